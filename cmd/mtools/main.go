@@ -28,6 +28,15 @@ func main() {
 					Aliases: []string{"p"},
 					EnvVars: []string{"PROJECT_PATH"},
 				},
+				&cli2.BoolFlag{
+					Name:    "offline",
+					Usage:   "Use only the cached registry manifests and module files without network requests",
+					EnvVars: []string{"MTOOLS_OFFLINE"},
+				},
+				&cli2.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Show the diff of the project files the command would change without changing them",
+				},
 			},
 		},
 	)
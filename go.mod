@@ -8,7 +8,6 @@ require (
 	github.com/gkampitakis/go-snaps v0.5.19
 	github.com/go-modulus/modulus v0.5.0-rc.2
 	github.com/iancoleman/strcase v0.3.0
-	github.com/laher/mergefs v0.1.1
 	github.com/manifoldco/promptui v0.9.0
 	github.com/sethvargo/go-envconfig v1.3.0
 	github.com/stretchr/testify v1.11.1
@@ -0,0 +1,93 @@
+package manifesto
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-modulus/modulus/module"
+)
+
+var ErrDependencyCycle = fmt.Errorf("modules have a cyclic dependency")
+var ErrDependencyNotFound = fmt.Errorf("dependency is not found in the manifest")
+
+// ResolveDependencies returns the modules to install together with all their transitive dependencies
+// that are not installed yet. The result is sorted in the install order: each module goes after its dependencies.
+// Dependencies are looked up by the module names in the available manifest, the version constraints are ignored.
+func ResolveDependencies(
+	available *LocalManifesto,
+	installed []module.Manifesto,
+	modulesToInstall []module.Manifesto,
+) ([]module.Manifesto, error) {
+	availableMap := make(map[string]module.Manifesto, len(available.Modules))
+	for _, md := range available.Modules {
+		availableMap[md.Name] = md
+	}
+	installedNames := make(map[string]struct{}, len(installed))
+	installedPackages := make(map[string]struct{}, len(installed))
+	for _, md := range installed {
+		installedNames[md.Name] = struct{}{}
+		installedPackages[md.Package] = struct{}{}
+	}
+
+	r := resolver{
+		available:         availableMap,
+		installedNames:    installedNames,
+		installedPackages: installedPackages,
+		visited:           make(map[string]struct{}),
+		visiting:          make(map[string]int),
+		res:               make([]module.Manifesto, 0, len(modulesToInstall)),
+	}
+	for _, md := range modulesToInstall {
+		err := r.visit(md, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r.res, nil
+}
+
+type resolver struct {
+	available         map[string]module.Manifesto
+	installedNames    map[string]struct{}
+	installedPackages map[string]struct{}
+	// visited contains the packages of modules that are already added to the result
+	visited map[string]struct{}
+	// visiting contains the names of modules on the current path with their positions in the path
+	visiting map[string]int
+	res      []module.Manifesto
+}
+
+func (r *resolver) visit(md module.Manifesto, path []string) error {
+	if _, ok := r.visited[md.Package]; ok {
+		return nil
+	}
+	if pos, ok := r.visiting[md.Name]; ok {
+		cycle := append(append([]string{}, path[pos:]...), md.Name)
+		return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+	}
+
+	r.visiting[md.Name] = len(path)
+	path = append(path, md.Name)
+	for _, dependency := range md.Install.Dependencies {
+		depName, _ := SplitDependency(dependency)
+		dep, ok := r.available[depName]
+		if !ok {
+			if _, installed := r.installedNames[depName]; installed {
+				continue
+			}
+			return fmt.Errorf("%w: %s required by %s", ErrDependencyNotFound, depName, md.Name)
+		}
+		if _, installed := r.installedPackages[dep.Package]; installed {
+			continue
+		}
+		err := r.visit(dep, path)
+		if err != nil {
+			return err
+		}
+	}
+	delete(r.visiting, md.Name)
+
+	r.visited[md.Package] = struct{}{}
+	r.res = append(r.res, md)
+	return nil
+}
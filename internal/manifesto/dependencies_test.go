@@ -0,0 +1,129 @@
+package manifesto_test
+
+import (
+	"testing"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/stretchr/testify/require"
+)
+
+func newManifesto(name string, deps ...string) module.Manifesto {
+	return module.Manifesto{
+		Name:    name,
+		Package: "github.com/go-modulus/modulus/" + name,
+		Install: module.InstallationManifesto{
+			Dependencies: deps,
+		},
+	}
+}
+
+func names(modules []module.Manifesto) []string {
+	res := make([]string, 0, len(modules))
+	for _, md := range modules {
+		res = append(res, md.Name)
+	}
+	return res
+}
+
+func TestResolveDependencies(t *testing.T) {
+	t.Run(
+		"resolve transitive dependencies in the install order", func(t *testing.T) {
+			available := &manifesto.LocalManifesto{
+				Modules: []module.Manifesto{
+					newManifesto("migrator", "pgx", "cli"),
+					newManifesto("pgx", "logger"),
+					newManifesto("logger"),
+					newManifesto("cli", "logger"),
+				},
+			}
+
+			res, err := manifesto.ResolveDependencies(
+				available,
+				nil,
+				[]module.Manifesto{newManifesto("migrator", "pgx", "cli")},
+			)
+
+			t.Log("Given a module with the transitive dependencies")
+			t.Log("When resolve the dependencies")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	All dependencies should be installed once before the modules requiring them")
+			require.Equal(t, []string{"logger", "pgx", "cli", "migrator"}, names(res))
+		},
+	)
+
+	t.Run(
+		"skip installed dependencies", func(t *testing.T) {
+			available := &manifesto.LocalManifesto{
+				Modules: []module.Manifesto{
+					newManifesto("migrator", "pgx", "cli"),
+					newManifesto("pgx", "logger"),
+					newManifesto("logger"),
+					newManifesto("cli"),
+				},
+			}
+
+			res, err := manifesto.ResolveDependencies(
+				available,
+				[]module.Manifesto{newManifesto("cli"), newManifesto("logger")},
+				[]module.Manifesto{newManifesto("migrator", "pgx", "cli")},
+			)
+
+			t.Log("Given a module with the dependencies some of which are installed")
+			t.Log("When resolve the dependencies")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The installed dependencies should not be added")
+			require.Equal(t, []string{"pgx", "migrator"}, names(res))
+		},
+	)
+
+	t.Run(
+		"return a cycle path", func(t *testing.T) {
+			available := &manifesto.LocalManifesto{
+				Modules: []module.Manifesto{
+					newManifesto("a", "b"),
+					newManifesto("b", "c"),
+					newManifesto("c", "a"),
+				},
+			}
+
+			_, err := manifesto.ResolveDependencies(
+				available,
+				nil,
+				[]module.Manifesto{newManifesto("a", "b")},
+			)
+
+			t.Log("Given modules with a cyclic dependency")
+			t.Log("When resolve the dependencies")
+			t.Log("	The cycle error should be returned")
+			require.ErrorIs(t, err, manifesto.ErrDependencyCycle)
+			t.Log("	The error should contain the cycle path")
+			require.Contains(t, err.Error(), "a -> b -> c -> a")
+		},
+	)
+
+	t.Run(
+		"return an error for the missing dependency", func(t *testing.T) {
+			available := &manifesto.LocalManifesto{
+				Modules: []module.Manifesto{
+					newManifesto("pgx", "slog logger"),
+				},
+			}
+
+			_, err := manifesto.ResolveDependencies(
+				available,
+				nil,
+				[]module.Manifesto{newManifesto("pgx", "slog logger")},
+			)
+
+			t.Log("Given a module with a dependency absent in the manifest")
+			t.Log("When resolve the dependencies")
+			t.Log("	The not found error should be returned")
+			require.ErrorIs(t, err, manifesto.ErrDependencyNotFound)
+			t.Log("	The error should contain the names of the dependency and the module")
+			require.Contains(t, err.Error(), "slog logger required by pgx")
+		},
+	)
+}
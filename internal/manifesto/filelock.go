@@ -0,0 +1,92 @@
+package manifesto
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+)
+
+// ManifestLockFile is the file inside the project locked by the commands changing the modules.json file
+const ManifestLockFile = ".mtools/modules.json.lock"
+
+var ErrManifestLocked = fmt.Errorf("modules.json is locked by another mtools process")
+
+// LockTimeoutEnv is an env variable overriding the time to wait for another process to release the lock, e.g. 30m
+const LockTimeoutEnv = "MTOOLS_LOCK_TIMEOUT"
+
+// defaultLockTimeout is the time to wait for another process to release the lock.
+// The install and upgrade commands hold the lock while running go get and go mod tidy for up to 5 minutes each.
+const defaultLockTimeout = 15 * time.Minute
+
+const lockRetryInterval = 100 * time.Millisecond
+
+// ManifestLock is an advisory lock of the modules.json file of the project.
+// It is held from loading the manifest until saving it, so parallel mtools processes change the file one by one.
+type ManifestLock struct {
+	file *os.File
+}
+
+// LockLocalManifesto takes the lock of the modules.json file of the project.
+// Waits for another process to release the lock and returns ErrManifestLocked on timeout.
+// The timeout is taken from the MTOOLS_LOCK_TIMEOUT env variable if it is set.
+// No files are created in the dry run mode, and the returned lock is a no-op.
+func LockLocalManifesto(projPath string) (*ManifestLock, error) {
+	if fsys.IsDryRun() {
+		return &ManifestLock{}, nil
+	}
+	filename := filepath.Join(projPath, ManifestLockFile)
+	err := os.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := lockTimeout()
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		file, err := tryLock(filename)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			return &ManifestLock{file: file}, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf(
+				"%w: waited for %s. Remove the %s file if no other mtools process is running",
+				ErrManifestLocked,
+				timeout,
+				filename,
+			)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// lockTimeout returns the time to wait for the lock
+func lockTimeout() (time.Duration, error) {
+	value := os.Getenv(LockTimeoutEnv)
+	if value == "" {
+		return defaultLockTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("wrong %s value %q: %w", LockTimeoutEnv, value, err)
+	}
+	return timeout, nil
+}
+
+// Unlock releases the lock. It is safe to call it several times.
+func (l *ManifestLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	file := l.file
+	l.file = nil
+	return unlock(file)
+}
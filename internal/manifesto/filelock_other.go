@@ -0,0 +1,38 @@
+//go:build !unix
+
+package manifesto
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// staleLockAge is the age of the lock file left by a dead process to take the lock over it.
+// It is longer than the commands holding the lock run, so the lock of a running process is not taken over.
+const staleLockAge = time.Hour
+
+// tryLock creates the lock file exclusively. Returns nil if the file is created by another process.
+func tryLock(filename string) (*os.File, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0644)
+	if err == nil {
+		return file, nil
+	}
+	if !errors.Is(err, os.ErrExist) {
+		return nil, err
+	}
+	info, statErr := os.Stat(filename)
+	if statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+		_ = os.Remove(filename)
+	}
+	return nil, nil
+}
+
+func unlock(file *os.File) error {
+	closeErr := file.Close()
+	err := os.Remove(file.Name())
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,63 @@
+//go:build !unix
+
+package manifesto_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockLocalManifesto_LockFile(t *testing.T) {
+	createLockFile := func(t *testing.T, projDir string, age time.Duration) string {
+		filename := filepath.Join(projDir, manifesto.ManifestLockFile)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, os.WriteFile(filename, nil, 0644))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(filename, modTime, modTime))
+		return filename
+	}
+
+	t.Run(
+		"wait for the lock file of another process", func(t *testing.T) {
+			t.Setenv(manifesto.LockTimeoutEnv, "200ms")
+			projDir := t.TempDir()
+			filename := createLockFile(t, projDir, time.Minute)
+
+			_, err := manifesto.LockLocalManifesto(projDir)
+			_, errStat := os.Stat(filename)
+
+			t.Log("Given the lock file created by another running process")
+			t.Log("When take the lock")
+			t.Log("	The error should be ErrManifestLocked")
+			require.ErrorIs(t, err, manifesto.ErrManifestLocked)
+			t.Log("	The lock file of another process should be kept")
+			require.NoError(t, errStat)
+		},
+	)
+
+	t.Run(
+		"take over the stale lock file", func(t *testing.T) {
+			t.Setenv(manifesto.LockTimeoutEnv, "5s")
+			projDir := t.TempDir()
+			filename := createLockFile(t, projDir, 2*time.Hour)
+
+			lock, err := manifesto.LockLocalManifesto(projDir)
+			require.NoError(t, err)
+			errUnlock := lock.Unlock()
+			_, errStat := os.Stat(filename)
+
+			t.Log("Given the lock file left by a dead process long ago")
+			t.Log("When take the lock")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The lock file should be removed on unlock")
+			require.NoError(t, errUnlock)
+			require.True(t, os.IsNotExist(errStat))
+		},
+	)
+}
@@ -0,0 +1,109 @@
+package manifesto
+
+import (
+	"encoding/json"
+
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+)
+
+// LockFile is a name of the file placed next to modules.json that keeps the exact versions of installed modules
+const LockFile = "modules.lock.json"
+
+type LockedModule struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	// GoModule is the path of the Go module containing the package.
+	// All packages of the same Go module are locked at the same version because go get changes the whole Go module.
+	GoModule string `json:"goModule,omitempty"`
+	Version  string `json:"version"`
+	// Constraint is a version constraint the version was resolved from
+	Constraint string `json:"constraint,omitempty"`
+	// Files are the files downloaded during the module installation
+	Files []LockedFile `json:"files,omitempty"`
+}
+
+// LockedFile keeps the sha256 checksum of the installed file content to find its local modifications
+type LockedFile struct {
+	Path   string `json:"path"`
+	Sha256 string `json:"sha256"`
+}
+
+type Lock struct {
+	Modules []LockedModule `json:"modules"`
+}
+
+func (l *Lock) Find(packageName string) (LockedModule, bool) {
+	for _, mod := range l.Modules {
+		if mod.Package == packageName {
+			return mod, true
+		}
+	}
+	return LockedModule{}, false
+}
+
+// FindGoModule returns the locked version of the Go module.
+// Returns false if no package of the Go module is locked.
+func (l *Lock) FindGoModule(goModule string) (string, bool) {
+	for _, mod := range l.Modules {
+		if goModule != "" && mod.GoModule == goModule {
+			return mod.Version, true
+		}
+	}
+	return "", false
+}
+
+// Update adds or replaces the locked package.
+// The other packages of the same Go module get its version too.
+func (l *Lock) Update(locked LockedModule) {
+	found := false
+	for i, mod := range l.Modules {
+		if mod.Package == locked.Package {
+			l.Modules[i] = locked
+			found = true
+			continue
+		}
+		if locked.GoModule != "" && mod.GoModule == locked.GoModule {
+			l.Modules[i].Version = locked.Version
+		}
+	}
+	if !found {
+		l.Modules = append(l.Modules, locked)
+	}
+}
+
+func (l *Lock) Remove(packageName string) bool {
+	for i, mod := range l.Modules {
+		if mod.Package == packageName {
+			l.Modules = append(l.Modules[:i], l.Modules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Lock) Save(projPath string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFileAtomic(projPath+"/"+LockFile, data, 0644)
+}
+
+// LoadLock reads the lock file of the project. Returns an empty lock if the file does not exist.
+func LoadLock(projPath string) (*Lock, error) {
+	res := &Lock{
+		Modules: make([]LockedModule, 0),
+	}
+	if !fileExists(projPath + "/" + LockFile) {
+		return res, nil
+	}
+	data, err := fsys.ReadFile(projPath + "/" + LockFile)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
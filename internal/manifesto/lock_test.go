@@ -0,0 +1,54 @@
+package manifesto_test
+
+import (
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock_Update(t *testing.T) {
+	t.Run(
+		"keep the packages of the same Go module at one version", func(t *testing.T) {
+			lock := &manifesto.Lock{
+				Modules: []manifesto.LockedModule{
+					{
+						Name:     "pgx",
+						Package:  "github.com/go-modulus/modulus/db/pgx",
+						GoModule: "github.com/go-modulus/modulus",
+						Version:  "v0.5.0",
+					},
+					{
+						Name:     "chi",
+						Package:  "github.com/go-modulus/chi",
+						GoModule: "github.com/go-modulus/chi",
+						Version:  "v1.0.0",
+					},
+				},
+			}
+
+			lock.Update(
+				manifesto.LockedModule{
+					Name:     "logger",
+					Package:  "github.com/go-modulus/modulus/logger",
+					GoModule: "github.com/go-modulus/modulus",
+					Version:  "v0.6.0",
+				},
+			)
+			pgx, _ := lock.Find("github.com/go-modulus/modulus/db/pgx")
+			chi, _ := lock.Find("github.com/go-modulus/chi")
+			version, ok := lock.FindGoModule("github.com/go-modulus/modulus")
+
+			t.Log("Given a lock with a package of the Go module")
+			t.Log("When update another package of the same Go module")
+			t.Log("	The package should be added")
+			require.Len(t, lock.Modules, 3)
+			t.Log("	The other package of the Go module should get the new version")
+			require.Equal(t, "v0.6.0", pgx.Version)
+			require.True(t, ok)
+			require.Equal(t, "v0.6.0", version)
+			t.Log("	The packages of other Go modules should be kept")
+			require.Equal(t, "v1.0.0", chi.Version)
+		},
+	)
+}
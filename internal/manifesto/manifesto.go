@@ -1,17 +1,23 @@
 package manifesto
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/go-modulus/modulus/errors"
 	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
 )
 
 var ErrCannotReadEntries = fmt.Errorf("cannot read entries")
+var ErrManifestChanged = fmt.Errorf("modules.json is changed by another process since it was loaded, run the command again")
 
 type Entrypoint struct {
 	LocalPath string `json:"localPath"`
@@ -19,16 +25,174 @@ type Entrypoint struct {
 }
 
 type LocalManifesto struct {
-	Modules []module.Manifesto `json:"modules"`
-	Entries []Entrypoint       `json:"entries,omitempty"`
+	// SchemaVersion is the version of the manifest format. It is set to the newest one on saving the local manifest.
+	SchemaVersion int                `json:"schemaVersion,omitempty"`
+	Modules       []module.Manifesto `json:"modules"`
+	Entries       []Entrypoint       `json:"entries,omitempty"`
+	// Constraints are semver constraints of the modules versions by the module names, e.g. {"pgx": "^0.5.0"}
+	Constraints map[string]string `json:"constraints,omitempty"`
+	// Registries are the sources of the available modules in the order of precedence.
+	// They are used instead of the public registry if the --manifest flag is not set.
+	Registries []Registry `json:"registries,omitempty"`
+	// ModuleEntries are the names of the entrypoints each module is wired into by the module packages,
+	// e.g. {"github.com/go-modulus/modulus/http": ["api"]}.
+	// A module without the record is wired into all entrypoints of the project.
+	ModuleEntries map[string][]string `json:"moduleEntries,omitempty"`
+	// FileChecksums are the sha256 checksums of the module files by the source URLs.
+	// They are read from the "sha256" fields of the install files of modules.
+	FileChecksums map[string]string `json:"-"`
+	// ModuleRegistries are the registries the modules are taken from by the module packages.
+	// The install files of a module are downloaded with the timeout and credentials of its registry.
+	ModuleRegistries map[string]Registry `json:"-"`
+	// metadata are the top-level fields unknown to mtools, e.g. the name and description of the project.
+	// They are kept in the order they are read to be written back unchanged.
+	metadata []metadataField
+	// loaded is the state of the modules.json file the manifest is loaded from
+	loaded *loadedFile
 }
 
+// loadedFile is the state of the file used to find its changes made by other processes
+type loadedFile struct {
+	existed bool
+	sum     [sha256.Size]byte
+}
+
+func newLoadedFile(data []byte) *loadedFile {
+	return &loadedFile{existed: true, sum: sha256.Sum256(data)}
+}
+
+type metadataField struct {
+	key   string
+	value json.RawMessage
+}
+
+// checksumsJson is a part of the manifest containing the checksums of the install files
+type checksumsJson struct {
+	Modules []struct {
+		Install struct {
+			Files []struct {
+				SourceUrl string `json:"sourceUrl"`
+				Sha256    string `json:"sha256"`
+			} `json:"files"`
+		} `json:"install"`
+	} `json:"modules"`
+}
+
+// ReadFromJSON reads the manifest ignoring the unknown fields of modules.
+// The unknown top-level fields are kept as metadata.
+// It is used for the registry manifests, the local modules.json file is read with ReadFromStrictJSON.
 func (m *LocalManifesto) ReadFromJSON(data []byte) error {
-	return json.Unmarshal(data, &m)
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return err
+	}
+	m.metadata, err = readMetadata(data)
+	if err != nil {
+		return err
+	}
+	checksums := checksumsJson{}
+	err = json.Unmarshal(data, &checksums)
+	if err != nil {
+		return err
+	}
+	m.FileChecksums = make(map[string]string)
+	for _, md := range checksums.Modules {
+		for _, file := range md.Install.Files {
+			if file.Sha256 != "" {
+				m.FileChecksums[file.SourceUrl] = strings.ToLower(file.Sha256)
+			}
+		}
+	}
+	return nil
+}
+
+// ReadFromStrictJSON checks the data against the JSON Schema with the name before reading it.
+// Returns the joined FieldError errors with the positions of all unknown fields and wrong values.
+func (m *LocalManifesto) ReadFromStrictJSON(data []byte, schemaName string) error {
+	err := validateStrict(data, schemaName)
+	if err != nil {
+		return err
+	}
+	return m.ReadFromJSON(data)
 }
 
+// WriteToJSON writes the manifest with its metadata placed before the known fields
+// The empty list of modules is written as an empty array.
 func (m *LocalManifesto) WriteToJSON() ([]byte, error) {
-	return json.MarshalIndent(m, "", "  ")
+	out := *m
+	if out.Modules == nil {
+		out.Modules = make([]module.Manifesto, 0)
+	}
+	if len(m.metadata) == 0 {
+		return json.MarshalIndent(out, "", "  ")
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for _, field := range m.metadata {
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(field.value)
+		buf.WriteByte(',')
+	}
+	if string(data) == "{}" {
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte('}')
+	} else {
+		buf.Write(data[1:])
+	}
+
+	res := bytes.Buffer{}
+	err = json.Indent(&res, buf.Bytes(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return res.Bytes(), nil
+}
+
+// readMetadata returns the top-level fields of the manifest that are not the fields of LocalManifesto
+func readMetadata(data []byte) ([]metadataField, error) {
+	known := make(map[string]struct{})
+	manifestType := reflect.TypeOf(LocalManifesto{})
+	for i := 0; i < manifestType.NumField(); i++ {
+		name, _, _ := strings.Cut(manifestType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			known[name] = struct{}{}
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("the manifest should be a JSON object")
+	}
+	res := make([]metadataField, 0)
+	for dec.More() {
+		token, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		err = dec.Decode(&value)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := known[key]; !ok {
+			res = append(res, metadataField{key: key, value: value})
+		}
+	}
+	return res, nil
 }
 
 func (m *LocalManifesto) AddModule(module module.Manifesto) {
@@ -45,6 +209,95 @@ func (m *LocalManifesto) UpdateModule(module module.Manifesto) {
 	m.AddModule(module)
 }
 
+func (m *LocalManifesto) RemoveModule(packageName string) bool {
+	for i, mod := range m.Modules {
+		if mod.Package == packageName {
+			m.Modules = append(m.Modules[:i], m.Modules[i+1:]...)
+			delete(m.ModuleEntries, packageName)
+			return true
+		}
+	}
+	return false
+}
+
+// EntriesOf returns the names of the entrypoints the module is wired into.
+// The module without the record is wired into all entrypoints with the names from allEntries.
+func (m *LocalManifesto) EntriesOf(modulePackage string, allEntries []string) []string {
+	if entries, ok := m.ModuleEntries[modulePackage]; ok {
+		return entries
+	}
+	return allEntries
+}
+
+// WireModule records that the module is wired into the entrypoints in addition to the already recorded ones
+func (m *LocalManifesto) WireModule(modulePackage string, entries []string) {
+	if m.ModuleEntries == nil {
+		m.ModuleEntries = make(map[string][]string)
+	}
+	res := slices.Clone(m.ModuleEntries[modulePackage])
+	if res == nil {
+		res = make([]string, 0, len(entries))
+	}
+	for _, entry := range entries {
+		if !slices.Contains(res, entry) {
+			res = append(res, entry)
+		}
+	}
+	m.ModuleEntries[modulePackage] = res
+}
+
+// AddEntry adds the entrypoint wired only with the modules having the packages.
+// The modules wired into all entrypoints get the records of the entrypoints from allEntries
+// to keep them out of the new entrypoint.
+func (m *LocalManifesto) AddEntry(entry Entrypoint, modulePackages []string, allEntries []string) {
+	for _, md := range m.Modules {
+		if _, ok := m.ModuleEntries[md.Package]; !ok {
+			m.WireModule(md.Package, allEntries)
+		}
+	}
+	for _, pckg := range modulePackages {
+		m.WireModule(pckg, []string{entry.Name})
+	}
+	if !slices.ContainsFunc(
+		m.Entries, func(e Entrypoint) bool {
+			return e.LocalPath == entry.LocalPath
+		},
+	) {
+		m.Entries = append(m.Entries, entry)
+	}
+}
+
+func (m *LocalManifesto) FindModule(moduleName string) (module.Manifesto, bool) {
+	for _, mod := range m.Modules {
+		if strings.EqualFold(mod.Name, moduleName) {
+			return mod, true
+		}
+	}
+	return module.Manifesto{}, false
+}
+
+// DependentModules returns the modules that have the given module name in their install dependencies
+func (m *LocalManifesto) DependentModules(moduleName string) []module.Manifesto {
+	res := make([]module.Manifesto, 0)
+	for _, mod := range m.Modules {
+		for _, dep := range mod.Install.Dependencies {
+			depName, _ := SplitDependency(dep)
+			if strings.EqualFold(depName, moduleName) {
+				res = append(res, mod)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// SplitDependency splits the dependency declared as "name@constraint" into the module name and the version constraint.
+// The constraint is empty if the dependency is declared only by the name.
+func SplitDependency(dependency string) (name string, constraint string) {
+	name, constraint, _ = strings.Cut(dependency, "@")
+	return strings.TrimSpace(name), strings.TrimSpace(constraint)
+}
+
 func (m *LocalManifesto) FindLocalModule(moduleName string) (module.Manifesto, bool) {
 	for _, mod := range m.Modules {
 		if mod.IsLocalModule && strings.EqualFold(mod.Name, moduleName) {
@@ -63,12 +316,49 @@ func (m *LocalManifesto) LocalModules() []module.Manifesto {
 	}
 	return res
 }
+
+// SaveAsLocalManifest atomically replaces the modules.json file of the project.
+// The manifest loaded with LoadLocalManifesto is not saved if the file is changed by another process since it was loaded.
 func (m *LocalManifesto) SaveAsLocalManifest(projPath string) error {
+	filename := projPath + "/modules.json"
+	err := m.checkUnchanged(filename)
+	if err != nil {
+		return err
+	}
+	m.SchemaVersion = SchemaVersion
 	data, err := m.WriteToJSON()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(projPath+"/modules.json", data, 0644)
+	err = fsys.WriteFileAtomic(filename, data, 0644)
+	if err != nil {
+		return err
+	}
+	if m.loaded != nil {
+		m.loaded = newLoadedFile(data)
+	}
+	return nil
+}
+
+// checkUnchanged returns ErrManifestChanged if the file differs from the loaded one
+func (m *LocalManifesto) checkUnchanged(filename string) error {
+	if m.loaded == nil {
+		return nil
+	}
+	data, err := fsys.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var current *loadedFile
+	if err == nil {
+		current = newLoadedFile(data)
+	} else {
+		current = &loadedFile{}
+	}
+	if *current != *m.loaded {
+		return ErrManifestChanged
+	}
+	return nil
 }
 
 func NewFromFs(manifestFs fs.FS, filename string) (*LocalManifesto, error) {
@@ -84,35 +374,72 @@ func NewFromFs(manifestFs fs.FS, filename string) (*LocalManifesto, error) {
 	return m, nil
 }
 
+// NewLocalFromFs reads the local modules.json file checking it against the LocalSchema.
+// The file of an older schema version is upgraded in memory, the file itself is kept unchanged.
+func NewLocalFromFs(manifestFs fs.FS, filename string) (*LocalManifesto, error) {
+	data, err := fs.ReadFile(manifestFs, filename)
+	if err != nil {
+		return nil, err
+	}
+	return newLocalFromData(data)
+}
+
+func newLocalFromData(data []byte) (*LocalManifesto, error) {
+	data, _, err := Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+	m := &LocalManifesto{}
+	err = m.ReadFromStrictJSON(data, LocalSchema)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadLocalManifesto reads the modules.json file of the project or returns an empty manifest if there is no file.
+// The commands changing the manifest should hold the LockLocalManifesto lock until the manifest is saved.
 func LoadLocalManifesto(projPath string) (*LocalManifesto, error) {
-	entries, err := readEntries(projPath)
+	entries, err := ReadEntries(projPath)
 	if err != nil {
 		return nil, errors.WithCause(ErrCannotReadEntries, err)
 	}
 	res := LocalManifesto{
-		Modules: make([]module.Manifesto, 0),
-		Entries: entries,
+		SchemaVersion: SchemaVersion,
+		Modules:       make([]module.Manifesto, 0),
+		Entries:       entries,
+		loaded:        &loadedFile{},
 	}
 	if fileExists(projPath + "/modules.json") {
-		projFs := os.DirFS(projPath)
-		manifest, err := NewFromFs(projFs, "modules.json")
+		data, err := fsys.ReadFile(projPath + "/modules.json")
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := newLocalFromData(data)
 		if err != nil {
 			return nil, err
 		}
+		manifest.loaded = newLoadedFile(data)
 		return manifest, nil
 	}
 	return &res, nil
 }
 
 func fileExists(filename string) bool {
-	info, err := os.Stat(filename)
-	if os.IsNotExist(err) {
-		return false
+	return fsys.IsFile(filename)
+}
+
+// EntryNames returns the names of the entrypoints
+func EntryNames(entries []Entrypoint) []string {
+	res := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		res = append(res, entry.Name)
 	}
-	return !info.IsDir()
+	return res
 }
 
-func readEntries(projPath string) (entries []Entrypoint, err error) {
+// ReadEntries finds the entrypoints of the project: the cmd/<name>/main.go files
+func ReadEntries(projPath string) (entries []Entrypoint, err error) {
 	folders, err := os.ReadDir(projPath + "/cmd")
 	if err != nil {
 		return
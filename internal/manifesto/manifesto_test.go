@@ -0,0 +1,292 @@
+package manifesto_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalManifesto_ReadFromJSON(t *testing.T) {
+	t.Run(
+		"read checksums of the install files", func(t *testing.T) {
+			m := &manifesto.LocalManifesto{}
+			err := m.ReadFromJSON(
+				[]byte(`{
+  "modules": [
+    {
+      "name": "gqlgen",
+      "package": "github.com/go-modulus/modulus/graphql",
+      "install": {
+        "files": [
+          {
+            "sourceUrl": "https://example.com/gqlgen.yaml",
+            "destFile": "gqlgen.yaml",
+            "sha256": "ABC123"
+          },
+          {
+            "sourceUrl": "https://example.com/tools.go",
+            "destFile": "tools.go"
+          }
+        ]
+      }
+    }
+  ]
+}`),
+			)
+
+			t.Log("When read a manifest with the checksums of the install files")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The module files should be read")
+			require.Len(t, m.Modules[0].Install.Files, 2)
+			t.Log("	The lowercase checksums should be available by the source URLs of the files")
+			require.Equal(t, map[string]string{"https://example.com/gqlgen.yaml": "abc123"}, m.FileChecksums)
+		},
+	)
+}
+
+func TestLocalManifesto_WireModule(t *testing.T) {
+	t.Run(
+		"wire a module into the chosen entrypoints", func(t *testing.T) {
+			m := &manifesto.LocalManifesto{}
+			m.WireModule("github.com/go-modulus/modulus/http", []string{"api"})
+			m.WireModule("github.com/go-modulus/modulus/http", []string{"worker", "api"})
+
+			t.Log("When wire a module into the entrypoints twice")
+			t.Log("	The module should be recorded in the entrypoints of both calls")
+			require.Equal(
+				t,
+				[]string{"api", "worker"},
+				m.EntriesOf("github.com/go-modulus/modulus/http", []string{"api", "console", "worker"}),
+			)
+			t.Log("	The module without the record should be wired into all entrypoints")
+			require.Equal(
+				t,
+				[]string{"api", "console", "worker"},
+				m.EntriesOf("github.com/go-modulus/modulus/db/pgx", []string{"api", "console", "worker"}),
+			)
+		},
+	)
+}
+
+func TestLocalManifesto_AddEntry(t *testing.T) {
+	t.Run(
+		"add an entrypoint with the chosen modules", func(t *testing.T) {
+			m := &manifesto.LocalManifesto{}
+			err := m.ReadFromJSON(
+				[]byte(`{
+  "entries": [{"name": "console", "localPath": "cmd/console/main.go"}],
+  "modules": [
+    {"name": "urfave cli", "package": "github.com/go-modulus/modulus/cli"},
+    {"name": "pgx", "package": "github.com/go-modulus/modulus/db/pgx"},
+    {"name": "chi", "package": "github.com/go-modulus/modulus/http"}
+  ],
+  "moduleEntries": {"github.com/go-modulus/modulus/http": []}
+}`),
+			)
+			require.NoError(t, err)
+
+			m.AddEntry(
+				manifesto.Entrypoint{Name: "api", LocalPath: "cmd/api/main.go"},
+				[]string{"github.com/go-modulus/modulus/http"},
+				[]string{"console"},
+			)
+
+			t.Log("Given the modules are wired into all entrypoints except the chi one")
+			t.Log("When add the api entrypoint with the chi module")
+			t.Log("	The entrypoint should be added")
+			require.Equal(
+				t, []manifesto.Entrypoint{
+					{Name: "console", LocalPath: "cmd/console/main.go"},
+					{Name: "api", LocalPath: "cmd/api/main.go"},
+				}, m.Entries,
+			)
+			t.Log("	The other modules should stay only in the previous entrypoints")
+			require.Equal(
+				t, map[string][]string{
+					"github.com/go-modulus/modulus/cli":    {"console"},
+					"github.com/go-modulus/modulus/db/pgx": {"console"},
+					"github.com/go-modulus/modulus/http":   {"api"},
+				}, m.ModuleEntries,
+			)
+			t.Log("	The record of the removed module should be removed")
+			m.RemoveModule("github.com/go-modulus/modulus/http")
+			require.NotContains(t, m.ModuleEntries, "github.com/go-modulus/modulus/http")
+		},
+	)
+}
+
+func TestLocalManifesto_SaveAsLocalManifest(t *testing.T) {
+	initProject := func(t *testing.T) string {
+		projDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(projDir+"/cmd/console", 0755))
+		require.NoError(t, os.WriteFile(projDir+"/cmd/console/main.go", []byte("package main\n"), 0644))
+		require.NoError(t, os.WriteFile(projDir+"/modules.json", []byte(`{"schemaVersion": 1, "modules": []}`), 0600))
+		return projDir
+	}
+
+	t.Run(
+		"save the manifest several times", func(t *testing.T) {
+			projDir := initProject(t)
+			m, err := manifesto.LoadLocalManifesto(projDir)
+			require.NoError(t, err)
+
+			m.AddModule(module.Manifesto{Name: "pgx", Package: "github.com/go-modulus/modulus/db/pgx"})
+			errFirst := m.SaveAsLocalManifest(projDir)
+			m.AddModule(module.Manifesto{Name: "logger", Package: "github.com/go-modulus/modulus/logger"})
+			errSecond := m.SaveAsLocalManifest(projDir)
+
+			saved, err := manifesto.LoadLocalManifesto(projDir)
+			require.NoError(t, err)
+			info, err := os.Stat(projDir + "/modules.json")
+			require.NoError(t, err)
+			tmpFiles, err := filepath.Glob(projDir + "/.modules.json.*")
+			require.NoError(t, err)
+
+			t.Log("Given a loaded manifest")
+			t.Log("When save the manifest after each change")
+			t.Log("	The errors should be nil")
+			require.NoError(t, errFirst)
+			require.NoError(t, errSecond)
+			t.Log("	The file should contain all modules")
+			require.Len(t, saved.Modules, 2)
+			t.Log("	The file mode should be kept and no temporary files should be left")
+			require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+			require.Empty(t, tmpFiles)
+		},
+	)
+
+	t.Run(
+		"reject the save of the manifest changed by another process", func(t *testing.T) {
+			projDir := initProject(t)
+			m, err := manifesto.LoadLocalManifesto(projDir)
+			require.NoError(t, err)
+			other, err := manifesto.LoadLocalManifesto(projDir)
+			require.NoError(t, err)
+
+			other.AddModule(module.Manifesto{Name: "logger", Package: "github.com/go-modulus/modulus/logger"})
+			require.NoError(t, other.SaveAsLocalManifest(projDir))
+			m.AddModule(module.Manifesto{Name: "pgx", Package: "github.com/go-modulus/modulus/db/pgx"})
+			err = m.SaveAsLocalManifest(projDir)
+
+			saved, errLoad := manifesto.LoadLocalManifesto(projDir)
+			require.NoError(t, errLoad)
+
+			t.Log("Given two manifests loaded from the same file")
+			t.Log("When save the second one after the first one is saved")
+			t.Log("	The error should be ErrManifestChanged")
+			require.ErrorIs(t, err, manifesto.ErrManifestChanged)
+			t.Log("	The changes of the first manifest should be kept")
+			require.Len(t, saved.Modules, 1)
+			require.Equal(t, "logger", saved.Modules[0].Name)
+		},
+	)
+}
+
+func TestLockLocalManifesto(t *testing.T) {
+	t.Run(
+		"wait for the lock released by another holder", func(t *testing.T) {
+			projDir := t.TempDir()
+			first, err := manifesto.LockLocalManifesto(projDir)
+			require.NoError(t, err)
+
+			acquired := make(chan error, 1)
+			go func() {
+				second, err := manifesto.LockLocalManifesto(projDir)
+				if err == nil {
+					err = second.Unlock()
+				}
+				acquired <- err
+			}()
+
+			t.Log("Given the locked manifest")
+			t.Log("When another holder takes the lock")
+			t.Log("	The holder should wait while the lock is held")
+			select {
+			case <-acquired:
+				t.Fatal("the lock is taken twice")
+			case <-time.After(300 * time.Millisecond):
+			}
+			t.Log("	The holder should take the lock after it is released")
+			require.NoError(t, first.Unlock())
+			select {
+			case err = <-acquired:
+				require.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the lock is not taken after it is released")
+			}
+		},
+	)
+
+	t.Run(
+		"fail after the lock timeout", func(t *testing.T) {
+			t.Setenv(manifesto.LockTimeoutEnv, "200ms")
+			projDir := t.TempDir()
+			first, err := manifesto.LockLocalManifesto(projDir)
+			require.NoError(t, err)
+			defer first.Unlock()
+
+			started := time.Now()
+			_, err = manifesto.LockLocalManifesto(projDir)
+
+			t.Log("Given the locked manifest and the lock timeout set by the env variable")
+			t.Log("When another holder takes the lock")
+			t.Log("	The error should be ErrManifestLocked")
+			require.ErrorIs(t, err, manifesto.ErrManifestLocked)
+			t.Log("	The holder should wait for the timeout")
+			require.GreaterOrEqual(t, time.Since(started), 200*time.Millisecond)
+		},
+	)
+
+	t.Run(
+		"reject the wrong lock timeout", func(t *testing.T) {
+			t.Setenv(manifesto.LockTimeoutEnv, "forever")
+
+			_, err := manifesto.LockLocalManifesto(t.TempDir())
+
+			t.Log("When take the lock with the wrong timeout in the env variable")
+			t.Log("	The error should be returned")
+			require.ErrorContains(t, err, manifesto.LockTimeoutEnv)
+		},
+	)
+}
+
+func TestLoadLocalManifesto(t *testing.T) {
+	t.Run(
+		"load the manifest written by the older version of mtools", func(t *testing.T) {
+			projDir := t.TempDir()
+			require.NoError(t, os.MkdirAll(projDir+"/cmd/console", 0755))
+			require.NoError(t, os.WriteFile(projDir+"/cmd/console/main.go", []byte("package main\n"), 0644))
+			content := `{
+  "name": "Modulus framework modules manifest",
+  "version": "1.0.0",
+  "description": "List of installed modules for the Modulus framework",
+  "modules": [
+    {
+      "name": "urfave cli",
+      "package": "github.com/go-modulus/modulus/cli",
+      "description": "Adds ability to create cli applications in the Modulus framework.",
+      "install": null,
+      "version": "1.0.0"
+    }
+  ]
+}`
+			require.NoError(t, os.WriteFile(projDir+"/modules.json", []byte(content), 0644))
+
+			m, err := manifesto.LoadLocalManifesto(projDir)
+
+			t.Log("Given a manifest without the schema version and with the null install instructions")
+			t.Log("When load the manifest")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The module should be loaded")
+			require.Len(t, m.Modules, 1)
+			require.Equal(t, "urfave cli", m.Modules[0].Name)
+		},
+	)
+}
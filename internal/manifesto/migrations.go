@@ -0,0 +1,208 @@
+package manifesto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the modules.json format written by this version of mtools.
+// Increase it with adding a migration from the previous version to the migrations list.
+const SchemaVersion = 1
+
+var ErrUnsupportedSchemaVersion = fmt.Errorf("schema version of the manifest is not supported")
+
+// migrations upgrade the manifest documents: migrations[i] converts the document of the version i to the version i+1.
+// The documents without the schemaVersion field have the version 0.
+var migrations = []func(doc *document) error{
+	migrateToV1,
+}
+
+// migrateToV1 removes the null values written by the versions of mtools before the schema was introduced.
+// The missing list of modules becomes empty, the null install instructions of the modules become empty too.
+func migrateToV1(doc *document) error {
+	for _, key := range []string{"entries", "constraints", "registries", "moduleEntries"} {
+		if value, ok := doc.get(key); ok && string(value) == "null" {
+			doc.delete(key)
+		}
+	}
+	value, ok := doc.get("modules")
+	if !ok || string(value) == "null" {
+		doc.set("modules", json.RawMessage("[]"))
+		return nil
+	}
+	var modules []json.RawMessage
+	err := json.Unmarshal(value, &modules)
+	if err != nil {
+		return fmt.Errorf("the modules field should be an array: %w", err)
+	}
+	for i, md := range modules {
+		modules[i], err = migrateModuleToV1(md)
+		if err != nil {
+			return fmt.Errorf("cannot migrate the module %d: %w", i, err)
+		}
+	}
+	value, err = json.Marshal(modules)
+	if err != nil {
+		return err
+	}
+	doc.set("modules", value)
+	return nil
+}
+
+// migrateModuleToV1 replaces the null install instructions of the module and their null lists with the empty values
+func migrateModuleToV1(data json.RawMessage) (json.RawMessage, error) {
+	md, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := md.get("install")
+	if !ok {
+		return data, nil
+	}
+	if string(value) == "null" {
+		md.set("install", json.RawMessage("{}"))
+		return md.marshal()
+	}
+	install, err := parseDocument(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"dependencies", "envVars", "files", "postInstallCommands"} {
+		if value, ok := install.get(key); ok && string(value) == "null" {
+			install.set(key, json.RawMessage("[]"))
+		}
+	}
+	value, err = install.marshal()
+	if err != nil {
+		return nil, err
+	}
+	md.set("install", value)
+	return md.marshal()
+}
+
+// Migrate upgrades the manifest data to the SchemaVersion.
+// Returns the upgraded data and the version of the original data.
+// The data of the newest version is returned unchanged.
+func Migrate(data []byte) ([]byte, int, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	version := 0
+	if value, ok := doc.get("schemaVersion"); ok {
+		err = json.Unmarshal(value, &version)
+		if err != nil {
+			return nil, 0, fmt.Errorf("the schemaVersion field should be an integer: %w", err)
+		}
+	}
+	if version > SchemaVersion || version < 0 {
+		return nil, version, fmt.Errorf(
+			"%w: the version is %d, the newest supported version is %d. Update mtools to read the manifest",
+			ErrUnsupportedSchemaVersion,
+			version,
+			SchemaVersion,
+		)
+	}
+	if version == SchemaVersion {
+		return data, version, nil
+	}
+
+	for i := version; i < SchemaVersion; i++ {
+		err = migrations[i](doc)
+		if err != nil {
+			return nil, version, fmt.Errorf("cannot migrate the manifest to the version %d: %w", i+1, err)
+		}
+	}
+	doc.set("schemaVersion", json.RawMessage(fmt.Sprint(SchemaVersion)))
+	res, err := doc.marshal()
+	if err != nil {
+		return nil, version, err
+	}
+	return res, version, nil
+}
+
+// document is a JSON object keeping the order of its fields
+type document struct {
+	fields []metadataField
+}
+
+func parseDocument(data []byte) (*document, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("the manifest should be a JSON object")
+	}
+	doc := &document{fields: make([]metadataField, 0)}
+	for dec.More() {
+		token, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		err = dec.Decode(&value)
+		if err != nil {
+			return nil, err
+		}
+		doc.fields = append(doc.fields, metadataField{key: key, value: value})
+	}
+	return doc, nil
+}
+
+func (d *document) get(key string) (json.RawMessage, bool) {
+	for _, field := range d.fields {
+		if field.key == key {
+			return field.value, true
+		}
+	}
+	return nil, false
+}
+
+// set changes the value of the field or adds the field to the end of the document
+func (d *document) set(key string, value json.RawMessage) {
+	for i, field := range d.fields {
+		if field.key == key {
+			d.fields[i].value = value
+			return
+		}
+	}
+	d.fields = append(d.fields, metadataField{key: key, value: value})
+}
+
+func (d *document) delete(key string) {
+	for i, field := range d.fields {
+		if field.key == key {
+			d.fields = append(d.fields[:i], d.fields[i+1:]...)
+			return
+		}
+	}
+}
+
+func (d *document) marshal() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, field := range d.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(field.value)
+	}
+	buf.WriteByte('}')
+
+	res := bytes.Buffer{}
+	err := json.Indent(&res, buf.Bytes(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return res.Bytes(), nil
+}
@@ -0,0 +1,164 @@
+package manifesto_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Run(
+		"upgrade an unversioned manifest", func(t *testing.T) {
+			data, version, err := manifesto.Migrate(
+				[]byte(`{
+  "$schema": "./modules.schema.json",
+  "name": "project",
+  "modules": null,
+  "entries": null,
+  "moduleEntries": null,
+  "x-owner": "team"
+}`),
+			)
+
+			t.Log("When migrate a manifest without the schema version")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The original version should be 0")
+			require.Equal(t, 0, version)
+			t.Log("	The null values should be removed, the metadata should keep its order")
+			require.Equal(
+				t, `{
+  "$schema": "./modules.schema.json",
+  "name": "project",
+  "modules": [],
+  "x-owner": "team",
+  "schemaVersion": 1
+}`, string(data),
+			)
+			t.Log("	The migrated manifest should match the schema")
+			errs, err := manifesto.Validate(data, manifesto.LocalSchema)
+			require.NoError(t, err)
+			require.Empty(t, errs)
+		},
+	)
+
+	t.Run(
+		"replace the null install instructions of the modules", func(t *testing.T) {
+			data, _, err := manifesto.Migrate(
+				[]byte(`{
+  "modules": [
+    {
+      "name": "urfave cli",
+      "package": "github.com/go-modulus/modulus/cli",
+      "install": null,
+      "version": "1.0.0"
+    },
+    {
+      "name": "pgx",
+      "package": "github.com/go-modulus/modulus/db/pgx",
+      "install": {
+        "dependencies": null,
+        "envVars": null,
+        "files": null,
+        "postInstallCommands": null
+      }
+    },
+    {
+      "name": "logger",
+      "package": "github.com/go-modulus/modulus/logger"
+    }
+  ]
+}`),
+			)
+
+			t.Log("When migrate a manifest with the null install instructions of the modules")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The null values should be replaced with the empty ones, the other fields should be kept")
+			require.Equal(
+				t, `{
+  "modules": [
+    {
+      "name": "urfave cli",
+      "package": "github.com/go-modulus/modulus/cli",
+      "install": {},
+      "version": "1.0.0"
+    },
+    {
+      "name": "pgx",
+      "package": "github.com/go-modulus/modulus/db/pgx",
+      "install": {
+        "dependencies": [],
+        "envVars": [],
+        "files": [],
+        "postInstallCommands": []
+      }
+    },
+    {
+      "name": "logger",
+      "package": "github.com/go-modulus/modulus/logger"
+    }
+  ],
+  "schemaVersion": 1
+}`, string(data),
+			)
+			t.Log("	The migrated manifest should match the schema")
+			errs, err := manifesto.Validate(data, manifesto.LocalSchema)
+			require.NoError(t, err)
+			require.Empty(t, errs)
+		},
+	)
+
+	t.Run(
+		"keep the manifest of the newest version", func(t *testing.T) {
+			src := []byte(`{"schemaVersion": 1, "modules": []}`)
+			data, version, err := manifesto.Migrate(src)
+
+			t.Log("When migrate a manifest of the newest version")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The version should be the newest one")
+			require.Equal(t, manifesto.SchemaVersion, version)
+			t.Log("	The data should be unchanged")
+			require.Equal(t, src, data)
+		},
+	)
+
+	t.Run(
+		"reject a manifest of the future version", func(t *testing.T) {
+			_, version, err := manifesto.Migrate([]byte(`{"schemaVersion": 100, "modules": []}`))
+
+			t.Log("When migrate a manifest written by a newer mtools")
+			t.Log("	The error should be ErrUnsupportedSchemaVersion")
+			require.True(t, errors.Is(err, manifesto.ErrUnsupportedSchemaVersion))
+			require.Equal(t, 100, version)
+		},
+	)
+}
+
+func TestNewLocalFromFs_Migrate(t *testing.T) {
+	t.Run(
+		"read an unversioned manifest", func(t *testing.T) {
+			manifestFs := fstest.MapFS{
+				"modules.json": &fstest.MapFile{Data: []byte(`{"name": "project", "modules": null}`)},
+			}
+			m, err := manifesto.NewLocalFromFs(manifestFs, "modules.json")
+
+			t.Log("When read a manifest of the older schema version")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The manifest should be upgraded in memory")
+			require.Equal(t, manifesto.SchemaVersion, m.SchemaVersion)
+			require.NotNil(t, m.Modules)
+			require.Empty(t, m.Modules)
+
+			data, err := m.WriteToJSON()
+			require.NoError(t, err)
+			t.Log("	The written manifest should have the newest version and keep the metadata")
+			require.JSONEq(t, `{"name": "project", "schemaVersion": 1, "modules": []}`, string(data))
+		},
+	)
+}
@@ -0,0 +1,252 @@
+package manifesto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/semver"
+)
+
+var ErrModuleNameCollision = fmt.Errorf("module name is declared in several registries with different packages")
+var ErrDuplicateModule = fmt.Errorf("module is declared several times in the registry")
+var ErrInvalidInstallFile = fmt.Errorf("install file of the module is invalid")
+var ErrInvalidEnvVar = fmt.Errorf("env variable of the module is invalid")
+var ErrInvalidPostInstallCommand = fmt.Errorf("post install command of the module is invalid")
+
+const (
+	AuthTypeBearer = "bearer"
+	AuthTypeBasic  = "basic"
+)
+
+// RegistryAuth describes the credentials of a private registry.
+// The credentials themselves are not stored in the manifest, only the names of the env variables containing them.
+type RegistryAuth struct {
+	// Type is either "bearer" or "basic"
+	Type        string `json:"type"`
+	TokenEnv    string `json:"tokenEnv,omitempty"`
+	UsernameEnv string `json:"usernameEnv,omitempty"`
+	PasswordEnv string `json:"passwordEnv,omitempty"`
+}
+
+// Registry is a source of the available modules: a URL or a local path to the modules.json manifest.
+type Registry struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+	// Timeout is a duration of the request to the registry, e.g. "10s". The default is 30s.
+	Timeout string        `json:"timeout,omitempty"`
+	Auth    *RegistryAuth `json:"auth,omitempty"`
+}
+
+// SetRegistry marks all modules of the manifest as taken from the registry
+func (m *LocalManifesto) SetRegistry(registry Registry) {
+	m.ModuleRegistries = make(map[string]Registry, len(m.Modules))
+	for _, md := range m.Modules {
+		m.ModuleRegistries[md.Package] = registry
+	}
+}
+
+type RegistryManifest struct {
+	Registry Registry
+	Manifest *LocalManifesto
+}
+
+// MergeRegistries combines the manifests of several registries into one.
+// The registries go in the order of precedence: if the same module package is declared in several registries,
+// the module and its version constraint are taken from the first one.
+// Returns ErrModuleNameCollision if the same module name points to different packages in different registries.
+func MergeRegistries(registries []RegistryManifest) (*LocalManifesto, error) {
+	res := &LocalManifesto{
+		Modules:          make([]module.Manifesto, 0),
+		Constraints:      make(map[string]string),
+		FileChecksums:    make(map[string]string),
+		ModuleRegistries: make(map[string]Registry),
+	}
+	// sources keeps the registry names of the added modules by the lowercase module names
+	sources := make(map[string]string)
+	for _, registry := range registries {
+		for _, md := range registry.Manifest.Modules {
+			existing, found := res.FindModule(md.Name)
+			if !found {
+				res.AddModule(md)
+				res.ModuleRegistries[md.Package] = registry.Registry
+				sources[strings.ToLower(md.Name)] = registry.Registry.Name
+				continue
+			}
+			if existing.Package != md.Package {
+				return nil, fmt.Errorf(
+					"%w: %s is %s in the registry %s and %s in the registry %s",
+					ErrModuleNameCollision,
+					md.Name,
+					existing.Package,
+					sources[strings.ToLower(md.Name)],
+					md.Package,
+					registry.Registry.Name,
+				)
+			}
+		}
+		for name, constraint := range registry.Manifest.Constraints {
+			if _, ok := res.Constraints[name]; !ok {
+				res.Constraints[name] = constraint
+			}
+		}
+		for url, checksum := range registry.Manifest.FileChecksums {
+			if _, ok := res.FileChecksums[url]; !ok {
+				res.FileChecksums[url] = checksum
+			}
+		}
+	}
+	return res, nil
+}
+
+// CheckRegistry finds the mistakes in the registry manifest that the schema cannot catch:
+// duplicated modules, unknown or cyclic dependencies, wrong constraints, install files and env variables.
+// Returns all found mistakes.
+func CheckRegistry(m *LocalManifesto) []error {
+	res := make([]error, 0)
+	names := make(map[string]string, len(m.Modules))
+	packages := make(map[string]string, len(m.Modules))
+	for _, md := range m.Modules {
+		if other, ok := names[strings.ToLower(md.Name)]; ok {
+			res = append(res, fmt.Errorf("%w: the name %s is used by %s and %s", ErrDuplicateModule, md.Name, other, md.Package))
+		}
+		names[strings.ToLower(md.Name)] = md.Package
+		if other, ok := packages[md.Package]; ok {
+			res = append(res, fmt.Errorf("%w: the package %s is used by %s and %s", ErrDuplicateModule, md.Package, other, md.Name))
+		}
+		packages[md.Package] = md.Name
+	}
+
+	depsFound := true
+	for _, md := range m.Modules {
+		for _, dependency := range md.Install.Dependencies {
+			name, constraint := SplitDependency(dependency)
+			if m.findModuleExact(name) == nil {
+				res = append(res, fmt.Errorf("%w: %s required by %s", ErrDependencyNotFound, name, md.Name))
+				depsFound = false
+			}
+			if constraint != "" {
+				if _, err := semver.ParseConstraint(constraint); err != nil {
+					res = append(res, fmt.Errorf("the dependency %s of %s: %w", dependency, md.Name, err))
+				}
+			}
+		}
+		res = append(res, checkInstall(md)...)
+	}
+	for name, constraint := range m.Constraints {
+		if _, err := semver.ParseConstraint(constraint); err != nil {
+			res = append(res, fmt.Errorf("the constraint of %s: %w", name, err))
+		}
+	}
+	if depsFound {
+		_, err := ResolveDependencies(m, nil, m.Modules)
+		if errors.Is(err, ErrDependencyCycle) {
+			res = append(res, err)
+		}
+	}
+	return res
+}
+
+// findModuleExact returns the module with the exact name as the dependencies are resolved by the exact names
+func (m *LocalManifesto) findModuleExact(name string) *module.Manifesto {
+	for i, md := range m.Modules {
+		if md.Name == name {
+			return &m.Modules[i]
+		}
+	}
+	return nil
+}
+
+func checkInstall(md module.Manifesto) []error {
+	res := make([]error, 0)
+	destFiles := make(map[string]struct{}, len(md.Install.Files))
+	for _, file := range md.Install.Files {
+		if !strings.HasPrefix(file.SourceUrl, "https://") && !strings.HasPrefix(file.SourceUrl, "http://") {
+			res = append(res, fmt.Errorf("%w: %s of %s should be an http(s) URL", ErrInvalidInstallFile, file.SourceUrl, md.Name))
+		}
+		dest := path.Clean(file.DestFile)
+		if file.DestFile == "" || path.IsAbs(dest) || dest == ".." || strings.HasPrefix(dest, "../") {
+			res = append(
+				res,
+				fmt.Errorf("%w: the destination %q of %s should be a path inside the project", ErrInvalidInstallFile, file.DestFile, md.Name),
+			)
+		}
+		if _, ok := destFiles[dest]; ok {
+			res = append(res, fmt.Errorf("%w: %s of %s is written several times", ErrInvalidInstallFile, file.DestFile, md.Name))
+		}
+		destFiles[dest] = struct{}{}
+	}
+
+	keys := make(map[string]struct{}, len(md.Install.EnvVars))
+	for _, envVar := range md.Install.EnvVars {
+		if envVar.Key == "" || strings.ContainsAny(envVar.Key, "= \t\n") {
+			res = append(res, fmt.Errorf("%w: the key %q of %s", ErrInvalidEnvVar, envVar.Key, md.Name))
+		}
+		if _, ok := keys[envVar.Key]; ok {
+			res = append(res, fmt.Errorf("%w: the key %s of %s is declared several times", ErrInvalidEnvVar, envVar.Key, md.Name))
+		}
+		keys[envVar.Key] = struct{}{}
+	}
+
+	for _, cmd := range md.Install.PostInstallCommands {
+		if strings.TrimSpace(cmd.CmdPackage) == "" {
+			res = append(res, fmt.Errorf("%w: the command package of %s is empty", ErrInvalidPostInstallCommand, md.Name))
+		}
+	}
+	return res
+}
+
+// AddRegistryModule appends the module to the modules of the registry manifest data.
+// The other modules and fields are kept as is, including the ones unknown to mtools like the sha256 checksums.
+// Returns ErrDuplicateModule if the registry already has a module with the same name or package.
+func AddRegistryModule(data []byte, md module.Manifesto) ([]byte, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	modules := make([]json.RawMessage, 0)
+	if value, ok := doc.get("modules"); ok && string(value) != "null" {
+		err = json.Unmarshal(value, &modules)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, raw := range modules {
+		existing := module.Manifesto{}
+		err = json.Unmarshal(raw, &existing)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(existing.Name, md.Name) || existing.Package == md.Package {
+			return nil, fmt.Errorf("%w: %s (%s)", ErrDuplicateModule, existing.Name, existing.Package)
+		}
+	}
+
+	raw, err := marshalUnescaped(md)
+	if err != nil {
+		return nil, err
+	}
+	modules = append(modules, raw)
+	value, err := marshalUnescaped(modules)
+	if err != nil {
+		return nil, err
+	}
+	doc.set("modules", value)
+	return doc.marshal()
+}
+
+// marshalUnescaped encodes the value to JSON keeping the characters like & in the URLs as is
+func marshalUnescaped(v any) ([]byte, error) {
+	buf := bytes.Buffer{}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,214 @@
+package manifesto_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeRegistries(t *testing.T) {
+	t.Run(
+		"merge registries in the order of precedence", func(t *testing.T) {
+			private := newManifesto("pgx")
+			private.Description = "private"
+			public := newManifesto("pgx")
+			public.Description = "public"
+
+			res, err := manifesto.MergeRegistries(
+				[]manifesto.RegistryManifest{
+					{
+						Registry: manifesto.Registry{Name: "private"},
+						Manifest: &manifesto.LocalManifesto{
+							Modules:     []module.Manifesto{private, newManifesto("billing")},
+							Constraints: map[string]string{"pgx": "^1.0.0"},
+						},
+					},
+					{
+						Registry: manifesto.Registry{Name: "public"},
+						Manifest: &manifesto.LocalManifesto{
+							Modules:     []module.Manifesto{public, newManifesto("logger")},
+							Constraints: map[string]string{"pgx": "^2.0.0", "logger": "~1.2"},
+						},
+					},
+				},
+			)
+
+			t.Log("Given two registries declaring the same module")
+			t.Log("When merge the registries")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	All modules should be merged without duplicates")
+			require.Equal(t, []string{"pgx", "billing", "logger"}, names(res.Modules))
+			t.Log("	The module and the constraint should be taken from the first registry")
+			require.Equal(t, "private", res.Modules[0].Description)
+			require.Equal(t, map[string]string{"pgx": "^1.0.0", "logger": "~1.2"}, res.Constraints)
+		},
+	)
+
+	t.Run(
+		"fail on the name collision", func(t *testing.T) {
+			fork := newManifesto("pgx")
+			fork.Package = "github.com/company/pgx"
+
+			_, err := manifesto.MergeRegistries(
+				[]manifesto.RegistryManifest{
+					{
+						Registry: manifesto.Registry{Name: "private"},
+						Manifest: &manifesto.LocalManifesto{Modules: []module.Manifesto{fork}},
+					},
+					{
+						Registry: manifesto.Registry{Name: "public"},
+						Manifest: &manifesto.LocalManifesto{Modules: []module.Manifesto{newManifesto("pgx")}},
+					},
+				},
+			)
+
+			t.Log("Given two registries declaring the same module name with different packages")
+			t.Log("When merge the registries")
+			t.Log("	The ErrModuleNameCollision error should be returned")
+			require.ErrorIs(t, err, manifesto.ErrModuleNameCollision)
+			require.ErrorContains(t, err, "github.com/company/pgx in the registry private")
+		},
+	)
+}
+
+func TestCheckRegistry(t *testing.T) {
+	t.Run(
+		"accept a valid registry", func(t *testing.T) {
+			pgx := newManifesto("pgx", "logger@^0.5.0")
+			pgx.Install.EnvVars = []module.ConfigEnvVariable{{Key: "PGX_DSN", Value: "postgres://localhost:5432/db"}}
+			pgx.Install.Files = []module.InstalledFile{
+				{SourceUrl: "https://example.com/pgx/config.go", DestFile: "internal/pgx/config.go"},
+			}
+
+			errs := manifesto.CheckRegistry(
+				&manifesto.LocalManifesto{Modules: []module.Manifesto{newManifesto("logger"), pgx}},
+			)
+
+			t.Log("When check a registry with the correct entries")
+			t.Log("	No mistakes should be found")
+			require.Empty(t, errs)
+		},
+	)
+
+	t.Run(
+		"find the mistakes of the entries", func(t *testing.T) {
+			pgx := newManifesto("pgx", "logger@^one", "cache")
+			pgx.Install.EnvVars = []module.ConfigEnvVariable{{Key: "PGX_DSN"}, {Key: "PGX_DSN"}, {Key: "PGX DSN"}}
+			pgx.Install.Files = []module.InstalledFile{
+				{SourceUrl: "config.go", DestFile: "../config.go"},
+			}
+			duplicate := newManifesto("PGX")
+			duplicate.Package = "github.com/company/pgx"
+
+			errs := manifesto.CheckRegistry(
+				&manifesto.LocalManifesto{Modules: []module.Manifesto{newManifesto("logger"), pgx, duplicate}},
+			)
+
+			t.Log("When check a registry with the wrong entries")
+			t.Log("	All mistakes should be found")
+			require.Len(t, errs, 7)
+			require.ErrorIs(t, errs[0], manifesto.ErrDuplicateModule)
+			require.ErrorIs(t, errs[1], semver.ErrInvalidConstraint)
+			require.ErrorIs(t, errs[2], manifesto.ErrDependencyNotFound)
+			require.ErrorIs(t, errs[3], manifesto.ErrInvalidInstallFile)
+			require.ErrorIs(t, errs[4], manifesto.ErrInvalidInstallFile)
+			require.ErrorIs(t, errs[5], manifesto.ErrInvalidEnvVar)
+			require.ErrorIs(t, errs[6], manifesto.ErrInvalidEnvVar)
+		},
+	)
+
+	t.Run(
+		"find a dependency cycle", func(t *testing.T) {
+			errs := manifesto.CheckRegistry(
+				&manifesto.LocalManifesto{
+					Modules: []module.Manifesto{newManifesto("a", "b"), newManifesto("b", "a")},
+				},
+			)
+
+			t.Log("When check a registry with the modules depending on each other")
+			t.Log("	The cycle should be found")
+			require.Len(t, errs, 1)
+			require.True(t, errors.Is(errs[0], manifesto.ErrDependencyCycle))
+		},
+	)
+}
+
+func TestAddRegistryModule(t *testing.T) {
+	registry := `{
+  "name": "company",
+  "modules": [
+    {
+      "name": "logger",
+      "package": "github.com/company/logger",
+      "install": {
+        "files": [
+          {
+            "sourceUrl": "https://example.com/logger/config.go",
+            "destFile": "internal/logger/config.go",
+            "sha256": "abc"
+          }
+        ]
+      }
+    }
+  ]
+}`
+
+	t.Run(
+		"append a module keeping the other entries", func(t *testing.T) {
+			data, err := manifesto.AddRegistryModule(
+				[]byte(registry),
+				module.Manifesto{Name: "pgx", Package: "github.com/company/pgx"},
+			)
+
+			t.Log("When add a module to the registry with the checksums of files")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The module should be appended and the checksums should be kept")
+			require.JSONEq(
+				t, `{
+  "name": "company",
+  "modules": [
+    {
+      "name": "logger",
+      "package": "github.com/company/logger",
+      "install": {
+        "files": [
+          {
+            "sourceUrl": "https://example.com/logger/config.go",
+            "destFile": "internal/logger/config.go",
+            "sha256": "abc"
+          }
+        ]
+      }
+    },
+    {
+      "name": "pgx",
+      "package": "github.com/company/pgx",
+      "description": "",
+      "install": {},
+      "version": ""
+    }
+  ]
+}`, string(data),
+			)
+		},
+	)
+
+	t.Run(
+		"reject a duplicated module", func(t *testing.T) {
+			_, err := manifesto.AddRegistryModule(
+				[]byte(registry),
+				module.Manifesto{Name: "Logger", Package: "github.com/company/another-logger"},
+			)
+
+			t.Log("When add a module with the name of an existing one")
+			t.Log("	The error should be ErrDuplicateModule")
+			require.ErrorIs(t, err, manifesto.ErrDuplicateModule)
+		},
+	)
+}
@@ -0,0 +1,416 @@
+package manifesto
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed schema/*.json
+var schemaFiles embed.FS
+
+const (
+	// LocalSchema is the JSON Schema of the modules.json file of a project
+	LocalSchema = "modules.schema.json"
+	// RegistrySchema is the JSON Schema of the modules.json file of a registry
+	RegistrySchema = "registry.schema.json"
+	// SchemaBaseUrl is the URL of the published schemas used in the $schema field of the manifests
+	SchemaBaseUrl = "https://raw.githubusercontent.com/go-modulus/mtools/main/internal/manifesto/schema/"
+)
+
+var ErrInvalidManifest = errors.New("manifest does not match the schema")
+
+// FieldError is a mismatch of the manifest and its schema at the position of the manifest file
+type FieldError struct {
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidManifest
+}
+
+// Schema returns the content of the embedded JSON Schema file
+func Schema(name string) ([]byte, error) {
+	return schemaFiles.ReadFile("schema/" + name)
+}
+
+// Validate checks the manifest data against the embedded JSON Schema with the name.
+// Returns all found mismatches ordered by their positions.
+func Validate(data []byte, schemaName string) ([]*FieldError, error) {
+	root, err := loadSchema(schemaName)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseJsonDoc(data)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := position(data, int(syntaxErr.Offset))
+			return []*FieldError{{Line: line, Column: col, Message: syntaxErr.Error()}}, nil
+		}
+		line, col := position(data, len(data))
+		return []*FieldError{{Line: line, Column: col, Message: err.Error()}}, nil
+	}
+
+	v := &validator{data: data, errs: make([]*FieldError, 0)}
+	err = v.validate(root, doc, "")
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(
+		v.errs, func(a, b *FieldError) int {
+			if a.Line != b.Line {
+				return a.Line - b.Line
+			}
+			return a.Column - b.Column
+		},
+	)
+	return v.errs, nil
+}
+
+// validateStrict returns the error joining all mismatches of the manifest and the schema
+func validateStrict(data []byte, schemaName string) error {
+	errs, err := Validate(data, schemaName)
+	if err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	res := make([]error, 0, len(errs))
+	for _, e := range errs {
+		res = append(res, e)
+	}
+	return errors.Join(res...)
+}
+
+// schemaNode is a subset of the JSON Schema keywords used by the manifest schemas
+type schemaNode struct {
+	file                 string
+	Ref                  string                 `json:"$ref"`
+	Type                 typeList               `json:"type"`
+	Enum                 []string               `json:"enum"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	PatternProperties    map[string]*schemaNode `json:"patternProperties"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+	Required             []string               `json:"required"`
+	Defs                 map[string]*schemaNode `json:"$defs"`
+}
+
+// typeList is the value of the type keyword: a type name or a list of them
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		*t = typeList{name}
+		return nil
+	}
+	var names []string
+	err := json.Unmarshal(data, &names)
+	if err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// allows checks if the value of the kind matches the types. Any value matches the empty list.
+func (t typeList) allows(kind string) bool {
+	return len(t) == 0 || slices.Contains(t, kind) || (kind == "number" && slices.Contains(t, "integer"))
+}
+
+func loadSchema(name string) (*schemaNode, error) {
+	data, err := Schema(name)
+	if err != nil {
+		return nil, err
+	}
+	node := &schemaNode{}
+	err = json.Unmarshal(data, node)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the schema %s: %w", name, err)
+	}
+	node.setFile(name)
+	return node, nil
+}
+
+// setFile remembers the schema file in all nodes to resolve the relative references
+func (n *schemaNode) setFile(name string) {
+	if n == nil {
+		return
+	}
+	n.file = name
+	for _, nodes := range []map[string]*schemaNode{n.Properties, n.PatternProperties, n.Defs} {
+		for _, child := range nodes {
+			child.setFile(name)
+		}
+	}
+	n.Items.setFile(name)
+}
+
+// resolve returns the node referenced by the $ref keyword, e.g. "#/$defs/module" or "modules.schema.json#/$defs/module"
+func (n *schemaNode) resolve() (*schemaNode, error) {
+	if n.Ref == "" {
+		return n, nil
+	}
+	file, pointer, _ := strings.Cut(n.Ref, "#")
+	if file == "" {
+		file = n.file
+	}
+	root, err := loadSchema(file)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := strings.CutPrefix(pointer, "/$defs/")
+	if !ok || root.Defs[name] == nil {
+		return nil, fmt.Errorf("cannot resolve the schema reference %s", n.Ref)
+	}
+	return root.Defs[name].resolve()
+}
+
+// additional returns the schema of the properties not listed in the properties keyword.
+// The allowed flag is false if the additional properties are forbidden.
+func (n *schemaNode) additional() (node *schemaNode, allowed bool, err error) {
+	raw := bytes.TrimSpace(n.AdditionalProperties)
+	switch string(raw) {
+	case "", "true":
+		return nil, true, nil
+	case "false":
+		return nil, false, nil
+	}
+	node = &schemaNode{}
+	err = json.Unmarshal(raw, node)
+	if err != nil {
+		return nil, false, err
+	}
+	node.setFile(n.file)
+	return node, true, nil
+}
+
+// jsonValue is a value of the parsed JSON document with its offset in the source
+type jsonValue struct {
+	offset int
+	// kind is one of the JSON Schema types: object, array, string, number, boolean, null
+	kind   string
+	str    string
+	keys   []jsonKey
+	values []*jsonValue
+}
+
+type jsonKey struct {
+	name   string
+	offset int
+	value  *jsonValue
+}
+
+func parseJsonDoc(data []byte) (*jsonValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	p := &jsonParser{data: data, dec: dec}
+	doc, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the end of the manifest")
+	}
+	return doc, nil
+}
+
+type jsonParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// start returns the offset of the next token skipping the whitespaces and delimiters
+func (p *jsonParser) start() int {
+	offset := int(p.dec.InputOffset())
+	for offset < len(p.data) && strings.IndexByte(" \t\r\n:,", p.data[offset]) != -1 {
+		offset++
+	}
+	return offset
+}
+
+func (p *jsonParser) value() (*jsonValue, error) {
+	res := &jsonValue{offset: p.start()}
+	token, err := p.dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			res.kind = "object"
+			for p.dec.More() {
+				keyOffset := p.start()
+				keyToken, err := p.dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := keyToken.(string)
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				res.keys = append(res.keys, jsonKey{name: key, offset: keyOffset, value: value})
+			}
+		} else {
+			res.kind = "array"
+			for p.dec.More() {
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				res.values = append(res.values, value)
+			}
+		}
+		// the closing delimiter
+		_, err = p.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+	case string:
+		res.kind = "string"
+		res.str = t
+	case json.Number:
+		res.kind = "number"
+		res.str = t.String()
+	case bool:
+		res.kind = "boolean"
+		res.str = strconv.FormatBool(t)
+	case nil:
+		res.kind = "null"
+	}
+	return res, nil
+}
+
+// position returns the line and column numbers of the offset starting from 1
+func position(data []byte, offset int) (line int, column int) {
+	offset = min(offset, len(data))
+	line = bytes.Count(data[:offset], []byte("\n")) + 1
+	column = offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, column
+}
+
+type validator struct {
+	data []byte
+	errs []*FieldError
+}
+
+func (v *validator) fail(offset int, path string, format string, args ...any) {
+	line, col := position(v.data, offset)
+	v.errs = append(v.errs, &FieldError{Line: line, Column: col, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(node *schemaNode, value *jsonValue, path string) error {
+	node, err := node.resolve()
+	if err != nil {
+		return err
+	}
+	if !node.Type.allows(value.kind) {
+		v.fail(value.offset, path, "expected %s, got %s", strings.Join(node.Type, " or "), value.kind)
+		return nil
+	}
+	if value.kind == "number" && len(node.Type) != 0 && !slices.Contains(node.Type, "number") && strings.ContainsAny(value.str, ".eE") {
+		v.fail(value.offset, path, "expected integer, got %s", value.str)
+		return nil
+	}
+	if len(node.Enum) != 0 && !slices.Contains(node.Enum, value.str) {
+		v.fail(value.offset, path, "the value %q is not one of: %s", value.str, strings.Join(node.Enum, ", "))
+	}
+
+	switch value.kind {
+	case "array":
+		if node.Items == nil {
+			return nil
+		}
+		for i, item := range value.values {
+			err = v.validate(node.Items, item, path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return err
+			}
+		}
+	case "object":
+		return v.validateObject(node, value, path)
+	}
+	return nil
+}
+
+func (v *validator) validateObject(node *schemaNode, value *jsonValue, path string) error {
+	additional, allowed, err := node.additional()
+	if err != nil {
+		return err
+	}
+	found := make(map[string]struct{}, len(value.keys))
+	for _, key := range value.keys {
+		found[key.name] = struct{}{}
+		keyPath := key.name
+		if path != "" {
+			keyPath = path + "." + key.name
+		}
+
+		child, ok := node.Properties[key.name]
+		if !ok {
+			for pattern, patternNode := range node.PatternProperties {
+				matched, err := regexp.MatchString(pattern, key.name)
+				if err != nil {
+					return err
+				}
+				if matched {
+					child, ok = patternNode, true
+					break
+				}
+			}
+		}
+		if !ok && !allowed {
+			v.fail(key.offset, path, "unknown field %q%s", key.name, v.suggest(key.name, node))
+			continue
+		}
+		if !ok {
+			child = additional
+		}
+		if child != nil {
+			err = v.validate(child, key.value, keyPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, name := range node.Required {
+		if _, ok := found[name]; !ok {
+			v.fail(value.offset, path, "missing required field %q", name)
+		}
+	}
+	return nil
+}
+
+// suggest returns a hint with the known field differing from the unknown one only in the letter case
+func (v *validator) suggest(name string, node *schemaNode) string {
+	for known := range node.Properties {
+		if strings.EqualFold(known, name) {
+			return fmt.Sprintf(", did you mean %q?", known)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package manifesto_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run(
+		"report unknown fields with positions", func(t *testing.T) {
+			errs, err := manifesto.Validate(
+				[]byte(`{
+  "modules": [
+    {
+      "name": "pgx",
+      "package": "github.com/go-modulus/modulus/db/pgx",
+      "localpath": "internal/pgx",
+      "install": {
+        "dependancies": ["logger"]
+      }
+    }
+  ]
+}`), manifesto.LocalSchema,
+			)
+
+			t.Log("When validate a manifest with the typos in the field names")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The unknown fields should be reported with their lines and columns")
+			require.Len(t, errs, 2)
+			require.Equal(
+				t, &manifesto.FieldError{
+					Line:    6,
+					Column:  7,
+					Path:    "modules[0]",
+					Message: `unknown field "localpath", did you mean "localPath"?`,
+				}, errs[0],
+			)
+			require.Equal(
+				t, &manifesto.FieldError{
+					Line:    8,
+					Column:  9,
+					Path:    "modules[0].install",
+					Message: `unknown field "dependancies"`,
+				}, errs[1],
+			)
+		},
+	)
+
+	t.Run(
+		"report wrong values and missing fields", func(t *testing.T) {
+			errs, err := manifesto.Validate(
+				[]byte(`{
+  "modules": [{"name": "pgx", "isLocalModule": "yes"}],
+  "registries": [{"name": "private", "url": "https://example.com", "auth": {"type": "oauth"}}]
+}`), manifesto.LocalSchema,
+			)
+
+			t.Log("When validate a manifest with the wrong values")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The wrong types, enum values and missing required fields should be reported")
+			require.Len(t, errs, 3)
+			require.Equal(t, `line 2, column 15: modules[0]: missing required field "package"`, errs[0].Error())
+			require.Equal(t, `line 2, column 48: modules[0].isLocalModule: expected boolean, got string`, errs[1].Error())
+			require.Equal(
+				t,
+				`line 3, column 85: registries[0].auth.type: the value "oauth" is not one of: bearer, basic`,
+				errs[2].Error(),
+			)
+		},
+	)
+
+	t.Run(
+		"report a syntax error", func(t *testing.T) {
+			errs, err := manifesto.Validate([]byte("{\n  \"modules\": [,]\n}"), manifesto.LocalSchema)
+
+			t.Log("When validate a manifest with a syntax error")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The syntax error should be reported with its position")
+			require.Len(t, errs, 1)
+			require.Equal(t, 2, errs[0].Line)
+		},
+	)
+
+	t.Run(
+		"validate a registry manifest", func(t *testing.T) {
+			errs, err := manifesto.Validate(
+				[]byte(`{
+  "name": "Private registry",
+  "modules": [{"name": "pgx", "package": "github.com/go-modulus/modulus/db/pgx"}],
+  "entries": []
+}`), manifesto.RegistrySchema,
+			)
+
+			t.Log("When validate a registry manifest with the entries of a project")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The project fields should be reported as unknown")
+			require.Len(t, errs, 1)
+			require.Equal(t, `line 4, column 3: unknown field "entries"`, errs[0].Error())
+		},
+	)
+
+	t.Run(
+		"validate the template of modules.json", func(t *testing.T) {
+			data, err := templates.TemplateFiles.ReadFile("init/modules.json")
+			require.NoError(t, err)
+
+			errs, err := manifesto.Validate(data, manifesto.LocalSchema)
+
+			t.Log("When validate the modules.json template created by mtools init")
+			t.Log("	The template should match the schema")
+			require.NoError(t, err)
+			require.Empty(t, errs)
+		},
+	)
+}
+
+func TestLocalManifesto_ReadFromStrictJSON(t *testing.T) {
+	t.Run(
+		"reject unknown fields", func(t *testing.T) {
+			m := &manifesto.LocalManifesto{}
+			err := m.ReadFromStrictJSON([]byte(`{"modules": [], "module": []}`), manifesto.LocalSchema)
+
+			t.Log("When read a manifest with an unknown field")
+			t.Log("	The ErrInvalidManifest error with the position should be returned")
+			require.ErrorIs(t, err, manifesto.ErrInvalidManifest)
+			var fieldErr *manifesto.FieldError
+			require.True(t, errors.As(err, &fieldErr))
+			require.Equal(t, 1, fieldErr.Line)
+			require.Equal(t, 17, fieldErr.Column)
+		},
+	)
+
+	t.Run(
+		"keep the metadata", func(t *testing.T) {
+			m := &manifesto.LocalManifesto{}
+			err := m.ReadFromStrictJSON(
+				[]byte(`{
+  "name": "Modulus framework modules manifest",
+  "version": "1.0.0",
+  "modules": [],
+  "x-owner": {"team": "platform"}
+}`), manifesto.LocalSchema,
+			)
+			require.NoError(t, err)
+			data, err := m.WriteToJSON()
+
+			t.Log("When write the manifest read with the top-level metadata")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The metadata should be written before the modules")
+			require.Equal(
+				t, `{
+  "name": "Modulus framework modules manifest",
+  "version": "1.0.0",
+  "x-owner": {
+    "team": "platform"
+  },
+  "modules": []
+}`, string(data),
+			)
+		},
+	)
+}
@@ -5,13 +5,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"os"
 	"os/exec"
 	"text/template"
 
 	"github.com/fatih/color"
 	"github.com/go-modulus/modulus/errors"
 	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
 	"github.com/go-modulus/mtools/internal/mtools/templates"
 	"github.com/go-modulus/mtools/internal/mtools/utils"
 )
@@ -85,12 +85,12 @@ func (c *InstallStorage) Install(ctx context.Context, md module.Manifesto, cfg S
 	}
 
 	// work with sqlc
-	err = exec.CommandContext(ctx, "go", "install", "github.com/sqlc-dev/sqlc/cmd/sqlc@latest").Run()
+	err = fsys.Run(exec.CommandContext(ctx, "go", "install", "github.com/sqlc-dev/sqlc/cmd/sqlc@latest"))
 	if err != nil {
 		return err
 	}
 	sqlcFile := storagePath + "/sqlc.yaml"
-	err = exec.CommandContext(ctx, "sqlc", "-f", sqlcFile, "generate").Run()
+	err = fsys.Run(exec.CommandContext(ctx, "sqlc", "-f", sqlcFile, "generate"))
 	if err != nil {
 		return err
 	}
@@ -121,7 +121,7 @@ func (c *InstallStorage) addFilesOfModule(
 		return err
 	}
 
-	err = os.WriteFile(storagePath+"/sqlc.tmpl.yaml", b.Bytes(), 0644)
+	err = fsys.WriteFile(storagePath+"/sqlc.tmpl.yaml", b.Bytes(), 0644)
 	if err != nil {
 		fmt.Println(color.RedString("Cannot write a storage tmpl file: %s", err.Error()))
 		return err
@@ -5,6 +5,7 @@ import (
 	"errors"
 	errors2 "github.com/go-modulus/modulus/errors"
 	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
 	"gopkg.in/yaml.v3"
 	"os"
 )
@@ -33,7 +34,7 @@ func NewUpdateSqlcConfig() *UpdateSqlcConfig {
 
 func (c *UpdateSqlcConfig) Update(ctx context.Context, storagePath string, projPath string) error {
 	defFile := projPath + "/sqlc.definition.yaml"
-	defContent, err := os.ReadFile(defFile)
+	defContent, err := fsys.ReadFile(defFile)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return ErrSqlcDefinitionFileNotFound
@@ -48,11 +49,11 @@ func (c *UpdateSqlcConfig) Update(ctx context.Context, storagePath string, projP
 		return errors2.WithCause(ErrCannotParseSqlcDefinition, err)
 	}
 
-	if _, err := os.Stat(storagePath + "/sqlc.tmpl.yaml"); os.IsNotExist(err) {
+	if !fsys.IsFile(storagePath + "/sqlc.tmpl.yaml") {
 		return ErrNoSqlcTmpl
 	}
 
-	tmplContent, err := os.ReadFile(storagePath + "/sqlc.tmpl.yaml")
+	tmplContent, err := fsys.ReadFile(storagePath + "/sqlc.tmpl.yaml")
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return ErrSqlcTemplateFileNotFound
@@ -84,7 +85,7 @@ func (c *UpdateSqlcConfig) Update(ctx context.Context, storagePath string, projP
 		return errors2.WithCause(ErrCannotUpdateSqlcConfig, err)
 	}
 
-	err = os.WriteFile(storagePath+"/sqlc.yaml", sqlcContent, 0644)
+	err = fsys.WriteFile(storagePath+"/sqlc.yaml", sqlcContent, 0644)
 	if err != nil {
 		return errors2.WithCause(ErrCannotUpdateSqlcConfig, err)
 	}
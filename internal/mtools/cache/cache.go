@@ -0,0 +1,222 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirEnv is an env variable overriding the cache directory
+const DirEnv = "MTOOLS_CACHE_DIR"
+
+var ErrNotCached = errors.New("the resource is not found in the cache")
+
+// Entry is a metadata of a cached resource
+type Entry struct {
+	Url          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+	Size         int64     `json:"size"`
+}
+
+// Cache keeps the downloaded registry manifests and module files in the user cache directory.
+// Every resource is stored as two files named by the hash of its URL: the content and the metadata.
+type Cache struct {
+	dir string
+}
+
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Default returns the cache placed in the $MTOOLS_CACHE_DIR directory
+// or in the mtools folder of the user cache directory ($XDG_CACHE_HOME or ~/.cache on Linux).
+func Default() (*Cache, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return New(dir), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return New(filepath.Join(dir, "mtools")), nil
+}
+
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Fetch returns the content of the request URL revalidating the cached copy with the ETag and Last-Modified headers.
+// In the offline mode only the cached copy is returned without any request, ErrNotCached is returned if there is no copy.
+// Failing to save the copy to the cache does not fail the fetch.
+func (c *Cache) Fetch(client *http.Client, req *http.Request, offline bool) ([]byte, error) {
+	key := c.key(req.URL.String())
+	entry, cached := c.readEntry(key)
+	if offline {
+		if !cached {
+			return nil, fmt.Errorf("%w: %s", ErrNotCached, req.URL.String())
+		}
+		return os.ReadFile(c.dataFile(key))
+	}
+
+	if cached {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		data, err := os.ReadFile(c.dataFile(key))
+		if err != nil {
+			return nil, err
+		}
+		entry.FetchedAt = time.Now()
+		_ = c.writeEntry(key, entry, nil)
+		return data, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.writeEntry(
+		key, Entry{
+			Url:          req.URL.String(),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+			Size:         int64(len(data)),
+		}, data,
+	)
+	return data, nil
+}
+
+// List returns the cached resources sorted by the URL
+func (c *Cache) List() ([]Entry, error) {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+	res := make([]Entry, 0, len(files)/2)
+	for _, file := range files {
+		key, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok {
+			continue
+		}
+		entry, ok := c.readEntry(key)
+		if !ok {
+			continue
+		}
+		res = append(res, entry)
+	}
+	sort.Slice(
+		res, func(i, j int) bool {
+			return res[i].Url < res[j].Url
+		},
+	)
+	return res, nil
+}
+
+// Clear removes all cached resources. Returns the number of the removed resources.
+// Only the files of the cached resources are removed because the cache dir may be shared with other files.
+func (c *Cache) Clear() (int, error) {
+	entries, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+	for i, entry := range entries {
+		key := c.key(entry.Url)
+		for _, file := range []string{c.dataFile(key), c.entryFile(key)} {
+			err = os.Remove(file)
+			if err != nil && !os.IsNotExist(err) {
+				return i, err
+			}
+		}
+	}
+	return len(entries), nil
+}
+
+func (c *Cache) key(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *Cache) dataFile(key string) string {
+	return filepath.Join(c.dir, key+".data")
+}
+
+func (c *Cache) entryFile(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *Cache) readEntry(key string) (Entry, bool) {
+	content, err := os.ReadFile(c.entryFile(key))
+	if err != nil {
+		return Entry{}, false
+	}
+	entry := Entry{}
+	err = json.Unmarshal(content, &entry)
+	if err != nil {
+		return Entry{}, false
+	}
+	if _, err = os.Stat(c.dataFile(key)); err != nil {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// writeEntry saves the metadata of the resource and its content if the data is not nil
+func (c *Cache) writeEntry(key string, entry Entry, data []byte) error {
+	err := os.MkdirAll(c.dir, 0755)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		err = os.WriteFile(c.dataFile(key), data, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	content, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.entryFile(key), content, 0644)
+}
+
+type offlineKey struct{}
+
+// WithOffline returns a context marking that the resources should be taken only from the cache
+func WithOffline(ctx context.Context, offline bool) context.Context {
+	return context.WithValue(ctx, offlineKey{}, offline)
+}
+
+func IsOffline(ctx context.Context) bool {
+	offline, _ := ctx.Value(offlineKey{}).(bool)
+	return offline
+}
@@ -0,0 +1,112 @@
+package cache_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/cache"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T, requests *int) *httptest.Server {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				*requests++
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("ETag", `"v1"`)
+				_, _ = w.Write([]byte("content"))
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func fetch(t *testing.T, c *cache.Cache, url string, offline bool) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	return c.Fetch(http.DefaultClient, req, offline)
+}
+
+func TestCache_Fetch(t *testing.T) {
+	t.Run(
+		"revalidate the cached resource", func(t *testing.T) {
+			requests := 0
+			server := newServer(t, &requests)
+			c := cache.New(t.TempDir())
+
+			first, errFirst := fetch(t, c, server.URL, false)
+			second, errSecond := fetch(t, c, server.URL, false)
+			entries, errList := c.List()
+
+			t.Log("When fetch the same resource twice")
+			require.NoError(t, errFirst)
+			require.NoError(t, errSecond)
+			require.NoError(t, errList)
+			t.Log("	The content should be returned both times")
+			require.Equal(t, "content", string(first))
+			require.Equal(t, "content", string(second))
+			t.Log("	The second request should be revalidated with the ETag")
+			require.Equal(t, 2, requests)
+			t.Log("	The resource should be listed in the cache")
+			require.Len(t, entries, 1)
+			require.Equal(t, server.URL, entries[0].Url)
+			require.Equal(t, `"v1"`, entries[0].ETag)
+		},
+	)
+
+	t.Run(
+		"use only the cache in the offline mode", func(t *testing.T) {
+			requests := 0
+			server := newServer(t, &requests)
+			c := cache.New(t.TempDir())
+
+			_, errNotCached := fetch(t, c, server.URL, true)
+			_, err := fetch(t, c, server.URL, false)
+			require.NoError(t, err)
+			offline, errOffline := fetch(t, c, server.URL, true)
+
+			t.Log("When fetch the resource in the offline mode")
+			t.Log("	The ErrNotCached error should be returned if the resource is not cached")
+			require.ErrorIs(t, errNotCached, cache.ErrNotCached)
+			t.Log("	The cached content should be returned without requests")
+			require.NoError(t, errOffline)
+			require.Equal(t, "content", string(offline))
+			require.Equal(t, 1, requests)
+		},
+	)
+
+	t.Run(
+		"clear the cache", func(t *testing.T) {
+			requests := 0
+			server := newServer(t, &requests)
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0644))
+			c := cache.New(dir)
+			_, err := fetch(t, c, server.URL, false)
+			require.NoError(t, err)
+
+			count, err := c.Clear()
+			require.NoError(t, err)
+			entries, errList := c.List()
+			other, errOther := os.ReadFile(filepath.Join(dir, "other.txt"))
+
+			t.Log("When clear the cache")
+			t.Log("	The number of removed resources should be returned")
+			require.Equal(t, 1, count)
+			t.Log("	The cache should be empty")
+			require.NoError(t, errList)
+			require.Empty(t, entries)
+			t.Log("	The other files of the cache dir should be kept")
+			require.NoError(t, errOther)
+			require.Equal(t, "other", string(other))
+		},
+	)
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/mtools/internal/mtools/cache"
+	"github.com/urfave/cli/v2"
+)
+
+type CacheList struct {
+	logger *slog.Logger
+	cache  *cache.Cache
+}
+
+func NewCacheList(
+	logger *slog.Logger,
+	cache *cache.Cache,
+) *CacheList {
+	return &CacheList{
+		logger: logger,
+		cache:  cache,
+	}
+}
+
+type CacheClear struct {
+	logger *slog.Logger
+	cache  *cache.Cache
+}
+
+func NewCacheClear(
+	logger *slog.Logger,
+	cache *cache.Cache,
+) *CacheClear {
+	return &CacheClear{
+		logger: logger,
+		cache:  cache,
+	}
+}
+
+func NewCacheCommand(
+	list *CacheList,
+	clear *CacheClear,
+) *cli.Command {
+	return &cli.Command{
+		Name: "cache",
+		Usage: `A set of commands for the cache of the registry manifests and module files.
+The cache is placed in the mtools folder of the user cache directory or in the $MTOOLS_CACHE_DIR directory.
+The cached resources are used by the commands running with the --offline flag.
+Example: mtools cache list
+`,
+		Subcommands: []*cli.Command{
+			{
+				Name: "list",
+				Usage: `Shows the cached registry manifests and module files.
+Example: mtools cache list
+`,
+				Action: list.Invoke,
+			},
+			{
+				Name: "clear",
+				Usage: `Removes all cached registry manifests and module files.
+Example: mtools cache clear
+`,
+				Action: clear.Invoke,
+			},
+		},
+	}
+}
+
+func (c *CacheList) Invoke(
+	ctx *cli.Context,
+) error {
+	entries, err := c.cache.List()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the cache directory %s: %s", c.cache.Dir(), err.Error()))
+		return err
+	}
+	fmt.Printf("Cache directory: %s\n", color.BlueString(c.cache.Dir()))
+	if len(entries) == 0 {
+		fmt.Println(color.YellowString("The cache is empty."))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "URL\tSIZE\tFETCHED AT\tETAG")
+	for _, entry := range entries {
+		etag := entry.ETag
+		if etag == "" {
+			etag = "-"
+		}
+		_, _ = fmt.Fprintf(
+			w,
+			"%s\t%d\t%s\t%s\n",
+			entry.Url,
+			entry.Size,
+			entry.FetchedAt.Local().Format("2006-01-02 15:04:05"),
+			etag,
+		)
+	}
+	_ = w.Flush()
+	return nil
+}
+
+func (c *CacheClear) Invoke(
+	ctx *cli.Context,
+) error {
+	count, err := c.cache.Clear()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot clear the cache directory %s: %s", c.cache.Dir(), err.Error()))
+		return err
+	}
+	fmt.Println(color.GreenString("The cache is cleared. %d resources are removed.", count))
+	return nil
+}
@@ -4,13 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	_ "github.com/amacneil/dbmate/v2/pkg/driver/postgres"
 	"github.com/fatih/color"
+	errors2 "github.com/go-modulus/modulus/errors"
 	"github.com/go-modulus/modulus/errors/errtrace"
 	"github.com/go-modulus/modulus/module"
 	"github.com/go-modulus/mtools/internal/manifesto"
 	"github.com/go-modulus/mtools/internal/mtools/action"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
 	"github.com/manifoldco/promptui"
 	"github.com/urfave/cli/v2"
 )
@@ -31,10 +36,17 @@ func NewAddCommand(updateSqlc *Add) *cli.Command {
 	return &cli.Command{
 		Name: "add",
 		Usage: `Adds a migration to the storage/migration folder of the selected module.
+The --template flag pre-fills the migration using the schema of the module and the names inferred from the migration name.
+Built-in templates: create-table, add-column, add-index, create-enum.
+The <template>.sql.tmpl files of the templates/migration folder of the project take precedence over the built-in ones.
 Example: mtools db add
 Example: mtools db add --proj-path=/path/to/project/root --module=example --name=create_table
+Example: mtools db add --module=example --name=create_users_table --template=create-table
+Example: mtools db add --module=example --name=add_email_to_users --template=add-column
 `,
 		Action: updateSqlc.Invoke,
+		Before: flag.DryRunBefore,
+		After:  flag.DryRunAfter,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:  "module",
@@ -44,6 +56,14 @@ Example: mtools db add --proj-path=/path/to/project/root --module=example --name
 				Name:  "name",
 				Usage: "A name of the migration",
 			},
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "A name of the template to pre-fill the migration with. The migration is empty by default",
+			},
+			&cli.StringFlag{
+				Name:  "templates-dir",
+				Usage: "A folder of the project migration templates related to the project root. Default is " + DefaultMigrationTemplatesDir,
+			},
 		},
 	}
 }
@@ -83,6 +103,14 @@ func (c *Add) Invoke(ctx *cli.Context) error {
 		storagePath := md.StoragePath(projPath)
 		migrationFs := os.DirFS(projPath)
 
+		if ctx.String("template") != "" {
+			err = c.addFromTemplate(ctx, md, storagePath, migrationName)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		config, err := newPgxConfig(projPath)
 		if err != nil {
 			fmt.Println(color.RedString("Cannot load the project config: %s", err.Error()))
@@ -106,6 +134,50 @@ func (c *Add) Invoke(ctx *cli.Context) error {
 	return nil
 }
 
+// addFromTemplate creates the migration file named the same way as dbmate does and fills it with the template
+func (c *Add) addFromTemplate(ctx *cli.Context, md module.Manifesto, storagePath string, migrationName string) error {
+	projPath := ctx.String("proj-path")
+	templatesDir := ctx.String("templates-dir")
+	if templatesDir == "" {
+		templatesDir = DefaultMigrationTemplatesDir
+	}
+	if !filepath.IsAbs(templatesDir) {
+		templatesDir = filepath.Join(projPath, templatesDir)
+	}
+
+	vars := NewMigrationTmplVars(md, migrationName, moduleSchema(storagePath))
+	content, err := RenderMigrationTemplate(templatesDir, ctx.String("template"), vars)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot render the migration template: %s", err.Error()))
+		fmt.Println(color.YellowString("Hint: %s", errors2.Hint(err)))
+		return err
+	}
+
+	migrationDir := storagePath + "/migration"
+	err = fsys.MkdirAll(migrationDir, 0755)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+	fileName := fmt.Sprintf("%s/%s_%s.sql", migrationDir, time.Now().UTC().Format("20060102150405"), migrationName)
+	if fsys.IsFile(fileName) {
+		fmt.Println(color.RedString("Migration %s already exists", fileName))
+		return errors.New("migration file already exists")
+	}
+	fmt.Printf("Creating migration: %s\n", fileName)
+	err = fsys.WriteFile(fileName, content, 0644)
+	if err != nil {
+		return errtrace.Wrap(err)
+	}
+
+	fmt.Println(
+		color.GreenString(
+			"Migration is created from the template %s. Check the names and fill the details.",
+			ctx.String("template"),
+		),
+	)
+	return nil
+}
+
 func (c *Add) askModuleName(modules []module.Manifesto) string {
 	items := make([]string, 0)
 	for _, md := range modules {
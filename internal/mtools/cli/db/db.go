@@ -2,18 +2,14 @@ package db
 
 import (
 	"context"
-	"fmt"
 	"io/fs"
 	"net/url"
 	"os"
 
 	"github.com/amacneil/dbmate/v2/pkg/dbmate"
-	"github.com/fatih/color"
 	"github.com/go-modulus/modulus/config"
 	"github.com/go-modulus/modulus/db/pgx"
-	"github.com/go-modulus/modulus/errors/errtrace"
-	"github.com/go-modulus/mtools/internal/manifesto"
-	"github.com/laher/mergefs"
+	"github.com/go-modulus/modulus/errors/errbuilder"
 	"github.com/sethvargo/go-envconfig"
 	"github.com/urfave/cli/v2"
 )
@@ -46,33 +42,8 @@ func newPgxConfig(projPath string) (pgx.ModuleConfig, error) {
 	return cfg, nil
 }
 
-func commonMigrationFs(projPath string, manifestFile string) (fs.FS, error) {
-	projFs := os.DirFS(projPath)
-	if manifestFile == "" {
-		manifestFile = "modules.json"
-	}
-	manifest, err := manifesto.NewFromFs(projFs, manifestFile)
-	if err != nil {
-		fmt.Println(color.RedString("Cannot load the project manifest %s/modules.json: %s", projPath, err.Error()))
-		return nil, errtrace.Wrap(err)
-	}
-
-	modulesFs := make([]fs.FS, 0)
-
-	for _, md := range manifest.Modules {
-		if md.LocalPath == "" {
-			continue
-		}
-
-		storagePath := md.StoragePath(projPath)
-		if _, err := os.Stat(storagePath); os.IsNotExist(err) {
-			continue
-		}
-		modulesFs = append(modulesFs, os.DirFS(storagePath))
-	}
-
-	return mergefs.Merge(modulesFs...), nil
-}
+var ErrDryRunIsNotSupported = errbuilder.New("the command does not support the dry run mode").
+	WithHint("Run the command without the --dry-run flag").Build()
 
 func NewDbCommand(
 	updateSqlc *UpdateSQLCConfig,
@@ -80,6 +51,9 @@ func NewDbCommand(
 	migrate *Migrate,
 	rollback *Rollback,
 	generate *Generate,
+	status *Status,
+	lintMigrations *LintMigrations,
+	lint *Lint,
 ) *cli.Command {
 	return &cli.Command{
 		Name: "db",
@@ -92,6 +66,9 @@ Example: mtools db
 			NewMigrateCommand(migrate),
 			NewRollbackCommand(rollback),
 			NewGenerateCommand(generate),
+			NewStatusCommand(status),
+			NewLintMigrationsCommand(lintMigrations),
+			NewLintCommand(lint),
 		},
 	}
 }
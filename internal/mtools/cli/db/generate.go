@@ -7,6 +7,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/go-modulus/mtools/internal/manifesto"
 	"github.com/go-modulus/mtools/internal/mtools/action"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
 	"github.com/go-modulus/mtools/internal/mtools/utils"
 	"github.com/urfave/cli/v2"
 )
@@ -25,6 +26,7 @@ func NewGenerateCommand(updateSqlc *Generate) *cli.Command {
 	return &cli.Command{
 		Name: "generate",
 		Usage: `Generates DTO and DAO files to work with DB. It uses SQLc compiler to do this action.
+The sqlc compiler writes the files itself, so the dry run mode is not supported.
 Example: mtools db generate
 `,
 		Action: updateSqlc.Invoke,
@@ -32,6 +34,10 @@ Example: mtools db generate
 }
 
 func (c *Generate) Invoke(ctx *cli.Context) error {
+	if flag.DryRunValue(ctx) {
+		fmt.Println(color.RedString("Cannot generate the files: %s", ErrDryRunIsNotSupported.Error()))
+		return ErrDryRunIsNotSupported
+	}
 	projPath := ctx.String("proj-path")
 	manifest, err := manifesto.LoadLocalManifesto(projPath)
 	fmt.Println(
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/mtools/internal/mtools/sqllint"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrDangerousMigrations = errbuilder.New("migrations contain dangerous operations").
+	WithHint("Fix the reported statements or put the -- mtools-lint-ignore <rule> comment before a statement if the operation is safe").Build()
+
+// defaultSchemaRegexp finds the schema of the module in the storage/sqlc.tmpl.yaml file created by mtools module create
+var defaultSchemaRegexp = regexp.MustCompile(`default_schema:\s*"?([^"\s]+)"?`)
+
+type Lint struct {
+}
+
+func NewLint() *Lint {
+	return &Lint{}
+}
+
+func NewLintCommand(lint *Lint) *cli.Command {
+	rules := make([]string, 0, len(sqllint.Rules))
+	for rule, description := range sqllint.Rules {
+		rules = append(rules, fmt.Sprintf("  %s: %s", rule, description))
+	}
+	sort.Strings(rules)
+
+	usage := `Checks the SQL of the migrations of all modules for the operations dangerous for a database with data.
+Put the -- mtools-lint-ignore comment before a statement to skip all rules for it, or list the rules: -- mtools-lint-ignore drop-column, drop-table.
+Put the -- mtools-lint-ignore-file <rules> comment anywhere in the file to skip the rules for the whole file.
+Rules:
+`
+	for _, rule := range rules {
+		usage += rule + "\n"
+	}
+	usage += `Example: mtools db lint
+Example for the new migrations of a module: mtools db lint --module=blog --since=20240101000000
+`
+	return &cli.Command{
+		Name:   "lint",
+		Usage:  usage,
+		Action: lint.Invoke,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "local-manifest",
+				Usage:   "Local manifest file related to the project root. Default is modules.json",
+				Aliases: []string{"lmf"},
+			},
+			newModuleFlag("The name of the module to lint. Can be repeated. All modules are linted by default"),
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Lint the migrations starting from the version only, the older migrations are already applied everywhere",
+			},
+		},
+	}
+}
+
+func (l *Lint) Invoke(ctx *cli.Context) error {
+	projPath := ctx.String("proj-path")
+	since := ctx.String("since")
+	if since != "" && !versionRegexp.MatchString(since) {
+		fmt.Println(color.RedString("Cannot parse the flags: %s", ErrInvalidMigrationVersion.Error()))
+		return ErrInvalidMigrationVersion
+	}
+	groups, modules, err := loadMigrations(projPath, ctx.String("local-manifest"), nil)
+	if err != nil {
+		return err
+	}
+	groups, err = chooseGroups(groups, modules, ctx.StringSlice("module"), false)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot choose the modules to lint: %s", err.Error()))
+		return err
+	}
+
+	checked := 0
+	found := 0
+	for _, group := range groups {
+		storagePath := storagePathOf(projPath, modules, group.Module)
+		schema := moduleSchema(storagePath)
+		for _, migration := range group.Migrations {
+			if migration.Version < since {
+				continue
+			}
+			fileName := storagePath + "/migration/" + migration.FileName
+			content, err := os.ReadFile(fileName)
+			if err != nil {
+				fmt.Println(color.RedString("Cannot read the migration %s: %s", fileName, err.Error()))
+				return err
+			}
+			checked++
+			for _, issue := range sqllint.Lint(string(content), schema) {
+				found++
+				fmt.Printf(
+					"%s:%d: %s %s\n",
+					fileName,
+					issue.Line,
+					color.YellowString("[%s]", issue.Rule),
+					color.RedString(issue.Message),
+				)
+			}
+		}
+	}
+
+	if found != 0 {
+		fmt.Println(color.RedString("%d issues are found in %d migrations.", found, checked))
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(ErrDangerousMigrations)))
+		return ErrDangerousMigrations
+	}
+	fmt.Println(color.GreenString("%d migrations are checked, no issues are found.", checked))
+	return nil
+}
+
+// moduleSchema returns the schema of the module from the sqlc template of its storage.
+// Returns an empty string if the schema is unknown, the schema check is skipped then.
+func moduleSchema(storagePath string) string {
+	data, err := os.ReadFile(storagePath + "/sqlc.tmpl.yaml")
+	if err != nil {
+		return ""
+	}
+	matches := defaultSchemaRegexp.FindSubmatch(data)
+	if matches == nil {
+		return ""
+	}
+	return string(matches[1])
+}
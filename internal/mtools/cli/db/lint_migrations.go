@@ -0,0 +1,140 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrDuplicateMigrationVersion = fmt.Errorf("migration version is used by several migration files")
+var ErrMigrationOrder = fmt.Errorf("migration is older than a migration of the module it depends on")
+
+var ErrMigrationsAreInvalid = errbuilder.New("migrations of the modules are invalid").
+	WithHint("Rename the reported migration files to give them unique versions, e.g. recreate them with mtools db add").Build()
+
+type LintMigrations struct {
+}
+
+func NewLintMigrations() *LintMigrations {
+	return &LintMigrations{}
+}
+
+func NewLintMigrationsCommand(lint *LintMigrations) *cli.Command {
+	return &cli.Command{
+		Name: "lint-migrations",
+		Usage: `Checks the migration files of all modules without connecting to the database.
+Reports the versions used by several migration files, only one of them is recorded as applied in the database.
+Warns when a migration of a module is older than a migration of the module it depends on.
+The same checks run before mtools db migrate.
+Example: mtools db lint-migrations
+`,
+		Action: lint.Invoke,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "local-manifest",
+				Usage:   "Local manifest file related to the project root. Default is modules.json",
+				Aliases: []string{"lmf"},
+			},
+		},
+	}
+}
+
+func (l *LintMigrations) Invoke(ctx *cli.Context) error {
+	projPath := ctx.String("proj-path")
+	groups, modules, err := loadMigrations(projPath, ctx.String("local-manifest"), nil)
+	if err != nil {
+		return err
+	}
+
+	err = lintMigrations(groups, modules)
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, group := range groups {
+		count += len(group.Migrations)
+	}
+	fmt.Println(color.GreenString("%d migrations of %d modules are checked.", count, len(groups)))
+	return nil
+}
+
+// lintMigrations prints the problems of the migrations.
+// Returns ErrMigrationsAreInvalid if there are errors, the warnings do not fail.
+func lintMigrations(groups []ModuleMigrations, modules []module.Manifesto) error {
+	errs, warnings := CheckMigrations(groups, modules)
+	for _, warning := range warnings {
+		fmt.Println(color.YellowString("Warning: %s", warning.Error()))
+	}
+	for _, e := range errs {
+		fmt.Println(color.RedString("Error: %s", e.Error()))
+	}
+	if len(errs) != 0 {
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(ErrMigrationsAreInvalid)))
+		return ErrMigrationsAreInvalid
+	}
+	return nil
+}
+
+// CheckMigrations returns the problems of the migration files of the modules.
+// The errors are the versions used by several files: the database keeps one record per version,
+// so only one of the files is applied.
+// The warnings are the migrations older than the migrations of the modules they depend on,
+// the tools applying the migrations by the versions run them before the tables they use are created.
+func CheckMigrations(groups []ModuleMigrations, modules []module.Manifesto) (errs []error, warnings []error) {
+	files := make(map[string][]string)
+	for _, group := range groups {
+		if group.Module == "" {
+			continue
+		}
+		for _, migration := range group.Migrations {
+			files[migration.Version] = append(files[migration.Version], group.Module+"/"+migration.FileName)
+		}
+	}
+	versions := make([]string, 0, len(files))
+	for version, versionFiles := range files {
+		if len(versionFiles) > 1 {
+			versions = append(versions, version)
+		}
+	}
+	sort.Strings(versions)
+	for _, version := range versions {
+		errs = append(
+			errs,
+			fmt.Errorf("%w: %s is used by %s", ErrDuplicateMigrationVersion, version, strings.Join(files[version], ", ")),
+		)
+	}
+
+	for _, group := range groups {
+		if group.Module == "" || len(group.Migrations) == 0 {
+			continue
+		}
+		first := group.Migrations[0]
+		deps := dependenciesOf(group.Module, modules)
+		for _, depGroup := range groups {
+			if _, ok := deps[depGroup.Module]; !ok || len(depGroup.Migrations) == 0 {
+				continue
+			}
+			last := depGroup.Migrations[len(depGroup.Migrations)-1]
+			if first.Version < last.Version {
+				warnings = append(
+					warnings,
+					fmt.Errorf(
+						"%w: %s/%s is older than %s/%s",
+						ErrMigrationOrder,
+						group.Module,
+						first.FileName,
+						depGroup.Module,
+						last.FileName,
+					),
+				)
+			}
+		}
+	}
+	return errs, warnings
+}
@@ -0,0 +1,80 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/cli/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMigrations(t *testing.T) {
+	t.Run(
+		"report the duplicate versions", func(t *testing.T) {
+			groups := []db.ModuleMigrations{
+				{
+					Module: "user",
+					Migrations: []db.MigrationStatus{
+						{Version: "1", FileName: "1_create_users.sql"},
+						{Version: "2", FileName: "2_init.sql"},
+					},
+				},
+				{
+					Module: "blog",
+					Migrations: []db.MigrationStatus{
+						{Version: "2", FileName: "2_init.sql"},
+						{Version: "3", FileName: "3_create_posts.sql"},
+					},
+				},
+			}
+
+			errs, warnings := db.CheckMigrations(groups, nil)
+
+			t.Log("Given two modules with the same migration file name")
+			t.Log("When check the migrations")
+			t.Log("	The duplicate version should be reported")
+			require.Len(t, errs, 1)
+			require.ErrorIs(t, errs[0], db.ErrDuplicateMigrationVersion)
+			require.Contains(t, errs[0].Error(), "user/2_init.sql, blog/2_init.sql")
+			t.Log("	The warnings should be empty for the independent modules")
+			require.Empty(t, warnings)
+		},
+	)
+
+	t.Run(
+		"warn about the migrations older than the migrations of the dependencies", func(t *testing.T) {
+			blog := module.Manifesto{Name: "blog", LocalPath: "internal/blog"}
+			blog.Install.Dependencies = []string{"user"}
+			modules := []module.Manifesto{
+				{Name: "user", LocalPath: "internal/user"},
+				blog,
+			}
+			groups := []db.ModuleMigrations{
+				{
+					Module: "user",
+					Migrations: []db.MigrationStatus{
+						{Version: "1", FileName: "1_create_users.sql"},
+						{Version: "3", FileName: "3_add_email.sql"},
+					},
+				},
+				{
+					Module: "blog",
+					Migrations: []db.MigrationStatus{
+						{Version: "2", FileName: "2_create_posts.sql"},
+					},
+				},
+			}
+
+			errs, warnings := db.CheckMigrations(groups, modules)
+
+			t.Log("Given the blog migration created before the last migration of the user module it depends on")
+			t.Log("When check the migrations")
+			t.Log("	The errors should be empty")
+			require.Empty(t, errs)
+			t.Log("	The order should be warned")
+			require.Len(t, warnings, 1)
+			require.ErrorIs(t, warnings[0], db.ErrMigrationOrder)
+			require.Contains(t, warnings[0].Error(), "blog/2_create_posts.sql is older than user/3_add_email.sql")
+		},
+	)
+}
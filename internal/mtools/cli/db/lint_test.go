@@ -0,0 +1,56 @@
+package db_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/cli/db"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestLint_Invoke(t *testing.T) {
+	t.Run(
+		"report the dangerous operations of the module migrations", func(t *testing.T) {
+			projDir := t.TempDir()
+			files := map[string]string{
+				"modules.json": `{
+  "modules": [
+    {"name": "blog", "package": "example.com/app/internal/blog", "localPath": "internal/blog", "isLocalModule": true}
+  ]
+}`,
+				"internal/blog/storage/sqlc.tmpl.yaml": `default_schema: "blog"`,
+				"internal/blog/storage/migration/20240101000000_create_posts.sql": `-- migrate:up
+CREATE TABLE blog.post (id uuid);
+-- migrate:down
+DROP TABLE blog.post;
+`,
+				"internal/blog/storage/migration/20240201000000_drop_comments.sql": `-- migrate:up
+DROP TABLE public.comment;
+-- migrate:down
+`,
+			}
+			for file, content := range files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(projDir+"/"+file), 0755))
+				require.NoError(t, os.WriteFile(projDir+"/"+file, []byte(content), 0644))
+			}
+			set := flag.NewFlagSet("test", 0)
+			set.String("proj-path", projDir, "")
+			set.String("since", "", "")
+
+			err := db.NewLint().Invoke(cli.NewContext(cli.NewApp(), set, nil))
+
+			require.NoError(t, set.Set("since", "20240301000000"))
+			errSince := db.NewLint().Invoke(cli.NewContext(cli.NewApp(), set, nil))
+
+			t.Log("Given a module migration dropping a table of another schema")
+			t.Log("When lint the migrations")
+			t.Log("	The error should be ErrDangerousMigrations")
+			require.ErrorIs(t, err, db.ErrDangerousMigrations)
+			t.Log("	The migrations older than the --since version should be skipped")
+			require.NoError(t, errSince)
+		},
+	)
+}
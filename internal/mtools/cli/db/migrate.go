@@ -3,8 +3,10 @@ package db
 import (
 	"fmt"
 	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/db/pgx"
 	"github.com/go-modulus/modulus/errors/errtrace"
 	"github.com/go-modulus/mtools/internal/mtools/action"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
 	"github.com/urfave/cli/v2"
 )
 
@@ -24,8 +26,14 @@ func NewMigrateCommand(updateSqlc *Migrate) *cli.Command {
 	return &cli.Command{
 		Name: "migrate",
 		Usage: `Migrates all migrations in all modules.
+The migrations of a module are applied after the migrations of the modules it depends on.
+Fails if the migration files are invalid, see mtools db lint-migrations.
 Example: mtools db migrate
 Example: mtools db migrate --proj-path=/path/to/project/root
+Example for one module and its dependencies: mtools db migrate --module=blog
+Example: mtools db migrate --to=20240101000000
+Example: mtools db migrate --steps=1
+Example for printing the migrations to apply without applying them: mtools --dry-run db migrate
 `,
 		Action: updateSqlc.Invoke,
 		Flags: []cli.Flag{
@@ -34,6 +42,15 @@ Example: mtools db migrate --proj-path=/path/to/project/root
 				Usage:   "Local manifest file related to the project root. Default is modules.json",
 				Aliases: []string{"lmf"},
 			},
+			newModuleFlag("The name of the module to migrate together with the modules it depends on. Can be repeated. All modules are migrated by default"),
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "Apply the migrations up to the version inclusive",
+			},
+			&cli.IntFlag{
+				Name:  "steps",
+				Usage: "Apply at most this number of the migrations",
+			},
 		},
 	}
 }
@@ -46,18 +63,55 @@ func (c *Migrate) Invoke(ctx *cli.Context) error {
 		return errtrace.Wrap(err)
 	}
 
-	manifest := ctx.String("local-manifest")
+	opts, err := planOptionsValue(ctx)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot parse the flags: %s", err.Error()))
+		return err
+	}
 
-	projFs, err := commonMigrationFs(projPath, manifest)
+	isDryRun := flag.DryRunValue(ctx)
+	if !isDryRun {
+		err = createDatabase(config)
+		if err != nil {
+			fmt.Println(color.RedString("Cannot create the database: %s", err.Error()))
+			return errtrace.Wrap(err)
+		}
+	}
+	applied, err := appliedMigrations(config)
 	if err != nil {
+		fmt.Println(color.RedString("Cannot read the applied migrations: %s", err.Error()))
 		return errtrace.Wrap(err)
 	}
-
-	dbMate := newDBMate(config, projFs, []string{"migration"})
-	err = dbMate.CreateAndMigrate()
+	groups, modules, err := loadMigrations(projPath, ctx.String("local-manifest"), applied)
 	if err != nil {
 		return errtrace.Wrap(err)
 	}
+	err = lintMigrations(groups, modules)
+	if err != nil {
+		return err
+	}
+	plan, err := PlanMigrate(groups, modules, opts)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot plan the migrations: %s", err.Error()))
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Println(color.GreenString("There are no pending migrations."))
+		return nil
+	}
+	if isDryRun {
+		printPlan("Dry run: the migrations to apply", plan)
+		return nil
+	}
+
+	for _, batch := range batches(plan) {
+		fmt.Printf("Migrating the module %s...\n", color.BlueString(batch[0].Module))
+		migrationsFs := newMigrationsFs(storagePathOf(projPath, modules, batch[0].Module), batch)
+		err = newDBMate(config, migrationsFs, []string{"migration"}).Migrate()
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+	}
 
 	fmt.Println(
 		color.GreenString(
@@ -67,3 +121,17 @@ func (c *Migrate) Invoke(ctx *cli.Context) error {
 
 	return nil
 }
+
+// createDatabase creates the database if it does not exist yet
+func createDatabase(config pgx.ModuleConfig) error {
+	drv, err := newDBMate(config, nil, nil).Driver()
+	if err != nil {
+		return err
+	}
+	exists, err := drv.DatabaseExists()
+	// skip creating if the status is unknown (e.g. the user cannot list the databases)
+	if err == nil && !exists {
+		return drv.CreateDatabase()
+	}
+	return nil
+}
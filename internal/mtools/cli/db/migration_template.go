@@ -0,0 +1,120 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/templates"
+)
+
+var ErrMigrationTemplateNotFound = errbuilder.New("migration template is not found").
+	WithHint("Use one of the built-in templates create-table, add-column, add-index, create-enum or add the <template>.sql.tmpl file to the templates/migration folder of the project").Build()
+var ErrInvalidMigrationTemplate = errbuilder.New("migration template is invalid").
+	WithHint("Check the syntax of the template, the available variables are listed in the README").Build()
+
+// DefaultMigrationTemplatesDir is the folder of the project migration templates related to the project root.
+// The project templates take precedence over the built-in ones with the same names.
+const DefaultMigrationTemplatesDir = "templates/migration"
+
+const migrationTemplateExt = ".sql.tmpl"
+
+// MigrationTmplVars are the variables of the migration templates
+type MigrationTmplVars struct {
+	// Name is the name of the migration, e.g. create_users_table
+	Name   string
+	Module module.Manifesto
+	// Schema is the schema of the module, public if it is unknown
+	Schema string
+	Table  string
+	Column string
+	Enum   string
+}
+
+// migrationNamePatterns infer the names of the objects from the migration name.
+// The first matching pattern is used.
+var migrationNamePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^add_(?P<column>\w+?)_index_(?:to|on)_(?P<table>\w+?)(?:_table)?$`),
+	regexp.MustCompile(`^add_index_(?:to|on)_(?P<table>\w+?)(?:_table)?$`),
+	regexp.MustCompile(`^add_(?P<column>\w+?)(?:_column)?_to_(?P<table>\w+?)(?:_table)?$`),
+	regexp.MustCompile(`^create_(?P<enum>\w+?)_(?:enum|type)$`),
+	regexp.MustCompile(`^(?:create|alter|update|change|drop)_(?P<table>\w+?)(?:_table)?$`),
+}
+
+// NewMigrationTmplVars infers the table, column and enum names from the migration name,
+// e.g. create_users_table, add_email_to_users, add_email_index_to_users, create_user_status_enum.
+// The names that cannot be inferred are filled with the placeholders to edit.
+func NewMigrationTmplVars(md module.Manifesto, name string, schema string) MigrationTmplVars {
+	if schema == "" {
+		schema = "public"
+	}
+	vars := MigrationTmplVars{
+		Name:   name,
+		Module: md,
+		Schema: schema,
+	}
+	normalized := strings.ToLower(strings.NewReplacer("-", "_", " ", "_").Replace(strings.TrimSpace(name)))
+	for _, pattern := range migrationNamePatterns {
+		matches := pattern.FindStringSubmatch(normalized)
+		if matches == nil {
+			continue
+		}
+		for i, group := range pattern.SubexpNames() {
+			switch group {
+			case "table":
+				vars.Table = matches[i]
+			case "column":
+				vars.Column = matches[i]
+			case "enum":
+				vars.Enum = matches[i]
+			}
+		}
+		break
+	}
+	if vars.Table == "" {
+		vars.Table = normalized
+	}
+	if vars.Column == "" {
+		vars.Column = "column_name"
+	}
+	if vars.Enum == "" {
+		vars.Enum = normalized
+	}
+	return vars
+}
+
+// RenderMigrationTemplate renders the migration template with the name.
+// The template is looked up in the project templates dir first, then among the built-in templates.
+func RenderMigrationTemplate(templatesDir string, name string, vars MigrationTmplVars) ([]byte, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, errors.WithCause(ErrMigrationTemplateNotFound, fmt.Errorf("%s", name))
+	}
+	content, err := os.ReadFile(filepath.Join(templatesDir, name+migrationTemplateExt))
+	if os.IsNotExist(err) {
+		content, err = templates.TemplateFiles.ReadFile("migration/" + name + migrationTemplateExt)
+		if err != nil {
+			return nil, errors.WithCause(ErrMigrationTemplateNotFound, fmt.Errorf("%s", name))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, errors.WithCause(ErrInvalidMigrationTemplate, err)
+	}
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, vars)
+	if err != nil {
+		return nil, errors.WithCause(ErrInvalidMigrationTemplate, err)
+	}
+	return b.Bytes(), nil
+}
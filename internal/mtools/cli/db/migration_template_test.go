@@ -0,0 +1,88 @@
+package db_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/cli/db"
+	"github.com/go-modulus/mtools/internal/mtools/sqllint"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMigrationTmplVars(t *testing.T) {
+	cases := []struct {
+		name   string
+		table  string
+		column string
+		enum   string
+	}{
+		{"create_users_table", "users", "column_name", "create_users_table"},
+		{"create_users", "users", "column_name", "create_users"},
+		{"add_email_to_users", "users", "email", "add_email_to_users"},
+		{"add_first_name_column_to_users_table", "users", "first_name", "add_first_name_column_to_users_table"},
+		{"add_email_index_to_users", "users", "email", "add_email_index_to_users"},
+		{"create_user_status_enum", "create_user_status_enum", "column_name", "user_status"},
+		{"backfill", "backfill", "column_name", "backfill"},
+	}
+	for _, c := range cases {
+		t.Run(
+			c.name, func(t *testing.T) {
+				vars := db.NewMigrationTmplVars(module.Manifesto{Name: "user"}, c.name, "")
+
+				t.Log("When infer the names from the migration name")
+				t.Log("	The names should be taken from the migration name or filled with the placeholders")
+				require.Equal(t, c.table, vars.Table)
+				require.Equal(t, c.column, vars.Column)
+				require.Equal(t, c.enum, vars.Enum)
+				t.Log("	The schema should be public by default")
+				require.Equal(t, "public", vars.Schema)
+			},
+		)
+	}
+}
+
+func TestRenderMigrationTemplate(t *testing.T) {
+	t.Run(
+		"render the built-in templates passing the linter", func(t *testing.T) {
+			for _, name := range []string{"create-table", "add-column", "add-index", "create-enum"} {
+				vars := db.NewMigrationTmplVars(module.Manifesto{Name: "user"}, "add_email_to_users", "auth")
+
+				content, err := db.RenderMigrationTemplate(t.TempDir(), name, vars)
+
+				t.Log("When render the built-in template " + name)
+				t.Log("	The error should be nil")
+				require.NoError(t, err)
+				t.Log("	The migration should use the schema of the module and pass the linter")
+				require.Contains(t, string(content), "auth.")
+				require.Empty(t, sqllint.Lint(string(content), "auth"))
+			}
+		},
+	)
+
+	t.Run(
+		"prefer the project template", func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(
+				t,
+				os.WriteFile(
+					dir+"/create-table.sql.tmpl",
+					[]byte("-- migrate:up\nCREATE TABLE {{.Schema}}.{{.Table}} (id bigint);\n"),
+					0644,
+				),
+			)
+			vars := db.NewMigrationTmplVars(module.Manifesto{Name: "user"}, "create_users_table", "auth")
+
+			content, err := db.RenderMigrationTemplate(dir, "create-table", vars)
+			_, errUnknown := db.RenderMigrationTemplate(dir, "create-view", vars)
+
+			t.Log("Given the project template overriding the built-in one")
+			t.Log("When render the template")
+			t.Log("	The project template should be used")
+			require.NoError(t, err)
+			require.Equal(t, "-- migrate:up\nCREATE TABLE auth.users (id bigint);\n", string(content))
+			t.Log("	The unknown template should not be found")
+			require.ErrorIs(t, errUnknown, db.ErrMigrationTemplateNotFound)
+		},
+	)
+}
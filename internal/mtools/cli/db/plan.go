@@ -0,0 +1,372 @@
+package db
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrDbModuleNotFound = errbuilder.New("module with migrations is not found in the project").
+	WithHint("Run mtools db status to see the modules with migrations").Build()
+var ErrInvalidMigrationVersion = errbuilder.New("invalid migration version").
+	WithHint("Use the numeric prefix of the migration file name, e.g. --to=20240101000000").Build()
+var ErrInvalidSteps = errbuilder.New("invalid number of steps").
+	WithHint("Use a positive number of the migrations, e.g. --steps=2").Build()
+
+var versionRegexp = regexp.MustCompile(`^\d+$`)
+
+// PlannedMigration is a migration file of the module to apply or to roll back
+type PlannedMigration struct {
+	Module   string
+	Version  string
+	FileName string
+}
+
+// PlanOptions choose the migrations to apply or to roll back
+type PlanOptions struct {
+	// Modules are the names of the modules to act on. All modules are used if it is empty.
+	Modules []string
+	// To is the version to migrate up to or to roll back to. The migration of this version is kept applied.
+	To string
+	// Steps is the maximum number of the migrations. There is no limit if it is 0.
+	Steps int
+}
+
+func newModuleFlag(usage string) cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:    "module",
+		Usage:   usage,
+		Aliases: []string{"m"},
+	}
+}
+
+func planOptionsValue(ctx *cli.Context) (PlanOptions, error) {
+	opts := PlanOptions{
+		Modules: ctx.StringSlice("module"),
+		To:      strings.TrimSpace(ctx.String("to")),
+		Steps:   ctx.Int("steps"),
+	}
+	if opts.To != "" && !versionRegexp.MatchString(opts.To) {
+		return opts, ErrInvalidMigrationVersion
+	}
+	if opts.Steps < 0 {
+		return opts, ErrInvalidSteps
+	}
+	return opts, nil
+}
+
+// PlanMigrate returns the pending migrations to apply in the order they should be applied.
+// The chosen modules are migrated together with the modules they depend on.
+// The migrations go in the order of their versions across all modules.
+// A migration goes after the pending migrations of the modules its module depends on, even if they are newer.
+func PlanMigrate(
+	groups []ModuleMigrations,
+	modules []module.Manifesto,
+	opts PlanOptions,
+) ([]PlannedMigration, error) {
+	groups, err := chooseGroups(groups, modules, opts.Modules, true)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]ModuleMigrations, 0, len(groups))
+	for _, group := range groups {
+		res := ModuleMigrations{Module: group.Module}
+		for _, migration := range group.Migrations {
+			if migration.State == MigrationPending && (opts.To == "" || compareVersions(migration.Version, opts.To) <= 0) {
+				res.Migrations = append(res.Migrations, migration)
+			}
+		}
+		if len(res.Migrations) != 0 {
+			pending = append(pending, res)
+		}
+	}
+	res, err := orderMigrations(pending, modules)
+	if err != nil {
+		return nil, err
+	}
+	return limit(res, opts.Steps), nil
+}
+
+// PlanRollback returns the applied migrations to roll back in the order they should be rolled back.
+// Without the chosen modules, the latest migrations of the project are rolled back.
+// Otherwise, the latest migrations of each chosen module are rolled back in the reverse order of applying them.
+// Only the latest migration is rolled back if neither To nor Steps is set.
+func PlanRollback(
+	groups []ModuleMigrations,
+	modules []module.Manifesto,
+	opts PlanOptions,
+) ([]PlannedMigration, error) {
+	groups, err := chooseGroups(groups, modules, opts.Modules, false)
+	if err != nil {
+		return nil, err
+	}
+	steps := opts.Steps
+	if steps == 0 && opts.To == "" {
+		steps = 1
+	}
+
+	applied := make([]ModuleMigrations, 0, len(groups))
+	for _, group := range groups {
+		res := ModuleMigrations{Module: group.Module}
+		for _, migration := range group.Migrations {
+			if migration.State == MigrationApplied && (opts.To == "" || compareVersions(migration.Version, opts.To) > 0) {
+				res.Migrations = append(res.Migrations, migration)
+			}
+		}
+		if len(res.Migrations) != 0 {
+			applied = append(applied, res)
+		}
+	}
+
+	res := make([]PlannedMigration, 0)
+	if len(opts.Modules) == 0 {
+		for _, group := range applied {
+			for _, migration := range group.Migrations {
+				res = append(res, PlannedMigration{Module: group.Module, Version: migration.Version, FileName: migration.FileName})
+			}
+		}
+		sort.SliceStable(
+			res, func(i, j int) bool {
+				return res[i].FileName > res[j].FileName
+			},
+		)
+		return limit(res, steps), nil
+	}
+
+	chosen := make([]ModuleMigrations, 0, len(applied))
+	for _, group := range applied {
+		if steps > 0 && len(group.Migrations) > steps {
+			group.Migrations = group.Migrations[len(group.Migrations)-steps:]
+		}
+		chosen = append(chosen, group)
+	}
+	// the migrations are rolled back in the reverse order of applying them
+	res, err = orderMigrations(chosen, modules)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(res)
+	return res, nil
+}
+
+// compareVersions compares the numeric versions of the migrations, e.g. the version 9 goes before 10.
+// Returns -1 if a goes before b, 1 if a goes after b and 0 if the versions are equal.
+func compareVersions(a string, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return cmp.Compare(len(a), len(b))
+	}
+	return strings.Compare(a, b)
+}
+
+func limit(migrations []PlannedMigration, steps int) []PlannedMigration {
+	if steps > 0 && len(migrations) > steps {
+		return migrations[:steps]
+	}
+	return migrations
+}
+
+// chooseGroups returns the migrations of the chosen modules.
+// The modules they depend on are added if withDependencies is true.
+func chooseGroups(
+	groups []ModuleMigrations,
+	modules []module.Manifesto,
+	names []string,
+	withDependencies bool,
+) ([]ModuleMigrations, error) {
+	res := make([]ModuleMigrations, 0, len(groups))
+	for _, group := range groups {
+		if group.Module != "" {
+			res = append(res, group)
+		}
+	}
+	if len(names) == 0 {
+		return res, nil
+	}
+
+	chosen := make(map[string]struct{})
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !slices.ContainsFunc(
+			res, func(group ModuleMigrations) bool {
+				return group.Module == name
+			},
+		) {
+			return nil, errors.WithCause(ErrDbModuleNotFound, fmt.Errorf("%s", name))
+		}
+		chosen[name] = struct{}{}
+		if withDependencies {
+			for dep := range dependenciesOf(name, modules) {
+				chosen[dep] = struct{}{}
+			}
+		}
+	}
+	return slices.DeleteFunc(
+		res, func(group ModuleMigrations) bool {
+			_, ok := chosen[group.Module]
+			return !ok
+		},
+	), nil
+}
+
+// dependenciesOf returns the names of all modules the module depends on directly or transitively
+func dependenciesOf(name string, modules []module.Manifesto) map[string]struct{} {
+	byName := make(map[string]module.Manifesto, len(modules))
+	for _, md := range modules {
+		byName[md.Name] = md
+	}
+	res := make(map[string]struct{})
+	var visit func(name string)
+	visit = func(name string) {
+		for _, dependency := range byName[name].Install.Dependencies {
+			depName, _ := manifesto.SplitDependency(dependency)
+			if _, ok := res[depName]; ok {
+				continue
+			}
+			res[depName] = struct{}{}
+			visit(depName)
+		}
+	}
+	visit(name)
+	delete(res, name)
+	return res
+}
+
+// orderMigrations returns the migrations of the groups in the order of applying them.
+// The migrations go in the order of their versions, a migration is postponed
+// until the migrations of the modules its module depends on are applied.
+func orderMigrations(groups []ModuleMigrations, modules []module.Manifesto) ([]PlannedMigration, error) {
+	deps := make(map[string]map[string]struct{}, len(groups))
+	rest := make([]PlannedMigration, 0)
+	for _, group := range groups {
+		deps[group.Module] = dependenciesOf(group.Module, modules)
+		for _, migration := range group.Migrations {
+			rest = append(rest, PlannedMigration{Module: group.Module, Version: migration.Version, FileName: migration.FileName})
+		}
+	}
+	sort.SliceStable(
+		rest, func(i, j int) bool {
+			return rest[i].FileName < rest[j].FileName
+		},
+	)
+
+	res := make([]PlannedMigration, 0, len(rest))
+	for len(rest) != 0 {
+		// the earliest migration whose module does not wait for the migrations of its dependencies
+		next := slices.IndexFunc(
+			rest, func(migration PlannedMigration) bool {
+				return !slices.ContainsFunc(
+					rest, func(other PlannedMigration) bool {
+						_, ok := deps[migration.Module][other.Module]
+						return ok && other.Module != migration.Module
+					},
+				)
+			},
+		)
+		if next == -1 {
+			names := make([]string, 0)
+			for _, migration := range rest {
+				if !slices.Contains(names, migration.Module) {
+					names = append(names, migration.Module)
+				}
+			}
+			return nil, fmt.Errorf("%w: %s", manifesto.ErrDependencyCycle, strings.Join(names, ", "))
+		}
+		res = append(res, rest[next])
+		rest = slices.Delete(rest, next, next+1)
+	}
+	return res, nil
+}
+
+// batches splits the planned migrations into the runs of the same module keeping their order
+func batches(plan []PlannedMigration) [][]PlannedMigration {
+	res := make([][]PlannedMigration, 0)
+	for i, migration := range plan {
+		if i == 0 || plan[i-1].Module != migration.Module {
+			res = append(res, make([]PlannedMigration, 0))
+		}
+		res[len(res)-1] = append(res[len(res)-1], migration)
+	}
+	return res
+}
+
+// migrationsFs shows dbmate only the planned migration files of the module storage folder
+type migrationsFs struct {
+	fs.FS
+	files map[string]struct{}
+}
+
+func newMigrationsFs(storagePath string, plan []PlannedMigration) *migrationsFs {
+	files := make(map[string]struct{}, len(plan))
+	for _, migration := range plan {
+		files[path.Join("migration", migration.FileName)] = struct{}{}
+	}
+	return &migrationsFs{FS: os.DirFS(storagePath), files: files}
+}
+
+func (f *migrationsFs) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(f.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(
+		entries, func(entry fs.DirEntry) bool {
+			_, ok := f.files[path.Join(name, entry.Name())]
+			return !ok
+		},
+	), nil
+}
+
+// loadMigrations returns the migrations of the project modules with their states and the project modules
+func loadMigrations(projPath string, manifestFile string, applied map[string]bool) (
+	[]ModuleMigrations,
+	[]module.Manifesto,
+	error,
+) {
+	if manifestFile == "" {
+		manifestFile = "modules.json"
+	}
+	manifest, err := manifesto.NewFromFs(os.DirFS(projPath), manifestFile)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot load the project manifest %s/%s: %s", projPath, manifestFile, err.Error()))
+		return nil, nil, err
+	}
+	groups, err := MigrationsStatus(projPath, manifest.Modules, applied)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the migration files: %s", err.Error()))
+		return nil, nil, err
+	}
+	return groups, manifest.Modules, nil
+}
+
+// storagePathOf returns the storage folder of the module with the name
+func storagePathOf(projPath string, modules []module.Manifesto, name string) string {
+	for _, md := range modules {
+		if md.Name == name {
+			return md.StoragePath(projPath)
+		}
+	}
+	return ""
+}
+
+// printPlan prints the planned migrations in their order
+func printPlan(title string, plan []PlannedMigration) {
+	fmt.Println(color.YellowString("%s:", title))
+	for i, migration := range plan {
+		fmt.Printf("%d. %s %s\n", i+1, color.BlueString(migration.Module), migration.FileName)
+	}
+}
@@ -0,0 +1,281 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/cli/db"
+	"github.com/stretchr/testify/require"
+)
+
+func newPlanModules() []module.Manifesto {
+	user := module.Manifesto{Name: "user", LocalPath: "internal/user", IsLocalModule: true}
+	user.Install.Dependencies = []string{"pgx"}
+	blog := module.Manifesto{Name: "blog", LocalPath: "internal/blog", IsLocalModule: true}
+	blog.Install.Dependencies = []string{"pgx", "user@^1.0.0"}
+	return []module.Manifesto{
+		{Name: "pgx", Package: "github.com/go-modulus/modulus/db/pgx"},
+		blog,
+		user,
+		{Name: "audit", LocalPath: "internal/audit", IsLocalModule: true},
+	}
+}
+
+func newPlanGroups() []db.ModuleMigrations {
+	return []db.ModuleMigrations{
+		{
+			// the blog migration is created earlier than the user one it depends on
+			Module: "blog",
+			Migrations: []db.MigrationStatus{
+				{Version: "1", FileName: "1_create_posts.sql", State: db.MigrationApplied},
+				{Version: "3", FileName: "3_add_author.sql", State: db.MigrationApplied},
+				{Version: "5", FileName: "5_add_tags.sql", State: db.MigrationPending},
+			},
+		},
+		{
+			Module: "user",
+			Migrations: []db.MigrationStatus{
+				{Version: "2", FileName: "2_create_users.sql", State: db.MigrationApplied},
+				{Version: "6", FileName: "6_add_email.sql", State: db.MigrationPending},
+			},
+		},
+		{
+			Module: "audit",
+			Migrations: []db.MigrationStatus{
+				{Version: "4", FileName: "4_create_log.sql", State: db.MigrationApplied},
+				{Version: "7", FileName: "7_add_ip.sql", State: db.MigrationPending},
+			},
+		},
+		{
+			Migrations: []db.MigrationStatus{
+				{Version: "0", State: db.MigrationMissing},
+			},
+		},
+	}
+}
+
+func TestPlanMigrate(t *testing.T) {
+	t.Run(
+		"apply the migrations of the dependencies first", func(t *testing.T) {
+			plan, err := db.PlanMigrate(newPlanGroups(), newPlanModules(), db.PlanOptions{})
+
+			t.Log("Given the blog module depending on the user module")
+			t.Log("When plan all pending migrations")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The user migrations should go before the blog ones")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "user", Version: "6", FileName: "6_add_email.sql"},
+					{Module: "blog", Version: "5", FileName: "5_add_tags.sql"},
+					{Module: "audit", Version: "7", FileName: "7_add_ip.sql"},
+				}, plan,
+			)
+		},
+	)
+
+	t.Run(
+		"apply the migrations of the module and its dependencies", func(t *testing.T) {
+			plan, err := db.PlanMigrate(
+				newPlanGroups(),
+				newPlanModules(),
+				db.PlanOptions{Modules: []string{"blog"}, To: "5"},
+			)
+
+			t.Log("When plan the migrations of the blog module up to the version 5")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	Only the blog migration should be applied, the user migration is newer than the version")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "blog", Version: "5", FileName: "5_add_tags.sql"},
+				}, plan,
+			)
+		},
+	)
+
+	t.Run(
+		"keep the version order of the independent modules", func(t *testing.T) {
+			groups := []db.ModuleMigrations{
+				{
+					Module: "user",
+					Migrations: []db.MigrationStatus{
+						{Version: "0101", FileName: "0101_create_users.sql", State: db.MigrationPending},
+						{Version: "0201", FileName: "0201_add_favorite_post_id.sql", State: db.MigrationPending},
+					},
+				},
+				{
+					Module: "blog",
+					Migrations: []db.MigrationStatus{
+						{Version: "0115", FileName: "0115_create_posts.sql", State: db.MigrationPending},
+					},
+				},
+			}
+			modules := []module.Manifesto{
+				{Name: "user", LocalPath: "internal/user", IsLocalModule: true},
+				{Name: "blog", LocalPath: "internal/blog", IsLocalModule: true},
+			}
+
+			plan, err := db.PlanMigrate(groups, modules, db.PlanOptions{})
+
+			t.Log("Given two modules without declared dependencies whose versions interleave")
+			t.Log("When plan all pending migrations")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The migrations should go in the order of their versions across the modules")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "user", Version: "0101", FileName: "0101_create_users.sql"},
+					{Module: "blog", Version: "0115", FileName: "0115_create_posts.sql"},
+					{Module: "user", Version: "0201", FileName: "0201_add_favorite_post_id.sql"},
+				}, plan,
+			)
+		},
+	)
+
+	t.Run(
+		"compare the versions as numbers", func(t *testing.T) {
+			groups := []db.ModuleMigrations{
+				{
+					Module: "user",
+					Migrations: []db.MigrationStatus{
+						{Version: "9", FileName: "9_create_users.sql", State: db.MigrationPending},
+						{Version: "10", FileName: "10_add_email.sql", State: db.MigrationPending},
+					},
+				},
+			}
+			modules := []module.Manifesto{{Name: "user", LocalPath: "internal/user", IsLocalModule: true}}
+
+			plan, err := db.PlanMigrate(groups, modules, db.PlanOptions{To: "9"})
+
+			t.Log("Given the migrations of the versions 9 and 10")
+			t.Log("When plan the migrations up to the version 9")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The migration of the version 10 should not be applied")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "user", Version: "9", FileName: "9_create_users.sql"},
+				}, plan,
+			)
+		},
+	)
+
+	t.Run(
+		"limit the number of the migrations", func(t *testing.T) {
+			plan, err := db.PlanMigrate(newPlanGroups(), newPlanModules(), db.PlanOptions{Steps: 1})
+
+			t.Log("When plan one step")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The first migration should be applied only")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "user", Version: "6", FileName: "6_add_email.sql"},
+				}, plan,
+			)
+		},
+	)
+
+	t.Run(
+		"reject an unknown module", func(t *testing.T) {
+			_, err := db.PlanMigrate(
+				newPlanGroups(),
+				newPlanModules(),
+				db.PlanOptions{Modules: []string{"pgx"}},
+			)
+
+			t.Log("When plan the migrations of a module without migrations")
+			t.Log("	The error should be ErrDbModuleNotFound")
+			require.ErrorIs(t, err, db.ErrDbModuleNotFound)
+		},
+	)
+}
+
+func TestPlanRollback(t *testing.T) {
+	t.Run(
+		"roll back the latest migration of the project", func(t *testing.T) {
+			plan, err := db.PlanRollback(newPlanGroups(), newPlanModules(), db.PlanOptions{})
+
+			t.Log("When plan the rollback without flags")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The latest applied migration should be rolled back")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "audit", Version: "4", FileName: "4_create_log.sql"},
+				}, plan,
+			)
+		},
+	)
+
+	t.Run(
+		"roll back the migrations of the module only", func(t *testing.T) {
+			plan, err := db.PlanRollback(
+				newPlanGroups(),
+				newPlanModules(),
+				db.PlanOptions{Modules: []string{"blog"}, Steps: 2},
+			)
+
+			t.Log("When plan the rollback of 2 steps of the blog module")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The latest blog migrations should be rolled back, the user ones should be kept")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "blog", Version: "3", FileName: "3_add_author.sql"},
+					{Module: "blog", Version: "1", FileName: "1_create_posts.sql"},
+				}, plan,
+			)
+		},
+	)
+
+	t.Run(
+		"roll back to the version compared as a number", func(t *testing.T) {
+			groups := []db.ModuleMigrations{
+				{
+					Module: "user",
+					Migrations: []db.MigrationStatus{
+						{Version: "9", FileName: "9_create_users.sql", State: db.MigrationApplied},
+						{Version: "10", FileName: "10_add_email.sql", State: db.MigrationApplied},
+					},
+				},
+			}
+			modules := []module.Manifesto{{Name: "user", LocalPath: "internal/user", IsLocalModule: true}}
+
+			plan, err := db.PlanRollback(groups, modules, db.PlanOptions{To: "9"})
+
+			t.Log("Given the applied migrations of the versions 9 and 10")
+			t.Log("When plan the rollback to the version 9")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	Only the migration of the version 10 should be rolled back")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "user", Version: "10", FileName: "10_add_email.sql"},
+				}, plan,
+			)
+		},
+	)
+
+	t.Run(
+		"roll back the dependent modules first", func(t *testing.T) {
+			plan, err := db.PlanRollback(
+				newPlanGroups(),
+				newPlanModules(),
+				db.PlanOptions{Modules: []string{"user", "blog"}, To: "1"},
+			)
+
+			t.Log("When plan the rollback of the user and blog modules to the version 1")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The blog migrations should be rolled back before the user ones")
+			require.Equal(
+				t, []db.PlannedMigration{
+					{Module: "blog", Version: "3", FileName: "3_add_author.sql"},
+					{Module: "user", Version: "2", FileName: "2_create_users.sql"},
+				}, plan,
+			)
+		},
+	)
+}
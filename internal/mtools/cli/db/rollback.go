@@ -5,6 +5,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/go-modulus/modulus/errors/errtrace"
 	"github.com/go-modulus/mtools/internal/mtools/action"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
 	"github.com/urfave/cli/v2"
 )
 
@@ -24,8 +25,12 @@ func NewRollbackCommand(updateSqlc *Rollback) *cli.Command {
 	return &cli.Command{
 		Name: "rollback",
 		Usage: `Rollbacks the last applied migration.
+With the --module flag rollbacks the last applied migrations of the module only, the other modules are not touched.
 Example: mtools db rollback
 Example: mtools db rollback --proj-path=/path/to/project/root
+Example for the last 3 migrations of a module: mtools db rollback --module=blog --steps=3
+Example: mtools db rollback --module=blog --to=20240101000000
+Example for printing the migrations to roll back without rolling them back: mtools --dry-run db rollback
 `,
 		Action: updateSqlc.Invoke,
 		Flags: []cli.Flag{
@@ -34,6 +39,15 @@ Example: mtools db rollback --proj-path=/path/to/project/root
 				Usage:   "Local manifest file related to the project root. Default is modules.json",
 				Aliases: []string{"lmf"},
 			},
+			newModuleFlag("The name of the module to roll back. Can be repeated. The latest migrations of the project are rolled back by default"),
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "Roll back the migrations applied after the version. The migration of the version is kept",
+			},
+			&cli.IntFlag{
+				Name:  "steps",
+				Usage: "Roll back this number of the migrations (of each module with the --module flag). Default is 1 if --to is not set",
+			},
 		},
 	}
 }
@@ -46,21 +60,52 @@ func (c *Rollback) Invoke(ctx *cli.Context) error {
 		return errtrace.Wrap(err)
 	}
 
-	manifest := ctx.String("local-manifest")
-	projFs, err := commonMigrationFs(projPath, manifest)
+	opts, err := planOptionsValue(ctx)
 	if err != nil {
-		return errtrace.Wrap(err)
+		fmt.Println(color.RedString("Cannot parse the flags: %s", err.Error()))
+		return err
 	}
 
-	dbMate := newDBMate(config, projFs, []string{"migration"})
-	err = dbMate.Rollback()
+	applied, err := appliedMigrations(config)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the applied migrations: %s", err.Error()))
+		return errtrace.Wrap(err)
+	}
+	groups, modules, err := loadMigrations(projPath, ctx.String("local-manifest"), applied)
 	if err != nil {
 		return errtrace.Wrap(err)
 	}
+	plan, err := PlanRollback(groups, modules, opts)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot plan the rollback: %s", err.Error()))
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Println(color.YellowString("There are no applied migrations to roll back."))
+		return nil
+	}
+	if flag.DryRunValue(ctx) {
+		printPlan("Dry run: the migrations to roll back", plan)
+		return nil
+	}
+
+	// dbmate rolls back the latest applied migration of its files, so each migration is rolled back separately
+	for _, migration := range plan {
+		fmt.Printf("Rolling back the module %s...\n", color.BlueString(migration.Module))
+		migrationsFs := newMigrationsFs(
+			storagePathOf(projPath, modules, migration.Module),
+			[]PlannedMigration{migration},
+		)
+		err = newDBMate(config, migrationsFs, []string{"migration"}).Rollback()
+		if err != nil {
+			return errtrace.Wrap(err)
+		}
+	}
 
 	fmt.Println(
 		color.GreenString(
-			"The last migration is rolled back.",
+			"%d migration(s) rolled back.",
+			len(plan),
 		),
 	)
 
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/db/pgx"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/errors/errtrace"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrPendingMigrations = errbuilder.New("there are pending migrations").
+	WithHint("Run mtools db migrate to apply them").Build()
+
+// migrationFileRegexp matches the migration files the same way dbmate does
+var migrationFileRegexp = regexp.MustCompile(`^(\d+).*\.sql$`)
+
+type MigrationState string
+
+const (
+	MigrationApplied MigrationState = "applied"
+	MigrationPending MigrationState = "pending"
+	// MigrationMissing is an applied migration whose file is not found in any module
+	MigrationMissing MigrationState = "missing"
+)
+
+type MigrationStatus struct {
+	Version string
+	// FileName is empty for the missing migrations
+	FileName string
+	State    MigrationState
+}
+
+// ModuleMigrations are the migrations of one module sorted by the file names
+type ModuleMigrations struct {
+	// Module is the name of the module owning the migrations. It is empty for the missing migrations.
+	Module     string
+	Migrations []MigrationStatus
+}
+
+type Status struct {
+}
+
+func NewStatus() *Status {
+	return &Status{}
+}
+
+func NewStatusCommand(status *Status) *cli.Command {
+	return &cli.Command{
+		Name: "status",
+		Usage: `Shows the migrations of all modules marked as applied or pending.
+Reports the applied migrations whose files are not found in any module.
+Example: mtools db status
+Example for CI: mtools db status --exit-code
+`,
+		Action: status.Invoke,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "local-manifest",
+				Usage:   "Local manifest file related to the project root. Default is modules.json",
+				Aliases: []string{"lmf"},
+			},
+			&cli.BoolFlag{
+				Name:  "exit-code",
+				Usage: "Exit with a non-zero code if there are pending migrations",
+			},
+		},
+	}
+}
+
+func (c *Status) Invoke(ctx *cli.Context) error {
+	projPath := ctx.String("proj-path")
+	config, err := newPgxConfig(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot load the project config: %s", err.Error()))
+		return errtrace.Wrap(err)
+	}
+
+	manifestFile := ctx.String("local-manifest")
+	if manifestFile == "" {
+		manifestFile = "modules.json"
+	}
+	manifest, err := manifesto.NewFromFs(os.DirFS(projPath), manifestFile)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot load the project manifest %s/%s: %s", projPath, manifestFile, err.Error()))
+		return errtrace.Wrap(err)
+	}
+
+	applied, err := appliedMigrations(config)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the applied migrations: %s", err.Error()))
+		return errtrace.Wrap(err)
+	}
+	groups, err := MigrationsStatus(projPath, manifest.Modules, applied)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the migration files: %s", err.Error()))
+		return errtrace.Wrap(err)
+	}
+
+	counts := printStatus(groups)
+	fmt.Printf(
+		"%s applied, %s pending, %s missing\n",
+		color.GreenString("%d", counts[MigrationApplied]),
+		color.YellowString("%d", counts[MigrationPending]),
+		color.RedString("%d", counts[MigrationMissing]),
+	)
+	if ctx.Bool("exit-code") && counts[MigrationPending] != 0 {
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(ErrPendingMigrations)))
+		return ErrPendingMigrations
+	}
+	return nil
+}
+
+// MigrationsStatus groups the migration files of the modules by the modules and marks them applied or pending.
+// The applied versions without files are returned in the last group without the module name.
+func MigrationsStatus(
+	projPath string,
+	modules []module.Manifesto,
+	applied map[string]bool,
+) ([]ModuleMigrations, error) {
+	res := make([]ModuleMigrations, 0)
+	found := make(map[string]struct{})
+	for _, md := range modules {
+		if md.LocalPath == "" {
+			continue
+		}
+		entries, err := os.ReadDir(md.StoragePath(projPath) + "/migration")
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		group := ModuleMigrations{Module: md.Name, Migrations: make([]MigrationStatus, 0)}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			matches := migrationFileRegexp.FindStringSubmatch(entry.Name())
+			if len(matches) < 2 {
+				continue
+			}
+			state := MigrationPending
+			if applied[matches[1]] {
+				state = MigrationApplied
+			}
+			found[matches[1]] = struct{}{}
+			group.Migrations = append(
+				group.Migrations,
+				MigrationStatus{Version: matches[1], FileName: entry.Name(), State: state},
+			)
+		}
+		if len(group.Migrations) != 0 {
+			res = append(res, group)
+		}
+	}
+
+	missing := ModuleMigrations{Migrations: make([]MigrationStatus, 0)}
+	for version, ok := range applied {
+		if _, exists := found[version]; ok && !exists {
+			missing.Migrations = append(missing.Migrations, MigrationStatus{Version: version, State: MigrationMissing})
+		}
+	}
+	if len(missing.Migrations) != 0 {
+		sort.Slice(
+			missing.Migrations, func(i, j int) bool {
+				return missing.Migrations[i].Version < missing.Migrations[j].Version
+			},
+		)
+		res = append(res, missing)
+	}
+	return res, nil
+}
+
+// appliedMigrations returns the versions of the migrations recorded in the schema_migrations table
+func appliedMigrations(config pgx.ModuleConfig) (map[string]bool, error) {
+	drv, err := newDBMate(config, nil, nil).Driver()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := drv.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer sqlDB.Close()
+
+	exists, err := drv.MigrationsTableExists(sqlDB)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return map[string]bool{}, nil
+	}
+	return drv.SelectMigrations(sqlDB, -1)
+}
+
+// printStatus prints the migrations by the modules. Returns the numbers of the migrations by their states.
+func printStatus(groups []ModuleMigrations) map[MigrationState]int {
+	counts := make(map[MigrationState]int)
+	if len(groups) == 0 {
+		fmt.Println(color.YellowString("There are no migrations in the modules of the project."))
+		return counts
+	}
+	for _, group := range groups {
+		if group.Module == "" {
+			fmt.Println(color.RedString("Applied migrations without files:"))
+		} else {
+			fmt.Printf("Module %s:\n", color.BlueString(group.Module))
+		}
+		for _, migration := range group.Migrations {
+			counts[migration.State]++
+			switch migration.State {
+			case MigrationApplied:
+				fmt.Printf("	%s %s\n", color.GreenString("[applied]"), migration.FileName)
+			case MigrationPending:
+				fmt.Printf("	%s %s\n", color.YellowString("[pending]"), migration.FileName)
+			case MigrationMissing:
+				fmt.Printf("	%s %s\n", color.RedString("[missing]"), migration.Version)
+			}
+		}
+	}
+	return counts
+}
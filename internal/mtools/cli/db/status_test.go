@@ -0,0 +1,83 @@
+package db_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/cli/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationsStatus(t *testing.T) {
+	t.Run(
+		"group the migrations by the modules", func(t *testing.T) {
+			projDir := t.TempDir()
+			files := []string{
+				"internal/user/storage/migration/20240101000000_create_users.sql",
+				"internal/user/storage/migration/20240201000000_add_email.sql",
+				"internal/user/storage/migration/README.md",
+				"internal/blog/storage/migration/20240115000000_create_posts.sql",
+			}
+			for _, file := range files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(projDir+"/"+file), 0755))
+				require.NoError(t, os.WriteFile(projDir+"/"+file, []byte("-- migrate:up\n"), 0644))
+			}
+			modules := []module.Manifesto{
+				{Name: "pgx", Package: "github.com/go-modulus/modulus/db/pgx"},
+				{Name: "user", Package: "example.com/app/internal/user", LocalPath: "internal/user", IsLocalModule: true},
+				{Name: "blog", Package: "example.com/app/internal/blog", LocalPath: "internal/blog", IsLocalModule: true},
+				{Name: "auth", Package: "example.com/app/internal/auth", LocalPath: "internal/auth", IsLocalModule: true},
+			}
+			// the versions of the schema_migrations table
+			applied := map[string]bool{
+				"20240101000000": true,
+				"20240115000000": true,
+				"20231201000000": true,
+			}
+
+			groups, err := db.MigrationsStatus(projDir, modules, applied)
+
+			t.Log("Given the modules with the migrations and a database with the applied migrations")
+			t.Log("When get the status of the migrations")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The migrations should be grouped by the modules and the missing files should be reported")
+			require.Equal(
+				t, []db.ModuleMigrations{
+					{
+						Module: "user",
+						Migrations: []db.MigrationStatus{
+							{
+								Version:  "20240101000000",
+								FileName: "20240101000000_create_users.sql",
+								State:    db.MigrationApplied,
+							},
+							{
+								Version:  "20240201000000",
+								FileName: "20240201000000_add_email.sql",
+								State:    db.MigrationPending,
+							},
+						},
+					},
+					{
+						Module: "blog",
+						Migrations: []db.MigrationStatus{
+							{
+								Version:  "20240115000000",
+								FileName: "20240115000000_create_posts.sql",
+								State:    db.MigrationApplied,
+							},
+						},
+					},
+					{
+						Migrations: []db.MigrationStatus{
+							{Version: "20231201000000", State: db.MigrationMissing},
+						},
+					},
+				}, groups,
+			)
+		},
+	)
+}
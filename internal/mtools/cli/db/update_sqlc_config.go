@@ -7,6 +7,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/go-modulus/mtools/internal/manifesto"
 	"github.com/go-modulus/mtools/internal/mtools/action"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
 	"github.com/urfave/cli/v2"
 )
 
@@ -27,6 +28,8 @@ func NewUpdateSQLCConfigCommand(updateSqlc *UpdateSQLCConfig) *cli.Command {
 Example: mtools db update-sqlc-config
 `,
 		Action: updateSqlc.Invoke,
+		Before: flag.DryRunBefore,
+		After:  flag.DryRunAfter,
 	}
 }
 
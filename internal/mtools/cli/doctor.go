@@ -0,0 +1,382 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/action"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/files"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/go-modulus/mtools/internal/mtools/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrModuleIsNotInEntrypoint = errbuilder.New("module is not initialized in the entrypoint").
+	WithHint("Add the NewModule() call of the module to the modules slice of the entrypoint or run mtools doctor --fix.").
+	Build()
+var ErrModuleFileNotFound = errbuilder.New("module.go file of the local module is not found").
+	WithHint("Create the module.go file with the NewModule function in the module directory or remove the module from the modules.json file.").
+	Build()
+var ErrSqlcDefinitionNotFound = errbuilder.New("sqlc.definition.yaml file is not found in the project root").
+	WithHint("The file is required to generate the sqlc.yaml files of modules. Run mtools doctor --fix to create it from the template.").
+	Build()
+var ErrSqlcConfigIsOutdated = errbuilder.New("storage/sqlc.yaml file is older than storage/sqlc.tmpl.yaml").
+	WithHint("Run mtools db update-sqlc-config or mtools doctor --fix to regenerate it.").
+	Build()
+var ErrEnvVariableIsMissing = errbuilder.New("env variable declared in the module manifest is missing in the .env file").
+	WithHint("Add the variable to the .env file or run mtools doctor --fix to add it with the default value.").
+	Build()
+var ErrInstalledFileIsModified = errbuilder.New("file installed by the module is modified locally").
+	WithHint("The file differs from the one downloaded during the module installation. Ignore it if the changes are intentional.").
+	Build()
+var ErrProjectHasProblems = errbuilder.New("project has problems").
+	WithHint("Fix the problems listed above manually or run mtools doctor --fix to repair the ones that can be fixed automatically.").
+	Build()
+
+type problem struct {
+	err     error
+	details string
+	// fix repairs the problem. It is nil if the problem cannot be fixed automatically.
+	fix func() error
+	// isWarning marks the problems that are reported but do not fail the check
+	isWarning bool
+}
+
+type Doctor struct {
+	logger     *slog.Logger
+	updateSqlc *action.UpdateSqlcConfig
+}
+
+func NewDoctor(
+	logger *slog.Logger,
+	updateSqlc *action.UpdateSqlcConfig,
+) *Doctor {
+	return &Doctor{
+		logger:     logger,
+		updateSqlc: updateSqlc,
+	}
+}
+
+func NewDoctorCommand(c *Doctor) *cli.Command {
+	return &cli.Command{
+		Name: "doctor",
+		Usage: `Checks the project for the drift between the modules.json file and the project files.
+Finds the modules missing in the entrypoints, the local modules without the module.go file,
+the outdated sqlc.yaml files, the missing sqlc.definition.yaml file and the env variables missing in the .env file.
+Example: mtools doctor
+Example: mtools doctor --fix
+Example: mtools --dry-run doctor --fix
+`,
+		Action: c.Invoke,
+		Before: flag.DryRunBefore,
+		After:  flag.DryRunAfter,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "fix",
+				Usage: "Repair the problems that can be fixed automatically",
+			},
+		},
+	}
+}
+
+func (c *Doctor) Invoke(
+	ctx *cli.Context,
+) error {
+	projPath := flag.ProjPathValue(ctx)
+	if projPath == "" {
+		projPath = "."
+	}
+	isFix := ctx.Bool("fix")
+
+	manifest, err := manifesto.LoadLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the local modules.json manifest file: %s", err.Error()))
+		return err
+	}
+	entries, err := manifesto.ReadEntries(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the entrypoints: %s", err.Error()))
+		return err
+	}
+
+	fmt.Println(color.BlueString("Checking the project..."))
+	problems := make([]problem, 0)
+	problems = append(problems, c.checkModuleFiles(manifest, projPath)...)
+	problems = append(problems, c.checkEntrypoints(manifest, entries, projPath)...)
+	problems = append(problems, c.checkSqlcConfigs(ctx.Context, manifest, projPath)...)
+	envProblems, err := c.checkEnvVariables(manifest, projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the .env file: %s", err.Error()))
+		return err
+	}
+	problems = append(problems, envProblems...)
+	fileProblems, err := c.checkInstalledFiles(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the %s file: %s", manifesto.LockFile, err.Error()))
+		return err
+	}
+	problems = append(problems, fileProblems...)
+
+	if len(problems) == 0 {
+		fmt.Println(color.GreenString("No problems found. Your project is healthy."))
+		return nil
+	}
+
+	unfixed := 0
+	for _, p := range problems {
+		if p.isWarning {
+			fmt.Printf("%s %s: %s\n", color.YellowString("!"), color.YellowString(p.err.Error()), p.details)
+			fmt.Println(color.YellowString("  Hint: %s", errors.Hint(p.err)))
+			continue
+		}
+		fmt.Printf("%s %s: %s\n", color.RedString("✗"), color.RedString(p.err.Error()), p.details)
+		if !isFix || p.fix == nil {
+			if errors.Hint(p.err) != "" {
+				fmt.Println(color.YellowString("  Hint: %s", errors.Hint(p.err)))
+			}
+			unfixed++
+			continue
+		}
+		err = p.fix()
+		if err != nil {
+			fmt.Println(color.RedString("  Cannot fix the problem: %s", err.Error()))
+			unfixed++
+			continue
+		}
+		fmt.Println(color.GreenString("  Fixed"))
+	}
+
+	if unfixed == 0 && len(problems) == warnings(problems) {
+		fmt.Println(color.GreenString("No problems found. Check the warnings above."))
+		return nil
+	}
+	if unfixed != 0 {
+		fmt.Println(color.RedString("Found %d problems, %d of them are not fixed.", len(problems)-warnings(problems), unfixed))
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(ErrProjectHasProblems)))
+		return ErrProjectHasProblems
+	}
+	fmt.Println(color.GreenString("All %d problems are fixed.", len(problems)-warnings(problems)))
+	return nil
+}
+
+func (c *Doctor) checkModuleFiles(
+	manifest *manifesto.LocalManifesto,
+	projPath string,
+) []problem {
+	res := make([]problem, 0)
+	for _, md := range manifest.LocalModules() {
+		if utils.FileExists(md.ModulePath(projPath) + "/module.go") {
+			continue
+		}
+		res = append(
+			res, problem{
+				err:     ErrModuleFileNotFound,
+				details: fmt.Sprintf("module %s, file %s/module.go", md.Name, md.LocalPath),
+			},
+		)
+	}
+	return res
+}
+
+// checkEntrypoints finds the modules from the modules.json file that are missing in the modules slice
+// of any entrypoint they are wired into.
+// The project-local files of the installed modules are checked as well, the same way entrypoint sync does it.
+// The local modules without the module.go file are skipped because they cannot be initialized.
+func (c *Doctor) checkEntrypoints(
+	manifest *manifesto.LocalManifesto,
+	entries []manifesto.Entrypoint,
+	projPath string,
+) []problem {
+	res := make([]problem, 0)
+	allEntries := manifesto.EntryNames(entries)
+	projPackage, projPackageErr := utils.ProjectPackage(projPath)
+	for _, md := range manifest.Modules {
+		if md.IsLocalModule && !utils.FileExists(md.ModulePath(projPath)+"/module.go") {
+			continue
+		}
+		pckgs := []string{md.Package}
+		if md.LocalPath != "" && !md.IsLocalModule && utils.FileExists(md.ModulePath(projPath)+"/module.go") {
+			if projPackageErr != nil {
+				fmt.Println(
+					color.YellowString(
+						"Cannot get the project package to check the local files of the module %s: %s",
+						md.Name,
+						projPackageErr.Error(),
+					),
+				)
+			} else {
+				pckgs = append(pckgs, projPackage+"/"+md.LocalPath)
+			}
+		}
+		wired := manifest.EntriesOf(md.Package, allEntries)
+		for _, entry := range entries {
+			if !slices.Contains(wired, entry.Name) {
+				continue
+			}
+			entryFile := projPath + "/" + entry.LocalPath
+			for _, pckg := range pckgs {
+				found, err := files.IsModuleInEntrypoint(pckg, entryFile)
+				if err != nil {
+					fmt.Println(color.YellowString("Cannot parse the entrypoint %s: %s", entry.LocalPath, err.Error()))
+					break
+				}
+				if found {
+					continue
+				}
+				details := fmt.Sprintf("module %s, entrypoint %s", md.Name, entry.LocalPath)
+				if pckg != md.Package {
+					details = fmt.Sprintf("local files %s of the module %s, entrypoint %s", pckg, md.Name, entry.LocalPath)
+				}
+				res = append(
+					res, problem{
+						err:     ErrModuleIsNotInEntrypoint,
+						details: details,
+						fix: func() error {
+							return files.AddModuleToEntrypoint(pckg, entryFile)
+						},
+					},
+				)
+			}
+		}
+	}
+	return res
+}
+
+// checkSqlcConfigs finds the local modules with the storage/sqlc.tmpl.yaml file
+// which storage/sqlc.yaml file is missing or older than the template.
+// The missing sqlc.definition.yaml file is reported first to be fixed before the configs are regenerated.
+func (c *Doctor) checkSqlcConfigs(
+	ctx context.Context,
+	manifest *manifesto.LocalManifesto,
+	projPath string,
+) []problem {
+	res := make([]problem, 0)
+	hasTemplates := false
+	for _, md := range manifest.LocalModules() {
+		storagePath := md.StoragePath(projPath)
+		tmplInfo, err := os.Stat(storagePath + "/sqlc.tmpl.yaml")
+		if err != nil {
+			continue
+		}
+		hasTemplates = true
+		configInfo, err := os.Stat(storagePath + "/sqlc.yaml")
+		if err == nil && !configInfo.ModTime().Before(tmplInfo.ModTime()) {
+			continue
+		}
+		res = append(
+			res, problem{
+				err:     ErrSqlcConfigIsOutdated,
+				details: fmt.Sprintf("module %s, file %s/storage/sqlc.yaml", md.Name, md.LocalPath),
+				fix: func() error {
+					return c.updateSqlc.Update(ctx, storagePath, projPath)
+				},
+			},
+		)
+	}
+
+	if hasTemplates && !utils.FileExists(projPath+"/sqlc.definition.yaml") {
+		res = append(
+			[]problem{
+				{
+					err:     ErrSqlcDefinitionNotFound,
+					details: "file sqlc.definition.yaml",
+					fix: func() error {
+						return utils.CopyFromTemplates(
+							"create_module/sqlc.definition.yaml",
+							projPath+"/sqlc.definition.yaml",
+						)
+					},
+				},
+			}, res...,
+		)
+	}
+	return res
+}
+
+func (c *Doctor) checkEnvVariables(
+	manifest *manifesto.LocalManifesto,
+	projPath string,
+) ([]problem, error) {
+	envFile := projPath + "/.env"
+	keys, err := utils.EnvVariableKeys(envFile)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]problem, 0)
+	for _, md := range manifest.Modules {
+		missing := md.Install.EnvVars[:0:0]
+		missingKeys := make([]string, 0)
+		for _, envVar := range md.Install.EnvVars {
+			if _, ok := keys[envVar.Key]; !ok {
+				missing = append(missing, envVar)
+				missingKeys = append(missingKeys, envVar.Key)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		res = append(
+			res, problem{
+				err:     ErrEnvVariableIsMissing,
+				details: fmt.Sprintf("module %s, variables %s", md.Name, strings.Join(missingKeys, ", ")),
+				fix: func() error {
+					return fsys.ApplyToCopy(
+						envFile, func(filename string) error {
+							return module.WriteEnvVariablesToFile(missing, filename)
+						},
+					)
+				},
+			},
+		)
+	}
+	return res, nil
+}
+
+// checkInstalledFiles compares the files downloaded during the modules installation
+// with the checksums saved to the lock file. The removed files are skipped.
+func (c *Doctor) checkInstalledFiles(projPath string) ([]problem, error) {
+	lock, err := manifesto.LoadLock(projPath)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]problem, 0)
+	for _, locked := range lock.Modules {
+		for _, file := range locked.Files {
+			content, err := os.ReadFile(projPath + "/" + file.Path)
+			if err != nil {
+				continue
+			}
+			if utils.Sha256(content) == file.Sha256 {
+				continue
+			}
+			res = append(
+				res, problem{
+					err:       ErrInstalledFileIsModified,
+					details:   fmt.Sprintf("module %s, file %s", locked.Name, file.Path),
+					isWarning: true,
+				},
+			)
+		}
+	}
+	return res, nil
+}
+
+func warnings(problems []problem) int {
+	count := 0
+	for _, p := range problems {
+		if p.isWarning {
+			count++
+		}
+	}
+	return count
+}
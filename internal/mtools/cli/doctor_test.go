@@ -0,0 +1,318 @@
+package cli_test
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-modulus/mtools/internal/mtools/action"
+	cmdRoot "github.com/go-modulus/mtools/internal/mtools/cli"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+const doctorMain = `package main
+
+import (
+	"example.com/app/internal/blog"
+	"github.com/go-modulus/modulus/cli"
+	"github.com/go-modulus/modulus/db/pgx"
+	"github.com/go-modulus/modulus/module"
+	"go.uber.org/fx"
+)
+
+func main() {
+	modules := []*module.Module{
+		cli.NewModule(),
+		pgx.NewModule(),
+		blog.NewModule(),
+	}
+
+	fx.New(module.BuildFx(modules...), fx.Invoke(cli.Start)).Run()
+}
+`
+
+const cliModuleJson = `{
+      "name": "urfave cli",
+      "package": "github.com/go-modulus/modulus/cli"
+    }`
+
+const pgxModuleJson = `{
+      "name": "pgx",
+      "package": "github.com/go-modulus/modulus/db/pgx",
+      "install": {
+        "envVars": [
+          {"key": "PGX_DSN", "value": "postgres://localhost:5432/app", "comment": "The DSN of the database"}
+        ]
+      }
+    }`
+
+const blogModuleJson = `{
+      "name": "blog",
+      "package": "example.com/app/internal/blog",
+      "localPath": "internal/blog",
+      "isLocalModule": true
+    }`
+
+const authModuleJson = `{
+      "name": "auth",
+      "package": "github.com/go-modulus/modulus/auth",
+      "localPath": "internal/auth"
+    }`
+
+const usersModuleJson = `{
+      "name": "users",
+      "package": "example.com/app/internal/users",
+      "localPath": "internal/users",
+      "isLocalModule": true
+    }`
+
+// initDoctorProject creates a healthy project with the console entrypoint and the given modules in modules.json
+func initDoctorProject(t *testing.T, modules ...string) string {
+	projDir := t.TempDir()
+	modulesJson := `{"schemaVersion": 1, "modules": [`
+	for i, md := range append([]string{cliModuleJson}, modules...) {
+		if i > 0 {
+			modulesJson += ","
+		}
+		modulesJson += md
+	}
+	modulesJson += `]}`
+
+	require.NoError(t, os.WriteFile(projDir+"/go.mod", []byte("module example.com/app\n"), 0644))
+	require.NoError(t, os.WriteFile(projDir+"/modules.json", []byte(modulesJson), 0644))
+	require.NoError(t, os.WriteFile(projDir+"/.env", []byte("APP_ENV=dev\nPGX_DSN=postgres://db:5432/app\n"), 0644))
+	require.NoError(t, os.MkdirAll(projDir+"/cmd/console", 0755))
+	require.NoError(t, os.WriteFile(projDir+"/cmd/console/main.go", []byte(doctorMain), 0644))
+	require.NoError(t, os.MkdirAll(projDir+"/internal/blog/storage", 0755))
+	require.NoError(t, os.WriteFile(projDir+"/internal/blog/module.go", []byte("package blog\n"), 0644))
+	return projDir
+}
+
+// initSqlcConfigs creates the sqlc files of the blog module. The sqlc.yaml file is created before the template if it is stale.
+func initSqlcConfigs(t *testing.T, projDir string, isStale bool) {
+	storagePath := projDir + "/internal/blog/storage"
+	require.NoError(t, os.WriteFile(projDir+"/sqlc.definition.yaml", []byte("definition:\n  engine: postgresql\n"), 0644))
+	require.NoError(t, os.WriteFile(storagePath+"/sqlc.tmpl.yaml", []byte("sqlc-tmpl:\n  version: \"2\"\n"), 0644))
+	require.NoError(t, os.WriteFile(storagePath+"/sqlc.yaml", []byte("version: \"1\"\n"), 0644))
+	if isStale {
+		past := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(storagePath+"/sqlc.yaml", past, past))
+	}
+}
+
+func invokeDoctor(projDir string, isFix bool) error {
+	app := cli.NewApp()
+	set := flag.NewFlagSet("test", 0)
+	set.String("proj-path", projDir, "")
+	set.Bool("fix", isFix, "")
+	ctx := cli.NewContext(app, set, nil)
+	return cmdRoot.NewDoctor(slog.Default(), action.NewUpdateSqlcConfig()).Invoke(ctx)
+}
+
+func TestDoctor_Invoke(t *testing.T) {
+	t.Run(
+		"check a healthy project", func(t *testing.T) {
+			projDir := initDoctorProject(t, pgxModuleJson, blogModuleJson)
+			initSqlcConfigs(t, projDir, false)
+
+			err := invokeDoctor(projDir, false)
+
+			t.Log("Given the project matching its modules.json file")
+			t.Log("When check the project")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+		},
+	)
+
+	t.Run(
+		"add the module missing in the entrypoint", func(t *testing.T) {
+			projDir := initDoctorProject(t, authModuleJson)
+			require.NoError(t, os.MkdirAll(projDir+"/internal/auth", 0755))
+			require.NoError(t, os.WriteFile(projDir+"/internal/auth/module.go", []byte("package auth\n"), 0644))
+
+			errCheck := invokeDoctor(projDir, false)
+			mainBeforeFix, err := os.ReadFile(projDir + "/cmd/console/main.go")
+			require.NoError(t, err)
+			errFix := invokeDoctor(projDir, true)
+			mainAfterFix, err := os.ReadFile(projDir + "/cmd/console/main.go")
+			require.NoError(t, err)
+			errRecheck := invokeDoctor(projDir, false)
+
+			t.Log("Given the auth module with the local files is installed but missing in the console entrypoint")
+			t.Log("When check the project")
+			t.Log("	The error should be ErrProjectHasProblems")
+			require.ErrorIs(t, errCheck, cmdRoot.ErrProjectHasProblems)
+			t.Log("	The entrypoint should not be changed")
+			require.Equal(t, doctorMain, string(mainBeforeFix))
+			t.Log("When check the project with the --fix flag")
+			t.Log("	The error should be nil")
+			require.NoError(t, errFix)
+			t.Log("	Both the module package and the project-local package should be added to the entrypoint")
+			require.Contains(t, string(mainAfterFix), `"github.com/go-modulus/modulus/auth"`)
+			require.Contains(t, string(mainAfterFix), `"example.com/app/internal/auth"`)
+			t.Log("	The next check should find no problems")
+			require.NoError(t, errRecheck)
+		},
+	)
+
+	t.Run(
+		"add the project-local package missing in the entrypoint", func(t *testing.T) {
+			projDir := initDoctorProject(t, authModuleJson)
+			require.NoError(t, os.MkdirAll(projDir+"/internal/auth", 0755))
+			require.NoError(t, os.WriteFile(projDir+"/internal/auth/module.go", []byte("package auth\n"), 0644))
+			mainContent := strings.NewReplacer(
+				"\t\"github.com/go-modulus/modulus/cli\"",
+				"\t\"github.com/go-modulus/modulus/auth\"\n\t\"github.com/go-modulus/modulus/cli\"",
+				"\t\tblog.NewModule(),",
+				"\t\tblog.NewModule(),\n\t\tauth.NewModule(),",
+			).Replace(doctorMain)
+			require.NoError(t, os.WriteFile(projDir+"/cmd/console/main.go", []byte(mainContent), 0644))
+
+			errCheck := invokeDoctor(projDir, false)
+			errFix := invokeDoctor(projDir, true)
+			mainAfterFix, err := os.ReadFile(projDir + "/cmd/console/main.go")
+			require.NoError(t, err)
+
+			t.Log("Given the auth module package is in the entrypoint but its project-local package is not")
+			t.Log("When check the project")
+			t.Log("	The error should be ErrProjectHasProblems")
+			require.ErrorIs(t, errCheck, cmdRoot.ErrProjectHasProblems)
+			t.Log("When check the project with the --fix flag")
+			t.Log("	The error should be nil")
+			require.NoError(t, errFix)
+			t.Log("	The project-local package should be added to the entrypoint")
+			require.Contains(t, string(mainAfterFix), `"example.com/app/internal/auth"`)
+		},
+	)
+
+	t.Run(
+		"keep the local module without the module.go file", func(t *testing.T) {
+			projDir := initDoctorProject(t, usersModuleJson, authModuleJson)
+			require.NoError(t, os.MkdirAll(projDir+"/internal/auth", 0755))
+			require.NoError(t, os.WriteFile(projDir+"/internal/auth/module.go", []byte("package auth\n"), 0644))
+
+			errFix := invokeDoctor(projDir, true)
+			mainAfterFix, err := os.ReadFile(projDir + "/cmd/console/main.go")
+			require.NoError(t, err)
+			_, errModuleFile := os.Stat(projDir + "/internal/users/module.go")
+
+			t.Log("Given the users local module without the module.go file and the auth module missing in the entrypoint")
+			t.Log("When check the project with the --fix flag")
+			t.Log("	The error should be ErrProjectHasProblems because the module.go file cannot be created")
+			require.ErrorIs(t, errFix, cmdRoot.ErrProjectHasProblems)
+			t.Log("	The module.go file should not be created")
+			require.True(t, os.IsNotExist(errModuleFile))
+			t.Log("	The users module should not be added to the entrypoint")
+			require.NotContains(t, string(mainAfterFix), "example.com/app/internal/users")
+			t.Log("	The other problems should be fixed")
+			require.Contains(t, string(mainAfterFix), `"github.com/go-modulus/modulus/auth"`)
+		},
+	)
+
+	t.Run(
+		"regenerate the stale sqlc config", func(t *testing.T) {
+			projDir := initDoctorProject(t, blogModuleJson)
+			initSqlcConfigs(t, projDir, true)
+
+			errCheck := invokeDoctor(projDir, false)
+			errFix := invokeDoctor(projDir, true)
+			config, err := os.ReadFile(projDir + "/internal/blog/storage/sqlc.yaml")
+			require.NoError(t, err)
+
+			t.Log("Given the storage/sqlc.yaml file of the blog module is older than storage/sqlc.tmpl.yaml")
+			t.Log("When check the project")
+			t.Log("	The error should be ErrProjectHasProblems")
+			require.ErrorIs(t, errCheck, cmdRoot.ErrProjectHasProblems)
+			t.Log("When check the project with the --fix flag")
+			t.Log("	The error should be nil")
+			require.NoError(t, errFix)
+			t.Log("	The sqlc.yaml file should be generated from the template")
+			require.Equal(t, "version: \"2\"\n", string(config))
+		},
+	)
+
+	t.Run(
+		"create the missing sqlc.definition.yaml file", func(t *testing.T) {
+			projDir := initDoctorProject(t, blogModuleJson)
+			initSqlcConfigs(t, projDir, false)
+			require.NoError(t, os.Remove(projDir+"/sqlc.definition.yaml"))
+
+			errCheck := invokeDoctor(projDir, false)
+			_, errDefinitionBeforeFix := os.Stat(projDir + "/sqlc.definition.yaml")
+			errFix := invokeDoctor(projDir, true)
+			definition, errDefinition := os.ReadFile(projDir + "/sqlc.definition.yaml")
+
+			t.Log("Given the blog module with the sqlc.tmpl.yaml file and the project without sqlc.definition.yaml")
+			t.Log("When check the project")
+			t.Log("	The error should be ErrProjectHasProblems")
+			require.ErrorIs(t, errCheck, cmdRoot.ErrProjectHasProblems)
+			t.Log("	The file should not be created")
+			require.True(t, os.IsNotExist(errDefinitionBeforeFix))
+			t.Log("When check the project with the --fix flag")
+			t.Log("	The error should be nil")
+			require.NoError(t, errFix)
+			t.Log("	The file should be created from the template")
+			require.NoError(t, errDefinition)
+			require.Contains(t, string(definition), "default-overrides")
+		},
+	)
+
+	t.Run(
+		"add the missing env variable", func(t *testing.T) {
+			projDir := initDoctorProject(t, pgxModuleJson)
+			require.NoError(t, os.WriteFile(projDir+"/.env", []byte("APP_ENV=dev\n"), 0644))
+
+			errCheck := invokeDoctor(projDir, false)
+			errFix := invokeDoctor(projDir, true)
+			env, err := os.ReadFile(projDir + "/.env")
+			require.NoError(t, err)
+
+			t.Log("Given the .env file without the PGX_DSN variable of the pgx module")
+			t.Log("When check the project")
+			t.Log("	The error should be ErrProjectHasProblems")
+			require.ErrorIs(t, errCheck, cmdRoot.ErrProjectHasProblems)
+			t.Log("When check the project with the --fix flag")
+			t.Log("	The error should be nil")
+			require.NoError(t, errFix)
+			t.Log("	The variable should be added with the default value and the other variables should be kept")
+			require.Contains(t, string(env), "APP_ENV=dev")
+			require.Contains(t, string(env), "PGX_DSN=postgres://localhost:5432/app")
+		},
+	)
+
+	t.Run(
+		"warn about the modified installed file", func(t *testing.T) {
+			projDir := initDoctorProject(t, pgxModuleJson)
+			require.NoError(t, os.WriteFile(projDir+"/config.yaml", []byte("changed: true\n"), 0644))
+			require.NoError(
+				t, os.WriteFile(
+					projDir+"/modules.lock.json", []byte(`{"modules": [{
+  "name": "pgx",
+  "package": "github.com/go-modulus/modulus/db/pgx",
+  "version": "v0.5.0",
+  "files": [{"path": "config.yaml", "sha256": "0000000000000000000000000000000000000000000000000000000000000000"}]
+}]}`), 0644,
+				),
+			)
+
+			errCheck := invokeDoctor(projDir, false)
+			errFix := invokeDoctor(projDir, true)
+			content, err := os.ReadFile(projDir + "/config.yaml")
+			require.NoError(t, err)
+
+			t.Log("Given the installed config.yaml file differs from the checksum in modules.lock.json")
+			t.Log("When check the project")
+			t.Log("	The error should be nil because the modification is a warning")
+			require.NoError(t, errCheck)
+			t.Log("When check the project with the --fix flag")
+			t.Log("	The error should be nil")
+			require.NoError(t, errFix)
+			t.Log("	The file should be kept")
+			require.Equal(t, "changed: true\n", string(content))
+		},
+	)
+}
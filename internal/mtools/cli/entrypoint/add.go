@@ -0,0 +1,429 @@
+package entrypoint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/files"
+	"github.com/go-modulus/mtools/internal/mtools/journal"
+	"github.com/go-modulus/mtools/internal/mtools/utils"
+	"github.com/manifoldco/promptui"
+	"github.com/urfave/cli/v2"
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+var ErrInvalidEntrypointName = errbuilder.New("invalid entrypoint name").
+	WithHint("The name should contain only lowercase letters, digits, dashes and underscores, e.g. api or queue-worker").Build()
+var ErrUnknownEntrypointKind = errbuilder.New("unknown entrypoint kind").
+	WithHint("Use one of the kinds: console, http, worker").Build()
+var ErrCannotAddModuleToEntrypoint = errbuilder.New("cannot add the module to the entrypoint").
+	WithHint("Add the entrypoint without the module and type the initialization code manually").Build()
+var ErrEntrypointExists = errbuilder.New("entrypoint already exists").
+	WithHint("Choose another name or remove the cmd/<name> folder").Build()
+
+// kind is a type of the binary the entrypoint builds
+type kind struct {
+	name  string
+	usage string
+	// template is the name of the main.go template in the add_entrypoint folder
+	template string
+	// modules are the packages of modules wired into the entrypoint of the kind by default if they are installed
+	modules []string
+}
+
+var kinds = []kind{
+	{
+		name:     "console",
+		usage:    "Run project commands",
+		template: "main.go.tmpl",
+	},
+	{
+		name:     "http",
+		usage:    "Run the HTTP server of the project",
+		template: "main_http.go.tmpl",
+		modules:  []string{"github.com/go-modulus/modulus/http"},
+	},
+	{
+		name:     "worker",
+		usage:    "Run the background workers of the project",
+		template: "main_worker.go.tmpl",
+	},
+}
+
+// templatePackages are the packages of modules initialized in the main.go template
+var templatePackages = []string{"github.com/go-modulus/modulus/cli"}
+
+type AddTmplVars struct {
+	Name  string
+	Kind  string
+	Usage string
+}
+
+type Add struct {
+}
+
+func NewAdd() *Add {
+	return &Add{}
+}
+
+func NewAddCommand(add *Add) *cli.Command {
+	return &cli.Command{
+		Name: "add",
+		Usage: `Add a new entrypoint cmd/<name>/main.go building a separate binary of the project.
+Registers the entrypoint with its modules in modules.json, wires the chosen installed modules into it and adds the build-<name> make target.
+The http binary runs the serve command and the worker binary runs the worker command if no command is passed.
+Example: mtools entrypoint add
+Example without UI: mtools entrypoint add --name=api --kind=http --modules="pgx,chi" --silent
+`,
+		Action: add.Invoke,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "name",
+				Usage:   "The name of the entrypoint. It is used as a folder name inside the cmd folder and as a binary name",
+				Aliases: []string{"n"},
+			},
+			&cli.StringFlag{
+				Name:    "kind",
+				Usage:   "The kind of the entrypoint. Available values: console, http, worker",
+				Aliases: []string{"k"},
+			},
+			&cli.StringSliceFlag{
+				Name:    "modules",
+				Usage:   "A comma-separated list of the installed modules names to wire into the entrypoint",
+				Aliases: []string{"m"},
+			},
+			flag.NewSilent("Do not ask for any input"),
+		},
+	}
+}
+
+func (a *Add) Invoke(ctx *cli.Context) error {
+	isSilent := flag.SilentValue(ctx)
+	projPath := flag.ProjPathValue(ctx)
+
+	name, err := a.getName(ctx, isSilent)
+	if err != nil {
+		printError("Cannot get the entrypoint name", err)
+		return err
+	}
+	entryKind, err := a.getKind(ctx, isSilent)
+	if err != nil {
+		printError("Cannot get the entrypoint kind", err)
+		return err
+	}
+
+	entry := manifesto.Entrypoint{
+		Name:      name,
+		LocalPath: "cmd/" + name + "/main.go",
+	}
+	if utils.FileExists(projPath + "/" + entry.LocalPath) {
+		printError("Cannot add the entrypoint "+entry.LocalPath, ErrEntrypointExists)
+		return ErrEntrypointExists
+	}
+
+	manifestLock, err := manifesto.LockLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot lock the modules.json file: %s", err.Error()))
+		return err
+	}
+	defer manifestLock.Unlock()
+
+	manifest, err := manifesto.LoadLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the local modules.json manifest file: %s", err.Error()))
+		return err
+	}
+	entries, err := manifesto.ReadEntries(projPath)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Println(color.RedString("Cannot get the entrypoints: %s", err.Error()))
+		return err
+	}
+	modules, err := a.getModules(ctx, isSilent, manifest, entryKind)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot choose the modules to wire into the entrypoint: %s", err.Error()))
+		return err
+	}
+
+	fmt.Println(
+		color.GreenString("Adding the %s entrypoint", entryKind.name),
+		color.BlueString(entry.LocalPath),
+	)
+
+	jrnl, err := journal.Begin(projPath, "entrypoint add "+name)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot start the journal: %s", err.Error()))
+		return err
+	}
+	makeFile := "mk/cmd-" + name + ".mk"
+	for _, p := range []string{entry.LocalPath, makeFile, "modules.json"} {
+		err = jrnl.Snapshot(p)
+		if err != nil {
+			fmt.Println(color.RedString("Cannot save the project files to the journal: %s", err.Error()))
+			rollback(jrnl)
+			return err
+		}
+	}
+
+	err = a.createEntrypoint(projPath, entry, entryKind, modules, manifest, entries, makeFile)
+	if err != nil {
+		rollback(jrnl)
+		return err
+	}
+
+	err = jrnl.Commit()
+	if err != nil {
+		fmt.Println(color.YellowString("Cannot save the journal, the command cannot be undone: %s", err.Error()))
+	}
+	fmt.Println(
+		color.GreenString("Congratulations! The entrypoint is added."),
+		"Build it with the command:",
+		color.CyanString("make build-%s", name),
+	)
+	return nil
+}
+
+func (a *Add) createEntrypoint(
+	projPath string,
+	entry manifesto.Entrypoint,
+	entryKind kind,
+	modules []module.Manifesto,
+	manifest *manifesto.LocalManifesto,
+	entries []manifesto.Entrypoint,
+	makeFile string,
+) error {
+	entryPath := projPath + "/" + entry.LocalPath
+	tmplVars := AddTmplVars{
+		Name:  entry.Name,
+		Kind:  entryKind.name,
+		Usage: entryKind.usage,
+	}
+
+	err := utils.CreateDirIfNotExists(projPath + "/cmd/" + entry.Name)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot create the entrypoint directory: %s", err.Error()))
+		return err
+	}
+	err = utils.ProcessTemplate(entryKind.template, "add_entrypoint/"+entryKind.template, entryPath, tmplVars)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot create the %s file: %s", entry.LocalPath, err.Error()))
+		return err
+	}
+
+	projPackage, projPackageErr := utils.ProjectPackage(projPath)
+	for _, md := range modules {
+		pckgs := []string{md.Package}
+		if md.LocalPath != "" && !md.IsLocalModule && utils.FileExists(md.ModulePath(projPath)+"/module.go") {
+			if projPackageErr != nil {
+				printError("Cannot get the project package to add the local files of the module "+md.Name, projPackageErr)
+				return projPackageErr
+			}
+			pckgs = append(pckgs, projPackage+"/"+md.LocalPath)
+		}
+		fmt.Printf("Adding module initialization of %s ...\n", color.BlueString(md.Name))
+		for _, pckg := range pckgs {
+			err = files.AddModuleToEntrypoint(pckg, entryPath)
+			if err != nil {
+				err = errors.WithCause(ErrCannotAddModuleToEntrypoint, fmt.Errorf("%s: %w", md.Name, err))
+				printError("Cannot add the module to the entrypoint "+entry.LocalPath, err)
+				return err
+			}
+		}
+	}
+
+	err = utils.CreateDirIfNotExists(projPath + "/mk")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot create the mk directory: %s", err.Error()))
+		return err
+	}
+	err = utils.ProcessTemplate("build.mk.tmpl", "add_entrypoint/build.mk.tmpl", projPath+"/"+makeFile, tmplVars)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot create the %s file: %s", makeFile, err.Error()))
+		return err
+	}
+
+	pckgs := slices.Clone(templatePackages)
+	for _, md := range modules {
+		pckgs = append(pckgs, md.Package)
+	}
+	manifest.AddEntry(entry, pckgs, manifesto.EntryNames(entries))
+	err = manifest.SaveAsLocalManifest(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot save the local manifest file modules.json: %s", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (a *Add) getName(ctx *cli.Context, isSilent bool) (string, error) {
+	name := ctx.String("name")
+	if name == "" && !isSilent {
+		prompt := promptui.Prompt{
+			Label: "Enter a name of the entrypoint (e.g. api): ",
+			Validate: func(s string) error {
+				if !nameRegexp.MatchString(s) {
+					return ErrInvalidEntrypointName
+				}
+				return nil
+			},
+		}
+		return prompt.Run()
+	}
+	if !nameRegexp.MatchString(name) {
+		return "", ErrInvalidEntrypointName
+	}
+	return name, nil
+}
+
+func (a *Add) getKind(ctx *cli.Context, isSilent bool) (kind, error) {
+	name := ctx.String("kind")
+	if name == "" && isSilent {
+		name = kinds[0].name
+	}
+	if name == "" {
+		sel := promptui.Select{
+			Label: "Choose a kind of the entrypoint",
+			Items: kindNames(),
+		}
+		_, res, err := sel.Run()
+		if err != nil {
+			return kind{}, err
+		}
+		name = res
+	}
+	for _, k := range kinds {
+		if k.name == name {
+			return k, nil
+		}
+	}
+	return kind{}, ErrUnknownEntrypointKind
+}
+
+// getModules returns the installed modules to wire into the entrypoint.
+// The modules are taken from the --modules flag, the default modules of the kind are wired in the silent mode,
+// otherwise the user chooses them from the installed ones.
+func (a *Add) getModules(
+	ctx *cli.Context,
+	isSilent bool,
+	manifest *manifesto.LocalManifesto,
+	entryKind kind,
+) ([]module.Manifesto, error) {
+	res := make([]module.Manifesto, 0)
+	names := ctx.StringSlice("modules")
+	if len(names) != 0 {
+		for _, name := range names {
+			md, ok := manifest.FindModule(strings.TrimSpace(name))
+			if !ok {
+				fmt.Println(color.YellowString("The module %s is not installed. Skipping...", name))
+				continue
+			}
+			res = append(res, md)
+		}
+		return res, nil
+	}
+
+	for _, md := range manifest.Modules {
+		if slices.Contains(entryKind.modules, md.Package) {
+			res = append(res, md)
+		}
+	}
+	if isSilent || len(manifest.Modules) == 0 {
+		return res, nil
+	}
+	return askModules(manifest.Modules, res)
+}
+
+// askModules asks the user to toggle the modules in the list of installed ones. The selected modules are checked initially.
+func askModules(installed []module.Manifesto, selected []module.Manifesto) ([]module.Manifesto, error) {
+	type selectItem struct {
+		Name        string
+		Package     string
+		IsSelected  bool
+		Description string
+	}
+
+	templates := &promptui.SelectTemplates{
+		Label:    "{{ . }}",
+		Active:   "→ {{if .IsSelected}}\U0001F4E6 {{end}} {{ .Name | cyan }}",
+		Inactive: "{{if .IsSelected}}\U0001F4E6 {{end}} {{ .Name | white | faint }}",
+		Details: `{{ if .Package }}
+{{ "Package:" | faint }}	{{ .Package }}
+{{ "Description:" | faint }}	{{ .Description }}{{ end }}`,
+	}
+
+	items := make([]selectItem, len(installed)+1)
+	items[0] = selectItem{Name: "Wire chosen"}
+	for i, md := range installed {
+		items[i+1] = selectItem{
+			Name:        md.Name,
+			Package:     md.Package,
+			Description: md.Description,
+			IsSelected: slices.ContainsFunc(
+				selected, func(s module.Manifesto) bool {
+					return s.Package == md.Package
+				},
+			),
+		}
+	}
+
+	selectedPos := 0
+	for {
+		sel := promptui.Select{
+			Label:        "Please choose the modules to wire into the entrypoint:",
+			Items:        items,
+			Templates:    templates,
+			Size:         15,
+			CursorPos:    selectedPos,
+			HideSelected: true,
+		}
+		index, _, err := sel.Run()
+		if err != nil {
+			return nil, err
+		}
+		if index == 0 {
+			break
+		}
+		items[index].IsSelected = !items[index].IsSelected
+		selectedPos = index
+	}
+
+	res := make([]module.Manifesto, 0)
+	for i, item := range items[1:] {
+		if item.IsSelected {
+			res = append(res, installed[i])
+		}
+	}
+	return res, nil
+}
+
+func kindNames() []string {
+	res := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		res = append(res, k.name)
+	}
+	return res
+}
+
+func rollback(jrnl *journal.Journal) {
+	fmt.Println(color.YellowString("Rolling back the changes..."))
+	err := jrnl.Rollback()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot roll back the changes: %s", err.Error()))
+	}
+}
+
+func printError(message string, err error) {
+	fmt.Println(color.RedString("%s: %s", message, err.Error()))
+	if errors.Hint(err) != "" {
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
+	}
+}
@@ -0,0 +1,187 @@
+package entrypoint_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/entrypoint"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+const modulesJson = `{
+  "entries": [
+    {
+      "name": "console",
+      "localPath": "cmd/console/main.go"
+    }
+  ],
+  "modules": [
+    {
+      "name": "urfave cli",
+      "package": "github.com/go-modulus/modulus/cli"
+    },
+    {
+      "name": "pgx",
+      "package": "github.com/go-modulus/modulus/db/pgx"
+    }
+  ]
+}`
+
+func initProject(t *testing.T) string {
+	projDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(projDir+"/cmd/console", 0755))
+	require.NoError(t, os.WriteFile(projDir+"/cmd/console/main.go", []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(projDir+"/modules.json", []byte(modulesJson), 0644))
+	return projDir
+}
+
+const localFilesModulesJson = `{
+  "entries": [
+    {
+      "name": "console",
+      "localPath": "cmd/console/main.go"
+    }
+  ],
+  "modules": [
+    {
+      "name": "auth",
+      "package": "github.com/go-modulus/modulus/auth",
+      "localPath": "internal/auth"
+    }
+  ]
+}`
+
+// initLocalFilesProject creates a project with the auth module installed with its local files
+func initLocalFilesProject(t *testing.T) string {
+	projDir := initProject(t)
+	require.NoError(t, os.WriteFile(projDir+"/modules.json", []byte(localFilesModulesJson), 0644))
+	require.NoError(t, os.MkdirAll(projDir+"/internal/auth", 0755))
+	require.NoError(t, os.WriteFile(projDir+"/internal/auth/module.go", []byte("package auth\n"), 0644))
+	return projDir
+}
+
+func invokeAdd(projDir string, name string, kind string, modules string) error {
+	app := cli.NewApp()
+	set := flag.NewFlagSet("test", 0)
+	set.String("name", name, "")
+	set.String("kind", kind, "")
+	modulesFlag := cli.NewStringSlice()
+	_ = modulesFlag.Set(modules)
+	set.Var(modulesFlag, "modules", "")
+	set.String("proj-path", projDir, "")
+	set.Bool("silent", true, "")
+	ctx := cli.NewContext(app, set, nil)
+	return entrypoint.NewAdd().Invoke(ctx)
+}
+
+func TestAdd_Invoke(t *testing.T) {
+	t.Run(
+		"add an http entrypoint", func(t *testing.T) {
+			projDir := initProject(t)
+
+			err := invokeAdd(projDir, "api", "http", "pgx")
+			mainContent, errMain := os.ReadFile(projDir + "/cmd/api/main.go")
+			makeContent, errMake := os.ReadFile(projDir + "/mk/cmd-api.mk")
+			manifest, errManifest := manifesto.LoadLocalManifesto(projDir)
+
+			t.Log("When add a new http entrypoint with the pgx module")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The main.go file should be created with the pgx module initialization")
+			require.NoError(t, errMain)
+			require.Contains(t, string(mainContent), `"github.com/go-modulus/modulus/db/pgx"`)
+			require.Contains(t, string(mainContent), "pgx.NewModule(),")
+			require.Contains(t, string(mainContent), "Run the HTTP server of the project")
+			t.Log("	The binary should run the serve command by default")
+			require.Contains(t, string(mainContent), `os.Args = append(os.Args, "serve")`)
+			t.Log("	The make target should build the binary")
+			require.NoError(t, errMake)
+			require.Contains(t, string(makeContent), "go build -o ./bin/api ./cmd/api/main.go")
+			t.Log("	The entrypoint should be registered in modules.json")
+			require.NoError(t, errManifest)
+			require.Equal(
+				t, []manifesto.Entrypoint{
+					{Name: "console", LocalPath: "cmd/console/main.go"},
+					{Name: "api", LocalPath: "cmd/api/main.go"},
+				}, manifest.Entries,
+			)
+		},
+	)
+
+	t.Run(
+		"add an entrypoint with the installed module having local files", func(t *testing.T) {
+			projDir := initLocalFilesProject(t)
+			require.NoError(t, os.WriteFile(projDir+"/go.mod", []byte("module example.com/app\n"), 0644))
+
+			err := invokeAdd(projDir, "api", "http", "auth")
+			mainContent, errMain := os.ReadFile(projDir + "/cmd/api/main.go")
+
+			t.Log("Given the auth module is installed with the local files in internal/auth")
+			t.Log("When add a new entrypoint with the auth module")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The main.go file should initialize both the module package and the project-local package")
+			require.NoError(t, errMain)
+			require.Contains(t, string(mainContent), `"github.com/go-modulus/modulus/auth"`)
+			require.Contains(t, string(mainContent), `"example.com/app/internal/auth"`)
+		},
+	)
+
+	t.Run(
+		"roll back the entrypoint if the module cannot be added", func(t *testing.T) {
+			projDir := initLocalFilesProject(t)
+
+			err := invokeAdd(projDir, "api", "http", "auth")
+			_, errMain := os.Stat(projDir + "/cmd/api/main.go")
+			_, errMake := os.Stat(projDir + "/mk/cmd-api.mk")
+			manifest, errManifest := manifesto.LoadLocalManifesto(projDir)
+
+			t.Log("Given the auth module is installed with the local files and the project has no go.mod file")
+			t.Log("When add a new entrypoint with the auth module")
+			t.Log("	The error should be returned")
+			require.Error(t, err)
+			t.Log("	The main.go and make files should be removed")
+			require.True(t, os.IsNotExist(errMain))
+			require.True(t, os.IsNotExist(errMake))
+			t.Log("	The entrypoint should not be registered in modules.json")
+			require.NoError(t, errManifest)
+			require.Equal(t, []manifesto.Entrypoint{{Name: "console", LocalPath: "cmd/console/main.go"}}, manifest.Entries)
+		},
+	)
+
+	t.Run(
+		"add an existing entrypoint", func(t *testing.T) {
+			projDir := initProject(t)
+			require.NoError(t, invokeAdd(projDir, "worker", "worker", "pgx"))
+			mainContent, errMain := os.ReadFile(projDir + "/cmd/worker/main.go")
+
+			err := invokeAdd(projDir, "worker", "console", "pgx")
+
+			t.Log("Given the worker entrypoint is added")
+			t.Log("	The binary should run the worker command by default")
+			require.NoError(t, errMain)
+			require.Contains(t, string(mainContent), `os.Args = append(os.Args, "worker")`)
+			t.Log("When add the worker entrypoint again")
+			t.Log("	The error should be returned")
+			require.ErrorIs(t, err, entrypoint.ErrEntrypointExists)
+		},
+	)
+
+	t.Run(
+		"add an entrypoint of unknown kind", func(t *testing.T) {
+			projDir := initProject(t)
+
+			err := invokeAdd(projDir, "api", "grpc", "")
+			_, errMain := os.Stat(projDir + "/cmd/api/main.go")
+
+			t.Log("When add an entrypoint of unknown kind")
+			t.Log("	The error should be returned")
+			require.ErrorIs(t, err, entrypoint.ErrUnknownEntrypointKind)
+			t.Log("	The main.go file should not be created")
+			require.True(t, os.IsNotExist(errMain))
+		},
+	)
+}
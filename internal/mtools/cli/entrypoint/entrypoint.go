@@ -0,0 +1,24 @@
+package entrypoint
+
+import (
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/urfave/cli/v2"
+)
+
+func NewEntrypointCommand(
+	add *Add,
+	sync *Sync,
+) *cli.Command {
+	return &cli.Command{
+		Name: "entrypoint",
+		Usage: `A set of commands for the entrypoints of the project, the cmd/<name>/main.go files building the binaries.
+Example: mtools entrypoint
+`,
+		Before: flag.DryRunBefore,
+		After:  flag.DryRunAfter,
+		Subcommands: []*cli.Command{
+			NewAddCommand(add),
+			NewSyncCommand(sync),
+		},
+	}
+}
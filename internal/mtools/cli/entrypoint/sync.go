@@ -0,0 +1,167 @@
+package entrypoint
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/files"
+	"github.com/go-modulus/mtools/internal/mtools/journal"
+	"github.com/go-modulus/mtools/internal/mtools/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrCannotSyncModule = errbuilder.New("cannot sync the module in the entrypoint").
+	WithHint("Change the initialization code of the entrypoint manually and run the command again").Build()
+
+type Sync struct {
+}
+
+func NewSync() *Sync {
+	return &Sync{}
+}
+
+func NewSyncCommand(sync *Sync) *cli.Command {
+	return &cli.Command{
+		Name: "sync",
+		Usage: `Make the modules slice of each entrypoint match the modules recorded for it in the modules.json file.
+The entrypoints are taken from the entries section of the modules.json file.
+Adds the initialization of the missing modules and removes the modules wired into other entrypoints only.
+The modules not listed in the modules.json file are kept untouched.
+Example: mtools entrypoint sync
+Example for the chosen entrypoints: mtools entrypoint sync --entries=api,worker
+`,
+		Action: sync.Invoke,
+		Flags: []cli.Flag{
+			flag.NewEntries("A comma-separated list of the entrypoints names to sync. All entrypoints are synced by default"),
+		},
+	}
+}
+
+func (s *Sync) Invoke(ctx *cli.Context) error {
+	projPath := flag.ProjPathValue(ctx)
+
+	manifest, err := manifesto.LoadLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the local modules.json manifest file: %s", err.Error()))
+		return err
+	}
+	entries := manifest.Entries
+	names, err := flag.EntriesValue(ctx, manifesto.EntryNames(entries), false)
+	if err != nil {
+		return err
+	}
+
+	jrnl, err := journal.Begin(projPath, "entrypoint sync")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot start the journal: %s", err.Error()))
+		return err
+	}
+	changed := 0
+	for _, entry := range entries {
+		if !slices.Contains(names, entry.Name) {
+			continue
+		}
+		err = jrnl.Snapshot(entry.LocalPath)
+		if err != nil {
+			fmt.Println(color.RedString("Cannot save the entrypoint to the journal: %s", err.Error()))
+			rollback(jrnl)
+			return err
+		}
+		n, err := s.syncEntry(manifest, entries, entry, projPath)
+		if err != nil {
+			fmt.Println(color.RedString("Cannot sync the entrypoint %s: %s", entry.LocalPath, err.Error()))
+			if errors.Hint(err) != "" {
+				fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
+			}
+			rollback(jrnl)
+			return err
+		}
+		changed += n
+	}
+
+	err = jrnl.Commit()
+	if err != nil {
+		fmt.Println(color.YellowString("Cannot save the journal, the command cannot be undone: %s", err.Error()))
+	}
+	if changed == 0 {
+		fmt.Println(color.GreenString("All entrypoints are in sync with the modules.json file."))
+		return nil
+	}
+	fmt.Println(color.GreenString("The entrypoints are synced, %d modules are added or removed.", changed))
+	return nil
+}
+
+// syncEntry adds the modules wired into the entrypoint and removes the other ones.
+// Returns the number of added and removed modules.
+func (s *Sync) syncEntry(
+	manifest *manifesto.LocalManifesto,
+	entries []manifesto.Entrypoint,
+	entry manifesto.Entrypoint,
+	projPath string,
+) (int, error) {
+	entryFile := projPath + "/" + entry.LocalPath
+	allEntries := manifesto.EntryNames(entries)
+	projPackage, projPackageErr := utils.ProjectPackage(projPath)
+
+	changed := 0
+	for _, md := range manifest.Modules {
+		if md.IsLocalModule && !utils.FileExists(md.ModulePath(projPath)+"/module.go") {
+			continue
+		}
+		pckgs := []string{md.Package}
+		if md.LocalPath != "" && !md.IsLocalModule && utils.FileExists(md.ModulePath(projPath)+"/module.go") {
+			if projPackageErr != nil {
+				fmt.Println(
+					color.YellowString(
+						"Cannot get the project package to sync the local files of the module %s: %s",
+						md.Name,
+						projPackageErr.Error(),
+					),
+				)
+			} else {
+				pckgs = append(pckgs, projPackage+"/"+md.LocalPath)
+			}
+		}
+
+		wired := slices.Contains(manifest.EntriesOf(md.Package, allEntries), entry.Name)
+		for _, pckg := range pckgs {
+			ok, err := s.syncModule(md, pckg, entryFile, wired)
+			if err != nil {
+				return changed, err
+			}
+			if ok {
+				changed++
+			}
+		}
+	}
+	return changed, nil
+}
+
+// syncModule adds the module package to the entrypoint if it is wired into the entrypoint or removes it otherwise.
+// Returns true if the entrypoint is changed.
+func (s *Sync) syncModule(md module.Manifesto, pckg string, entryFile string, wired bool) (bool, error) {
+	found, err := files.IsModuleInEntrypoint(pckg, entryFile)
+	if err != nil {
+		return false, err
+	}
+	if found == wired {
+		return false, nil
+	}
+	if wired {
+		fmt.Printf("Adding the module %s to the entrypoint %s\n", color.BlueString(md.Name), color.BlueString(entryFile))
+		err = files.AddModuleToEntrypoint(pckg, entryFile)
+	} else {
+		fmt.Printf("Removing the module %s from the entrypoint %s\n", color.BlueString(md.Name), color.BlueString(entryFile))
+		err = files.RemoveModuleFromEntrypoint(pckg, entryFile)
+	}
+	if err != nil {
+		return false, errors.WithCause(ErrCannotSyncModule, fmt.Errorf("%s: %w", md.Name, err))
+	}
+	return true, nil
+}
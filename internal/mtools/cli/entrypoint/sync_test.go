@@ -0,0 +1,118 @@
+package entrypoint_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/cli/entrypoint"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+const syncedMain = `package main
+
+import (
+	"github.com/go-modulus/modulus/cli"
+	"github.com/go-modulus/modulus/db/pgx"
+	"github.com/go-modulus/modulus/module"
+	"go.uber.org/fx"
+)
+
+func main() {
+	modules := []*module.Module{
+		cli.NewModule(),
+		pgx.NewModule(),
+	}
+
+	fx.New(module.BuildFx(modules...), fx.Invoke(cli.Start)).Run()
+}
+`
+
+const syncedModulesJson = `{
+  "entries": [
+    {"name": "console", "localPath": "cmd/console/main.go"},
+    {"name": "api", "localPath": "cmd/api/main.go"}
+  ],
+  "modules": [
+    {
+      "name": "urfave cli",
+      "package": "github.com/go-modulus/modulus/cli"
+    },
+    {
+      "name": "pgx",
+      "package": "github.com/go-modulus/modulus/db/pgx"
+    },
+    {
+      "name": "chi",
+      "package": "github.com/go-modulus/modulus/http"
+    }
+  ],
+  "moduleEntries": {
+    "github.com/go-modulus/modulus/db/pgx": ["api"],
+    "github.com/go-modulus/modulus/http": ["api"]
+  }
+}`
+
+func TestSync_Invoke(t *testing.T) {
+	t.Run(
+		"sync the entrypoints with the recorded modules", func(t *testing.T) {
+			projDir := t.TempDir()
+			for _, name := range []string{"api", "console"} {
+				require.NoError(t, os.MkdirAll(projDir+"/cmd/"+name, 0755))
+				require.NoError(t, os.WriteFile(projDir+"/cmd/"+name+"/main.go", []byte(syncedMain), 0644))
+			}
+			require.NoError(t, os.WriteFile(projDir+"/modules.json", []byte(syncedModulesJson), 0644))
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.String("proj-path", projDir, "")
+			ctx := cli.NewContext(app, set, nil)
+			err := entrypoint.NewSync().Invoke(ctx)
+
+			apiContent, errApi := os.ReadFile(projDir + "/cmd/api/main.go")
+			consoleContent, errConsole := os.ReadFile(projDir + "/cmd/console/main.go")
+
+			t.Log("Given the pgx and chi modules are recorded only for the api entrypoint")
+			t.Log("When sync the entrypoints")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			require.NoError(t, errApi)
+			require.NoError(t, errConsole)
+			t.Log("	The chi module should be added to the api entrypoint")
+			require.Contains(t, string(apiContent), "http.NewModule(),")
+			require.Contains(t, string(apiContent), "pgx.NewModule(),")
+			t.Log("	The pgx module should be removed from the console entrypoint with its import")
+			require.NotContains(t, string(consoleContent), "pgx")
+			require.NotContains(t, string(consoleContent), "http.NewModule()")
+			t.Log("	The cli module without the record should be kept in both entrypoints")
+			require.Contains(t, string(apiContent), "cli.NewModule(),")
+			require.Contains(t, string(consoleContent), "cli.NewModule(),")
+		},
+	)
+	t.Run(
+		"fail if a module cannot be synced", func(t *testing.T) {
+			projDir := t.TempDir()
+			require.NoError(t, os.MkdirAll(projDir+"/cmd/api", 0755))
+			require.NoError(t, os.MkdirAll(projDir+"/cmd/console", 0755))
+			require.NoError(t, os.WriteFile(projDir+"/cmd/api/main.go", []byte("package main\n\nfunc main() {}\n"), 0644))
+			require.NoError(t, os.WriteFile(projDir+"/cmd/console/main.go", []byte(syncedMain), 0644))
+			require.NoError(t, os.WriteFile(projDir+"/modules.json", []byte(syncedModulesJson), 0644))
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.String("proj-path", projDir, "")
+			err := entrypoint.NewSync().Invoke(cli.NewContext(app, set, nil))
+
+			consoleContent, errConsole := os.ReadFile(projDir + "/cmd/console/main.go")
+
+			t.Log("Given the api entrypoint without the modules slice")
+			t.Log("When sync the entrypoints")
+			t.Log("	The error should be ErrCannotSyncModule")
+			require.ErrorIs(t, err, entrypoint.ErrCannotSyncModule)
+			t.Log("	The changes of the other entrypoints should be rolled back")
+			require.NoError(t, errConsole)
+			require.Equal(t, syncedMain, string(consoleContent))
+		},
+	)
+}
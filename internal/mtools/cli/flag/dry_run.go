@@ -0,0 +1,35 @@
+package flag
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/urfave/cli/v2"
+)
+
+// DryRunValue returns the value of the global --dry-run flag.
+// In the dry run mode the project files are not changed, the diff of the changes is printed instead.
+func DryRunValue(ctx *cli.Context) bool {
+	return ctx.Bool("dry-run")
+}
+
+// DryRunBefore is a Before hook of the commands changing the project files.
+// It switches the writes to the memory if the --dry-run flag is set.
+func DryRunBefore(ctx *cli.Context) error {
+	if !DryRunValue(ctx) || fsys.IsDryRun() {
+		return nil
+	}
+	fsys.EnableDryRun()
+	_, _ = fmt.Fprintln(MessagesWriter(ctx), color.YellowString("Dry run: the project files will not be changed."))
+	return nil
+}
+
+// DryRunAfter is an After hook of the commands changing the project files.
+// It prints the diff of all files that would be changed by the command.
+func DryRunAfter(ctx *cli.Context) error {
+	if !fsys.IsDryRun() {
+		return nil
+	}
+	return fsys.PrintDiff(MessagesWriter(ctx))
+}
@@ -0,0 +1,99 @@
+package flag
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrEntrypointNotFound = errors.New("entrypoint is not found")
+
+func NewEntries(usage string) cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:    "entries",
+		Usage:   usage,
+		Aliases: []string{"e"},
+	}
+}
+
+// EntriesValue returns the names of the entrypoints chosen with the --entries flag.
+// If the flag is not set, the user chooses the entrypoints from the list when askUser is true,
+// otherwise all entrypoints are returned.
+func EntriesValue(ctx *cli.Context, entries []string, askUser bool) ([]string, error) {
+	values := ctx.StringSlice("entries")
+	if len(values) == 0 {
+		if !askUser || len(entries) < 2 {
+			return entries, nil
+		}
+		return askEntries(entries)
+	}
+
+	res := make([]string, 0, len(values))
+	for _, val := range values {
+		val = strings.TrimSpace(val)
+		if !slices.Contains(entries, val) {
+			fmt.Println(
+				color.RedString("Entrypoint"),
+				color.BlueString(val),
+				color.RedString("is not found. Available entrypoints: %s", strings.Join(entries, ", ")),
+			)
+			return nil, ErrEntrypointNotFound
+		}
+		if !slices.Contains(res, val) {
+			res = append(res, val)
+		}
+	}
+	return res, nil
+}
+
+// askEntries asks the user to toggle the entrypoints in the list. All entrypoints are checked initially.
+func askEntries(entries []string) ([]string, error) {
+	type selectItem struct {
+		Name       string
+		IsSelected bool
+	}
+
+	templates := &promptui.SelectTemplates{
+		Label:    "{{ . }}",
+		Active:   "→ {{if .IsSelected}}✅ {{end}} {{ .Name | cyan }}",
+		Inactive: "{{if .IsSelected}}✅ {{end}} {{ .Name | white | faint }}",
+	}
+	items := make([]selectItem, len(entries)+1)
+	items[0] = selectItem{Name: "Use chosen"}
+	for i, entry := range entries {
+		items[i+1] = selectItem{Name: entry, IsSelected: true}
+	}
+
+	selectedPos := 0
+	for {
+		sel := promptui.Select{
+			Label:        "Please choose the entrypoints to wire the modules into:",
+			Items:        items,
+			Templates:    templates,
+			CursorPos:    selectedPos,
+			HideSelected: true,
+		}
+		index, _, err := sel.Run()
+		if err != nil {
+			return nil, err
+		}
+		if index == 0 {
+			break
+		}
+		items[index].IsSelected = !items[index].IsSelected
+		selectedPos = index
+	}
+
+	res := make([]string, 0, len(entries))
+	for _, item := range items[1:] {
+		if item.IsSelected {
+			res = append(res, item.Name)
+		}
+	}
+	return res, nil
+}
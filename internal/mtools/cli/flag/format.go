@@ -0,0 +1,82 @@
+package flag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	FormatTable = "table"
+	FormatJson  = "json"
+	FormatYaml  = "yaml"
+)
+
+func NewFormat(usage string) cli.Flag {
+	return &cli.StringFlag{
+		Name:    "format",
+		Usage:   usage,
+		Aliases: []string{"f"},
+		Value:   FormatTable,
+	}
+}
+
+func FormatValue(ctx *cli.Context) (string, error) {
+	format := ctx.String("format")
+	if format == "" {
+		return FormatTable, nil
+	}
+	if !slices.Contains([]string{FormatTable, FormatJson, FormatYaml}, format) {
+		return "", fmt.Errorf(
+			"unknown output format %s, use one of: %s, %s, %s",
+			format,
+			FormatTable,
+			FormatJson,
+			FormatYaml,
+		)
+	}
+	return format, nil
+}
+
+// MessagesWriter returns the writer of the messages accompanying the command output, e.g. warnings and banners.
+// The messages go to stderr if the output is json or yaml to keep the output parseable.
+func MessagesWriter(ctx *cli.Context) io.Writer {
+	if !isFormattedOutput(ctx) {
+		if ctx.App != nil && ctx.App.Writer != nil {
+			return ctx.App.Writer
+		}
+		return os.Stdout
+	}
+	if ctx.App != nil && ctx.App.ErrWriter != nil {
+		return ctx.App.ErrWriter
+	}
+	return os.Stderr
+}
+
+// isFormattedOutput checks that the --format flag is json or yaml.
+// The Before and After hooks of the command groups run before the flags of the subcommands are parsed,
+// so the not parsed arguments are checked as well.
+func isFormattedOutput(ctx *cli.Context) bool {
+	format := ctx.String("format")
+	if format == "" {
+		args := ctx.Args().Slice()
+		for i, arg := range args {
+			if !strings.HasPrefix(arg, "-") {
+				continue
+			}
+			name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			if name != "format" && name != "f" {
+				continue
+			}
+			if !hasValue && i+1 < len(args) {
+				value = args[i+1]
+			}
+			format = value
+		}
+	}
+	return format == FormatJson || format == FormatYaml
+}
@@ -0,0 +1,40 @@
+package flag_test
+
+import (
+	"bytes"
+	goflag "flag"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestMessagesWriter(t *testing.T) {
+	t.Run(
+		"write the messages to stderr for the formatted output", func(t *testing.T) {
+			app := cli.NewApp()
+			app.Writer = &bytes.Buffer{}
+			app.ErrWriter = &bytes.Buffer{}
+			commandSet := goflag.NewFlagSet("list", 0)
+			commandSet.String("format", flag.FormatTable, "doc")
+			require.NoError(t, commandSet.Set("format", flag.FormatJson))
+			tableSet := goflag.NewFlagSet("list", 0)
+			tableSet.String("format", flag.FormatTable, "doc")
+			groupSet := goflag.NewFlagSet("module", 0)
+			require.NoError(t, groupSet.Parse([]string{"list", "--format", "yaml"}))
+
+			command := flag.MessagesWriter(cli.NewContext(app, commandSet, nil))
+			table := flag.MessagesWriter(cli.NewContext(app, tableSet, nil))
+			group := flag.MessagesWriter(cli.NewContext(app, groupSet, nil))
+
+			t.Log("When get the writer of the messages")
+			t.Log("	The messages of the json output should go to stderr")
+			require.Same(t, app.ErrWriter, command)
+			t.Log("	The messages of the table output should go to stdout")
+			require.Same(t, app.Writer, table)
+			t.Log("	The messages of the command group should go to stderr if its subcommand formats the output")
+			require.Same(t, app.ErrWriter, group)
+		},
+	)
+}
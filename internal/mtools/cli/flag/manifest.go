@@ -1,76 +1,215 @@
 package flag
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cache"
 	"github.com/urfave/cli/v2"
 )
 
+const DefaultManifestUrl = "https://raw.githubusercontent.com/go-modulus/registry/refs/heads/main/modules.json"
+
+const defaultRegistryTimeout = 30 * time.Second
+
+var ErrRegistryCredentialsNotSet = errors.New("registry credentials are not set")
+var ErrUnknownRegistryAuthType = errors.New("unknown registry auth type")
+
 func NewManifest(usage string) cli.Flag {
 	return &cli.StringFlag{
-		Name:        "manifest",
-		Usage:       usage,
-		DefaultText: "https://raw.githubusercontent.com/go-modulus/registry/refs/heads/main/modules.json",
+		Name: "manifest",
+		Usage: usage + `
+If the flag is not set, the registries from the "registries" section of the project modules.json file are used.`,
+		DefaultText: DefaultManifestUrl,
 		Aliases:     []string{"mf"},
-		Value:       "https://raw.githubusercontent.com/go-modulus/registry/refs/heads/main/modules.json",
+		Value:       DefaultManifestUrl,
 	}
 }
 
+// ManifestValue returns the manifest with all available modules.
+// The manifest from the --manifest flag is used if the flag is set.
+// Otherwise, the registries of the project are fetched and merged in the order they are declared in modules.json.
+// The public registry is used if the project has no registries.
 func ManifestValue(ctx *cli.Context) (*manifesto.LocalManifesto, error) {
 	manifestPath := ctx.String("manifest")
-	if manifestPath == "" {
-		return manifestFromURL("https://raw.githubusercontent.com/go-modulus/registry/refs/heads/main/modules.json")
+	w := MessagesWriter(ctx)
+	offline := OfflineValue(ctx)
+	if ctx.IsSet("manifest") && manifestPath != "" {
+		return manifestFromRegistry(w, manifesto.Registry{Name: "manifest", Url: manifestPath}, offline)
 	}
 
-	if strings.HasPrefix(manifestPath, "https://") {
-		return manifestFromURL(manifestPath)
+	projPath := ProjPathValue(ctx)
+	if projPath == "" {
+		projPath = "."
 	}
-	if strings.HasPrefix(manifestPath, "http://") {
-		return manifestFromURL(manifestPath)
+	local := &manifesto.LocalManifesto{}
+	if _, err := os.Stat(projPath + "/modules.json"); err == nil {
+		local, err = manifesto.NewLocalFromFs(os.DirFS(projPath), "modules.json")
+		if err != nil {
+			_, _ = fmt.Fprintln(w, color.RedString("Cannot read the registries from the project modules.json file: %s", err.Error()))
+			return nil, err
+		}
+	}
+	if len(local.Registries) == 0 {
+		return manifestFromRegistry(w, manifesto.Registry{Name: "public", Url: DefaultManifestUrl}, offline)
 	}
 
-	manifestFs := os.DirFS(filepath.Dir(manifestPath))
-	manifestFile := filepath.Base(manifestPath)
+	registries := make([]manifesto.RegistryManifest, 0, len(local.Registries))
+	for _, registry := range local.Registries {
+		if !isRemoteUrl(registry.Url) && !filepath.IsAbs(registry.Url) {
+			registry.Url = filepath.Join(projPath, registry.Url)
+		}
+		m, err := manifestFromRegistry(w, registry, offline)
+		if err != nil {
+			_, _ = fmt.Fprintln(w, color.RedString("Cannot get the manifest of the registry %s", registry.Name))
+			return nil, err
+		}
+		registries = append(
+			registries, manifesto.RegistryManifest{
+				Registry: registry,
+				Manifest: m,
+			},
+		)
+	}
+
+	res, err := manifesto.MergeRegistries(registries)
+	if err != nil {
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot merge the registries: %s", err.Error()))
+		return nil, err
+	}
+	return res, nil
+}
+
+func manifestFromRegistry(w io.Writer, registry manifesto.Registry, offline bool) (*manifesto.LocalManifesto, error) {
+	if isRemoteUrl(registry.Url) {
+		m, err := manifestFromURL(w, registry, offline)
+		if err != nil {
+			return nil, err
+		}
+		m.SetRegistry(registry)
+		return m, nil
+	}
+
+	manifestFs := os.DirFS(filepath.Dir(registry.Url))
+	manifestFile := filepath.Base(registry.Url)
 
 	availableModulesManifest, err := manifesto.NewFromFs(manifestFs, manifestFile)
 	if err != nil {
-		fmt.Println(color.RedString("Cannot read from the manifest file: %s", err.Error()))
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot read from the manifest file: %s", err.Error()))
 		return nil, err
 	}
+	availableModulesManifest.SetRegistry(registry)
 	return availableModulesManifest, nil
 }
 
-func manifestFromURL(url string) (*manifesto.LocalManifesto, error) {
-	resp, err := http.Get(url) //nolint:noctx
+func isRemoteUrl(url string) bool {
+	return strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")
+}
+
+// manifestFromURL fetches the manifest through the mtools cache.
+// The cached copy is revalidated on every call, in the offline mode the copy is used without requests.
+func manifestFromURL(w io.Writer, registry manifesto.Registry, offline bool) (*manifesto.LocalManifesto, error) {
+	req, client, err := NewRegistryRequest(context.Background(), registry, registry.Url, offline)
 	if err != nil {
-		fmt.Println(color.RedString("Cannot fetch the manifest from URL: %s", err.Error()))
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot create a request to the registry %s: %s", registry.Name, err.Error()))
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("unexpected status %s", resp.Status)
-		fmt.Println(color.RedString("Cannot fetch the manifest from URL: %s", err.Error()))
+	mtoolsCache, err := cache.Default()
+	if err != nil {
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot find the cache directory: %s", err.Error()))
 		return nil, err
 	}
-
-	data, err := io.ReadAll(resp.Body)
+	data, err := mtoolsCache.Fetch(client, req, offline)
 	if err != nil {
-		fmt.Println(color.RedString("Cannot read the manifest response body: %s", err.Error()))
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot fetch the manifest from URL: %s", err.Error()))
+		if offline {
+			_, _ = fmt.Fprintln(w, color.YellowString("Hint: run the command without the --offline flag once to cache the manifest"))
+		}
 		return nil, err
 	}
 
 	m := &manifesto.LocalManifesto{}
 	if err = m.ReadFromJSON(data); err != nil {
-		fmt.Println(color.RedString("Cannot parse the manifest JSON: %s", err.Error()))
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot parse the manifest JSON: %s", err.Error()))
 		return nil, err
 	}
 	return m, nil
 }
+
+// NewRegistryRequest creates a GET request to the resource of the registry, e.g. the manifest or an install file,
+// and the client with the timeout of the registry.
+// The credentials of the registry are added only to the requests to the registry host and not in the offline mode.
+func NewRegistryRequest(
+	ctx context.Context,
+	registry manifesto.Registry,
+	resourceUrl string,
+	offline bool,
+) (*http.Request, *http.Client, error) {
+	timeout := defaultRegistryTimeout
+	if registry.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(registry.Timeout)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wrong timeout %s: %w", registry.Timeout, err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceUrl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !offline && isRegistryHost(registry, req.URL) {
+		err = setRegistryAuth(req, registry)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return req, &http.Client{Timeout: timeout}, nil
+}
+
+// isRegistryHost checks that the URL points to the host of the remote registry
+func isRegistryHost(registry manifesto.Registry, resourceUrl *url.URL) bool {
+	if !isRemoteUrl(registry.Url) {
+		return false
+	}
+	registryUrl, err := url.Parse(registry.Url)
+	if err != nil {
+		return false
+	}
+	return registryUrl.Host == resourceUrl.Host
+}
+
+// setRegistryAuth adds the credentials taken from the env variables to the request
+func setRegistryAuth(req *http.Request, registry manifesto.Registry) error {
+	if registry.Auth == nil {
+		return nil
+	}
+	switch registry.Auth.Type {
+	case manifesto.AuthTypeBearer:
+		token := os.Getenv(registry.Auth.TokenEnv)
+		if token == "" {
+			return fmt.Errorf("%w: set the %s env variable", ErrRegistryCredentialsNotSet, registry.Auth.TokenEnv)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case manifesto.AuthTypeBasic:
+		username := os.Getenv(registry.Auth.UsernameEnv)
+		if username == "" {
+			return fmt.Errorf("%w: set the %s env variable", ErrRegistryCredentialsNotSet, registry.Auth.UsernameEnv)
+		}
+		req.SetBasicAuth(username, os.Getenv(registry.Auth.PasswordEnv))
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownRegistryAuthType, registry.Auth.Type)
+	}
+	return nil
+}
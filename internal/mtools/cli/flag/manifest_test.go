@@ -0,0 +1,191 @@
+package flag_test
+
+import (
+	"context"
+	goflag "flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cache"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+const privateRegistryJson = `{
+  "modules": [
+    {
+      "name": "billing",
+      "package": "github.com/company/billing",
+      "install": {}
+    }
+  ]
+}`
+
+const publicRegistryJson = `{
+  "modules": [
+    {
+      "name": "pgx",
+      "package": "github.com/go-modulus/modulus/db/pgx",
+      "install": {}
+    }
+  ]
+}`
+
+func newContext(projPath string, manifestPath string) *cli.Context {
+	set := goflag.NewFlagSet("test", 0)
+	set.String("proj-path", projPath, "doc")
+	set.String("manifest", flag.DefaultManifestUrl, "doc")
+	if manifestPath != "" {
+		_ = set.Set("manifest", manifestPath)
+	}
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestManifestValue(t *testing.T) {
+	t.Run(
+		"merge project registries with auth", func(t *testing.T) {
+			server := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						if r.Header.Get("Authorization") != "Bearer secret" {
+							w.WriteHeader(http.StatusUnauthorized)
+							return
+						}
+						_, _ = w.Write([]byte(privateRegistryJson))
+					},
+				),
+			)
+			defer server.Close()
+			t.Setenv("PRIVATE_REGISTRY_TOKEN", "secret")
+			t.Setenv(cache.DirEnv, t.TempDir())
+
+			projDir := t.TempDir()
+			err := os.WriteFile(projDir+"/public.json", []byte(publicRegistryJson), 0644)
+			require.NoError(t, err)
+			local := &manifesto.LocalManifesto{
+				Registries: []manifesto.Registry{
+					{
+						Name:    "private",
+						Url:     server.URL,
+						Timeout: "5s",
+						Auth: &manifesto.RegistryAuth{
+							Type:     manifesto.AuthTypeBearer,
+							TokenEnv: "PRIVATE_REGISTRY_TOKEN",
+						},
+					},
+					{
+						Name: "public",
+						Url:  "public.json",
+					},
+				},
+			}
+			err = local.SaveAsLocalManifest(projDir)
+			require.NoError(t, err)
+
+			res, err := flag.ManifestValue(newContext(projDir, ""))
+
+			t.Log("Given a project with a private registry requiring a token and a local registry")
+			t.Log("When get the manifest without the --manifest flag")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The modules of both registries should be merged")
+			require.Len(t, res.Modules, 2)
+			require.Equal(t, "billing", res.Modules[0].Name)
+			require.Equal(t, "pgx", res.Modules[1].Name)
+		},
+	)
+
+	t.Run(
+		"fail if the registry token is not set", func(t *testing.T) {
+			projDir := t.TempDir()
+			local := &manifesto.LocalManifesto{
+				Registries: []manifesto.Registry{
+					{
+						Name: "private",
+						Url:  "https://registry.example.com/modules.json",
+						Auth: &manifesto.RegistryAuth{
+							Type:     manifesto.AuthTypeBearer,
+							TokenEnv: "NOT_SET_REGISTRY_TOKEN",
+						},
+					},
+				},
+			}
+			err := local.SaveAsLocalManifest(projDir)
+			require.NoError(t, err)
+
+			_, err = flag.ManifestValue(newContext(projDir, ""))
+
+			t.Log("Given a private registry which token env variable is not set")
+			t.Log("When get the manifest")
+			t.Log("	The ErrRegistryCredentialsNotSet error should be returned")
+			require.ErrorIs(t, err, flag.ErrRegistryCredentialsNotSet)
+		},
+	)
+
+	t.Run(
+		"use the manifest flag instead of the registries", func(t *testing.T) {
+			projDir := t.TempDir()
+			err := os.WriteFile(projDir+"/public.json", []byte(publicRegistryJson), 0644)
+			require.NoError(t, err)
+			local := &manifesto.LocalManifesto{
+				Registries: []manifesto.Registry{
+					{Name: "private", Url: "https://registry.example.com/modules.json"},
+				},
+			}
+			err = local.SaveAsLocalManifest(projDir)
+			require.NoError(t, err)
+
+			res, err := flag.ManifestValue(newContext(projDir, projDir+"/public.json"))
+
+			t.Log("Given a project with registries")
+			t.Log("When get the manifest with the --manifest flag")
+			t.Log("	Only the manifest from the flag should be used")
+			require.NoError(t, err)
+			require.Len(t, res.Modules, 1)
+			require.Equal(t, "pgx", res.Modules[0].Name)
+		},
+	)
+}
+
+func TestNewRegistryRequest(t *testing.T) {
+	t.Run(
+		"add the credentials only to the requests to the registry host", func(t *testing.T) {
+			t.Setenv("PRIVATE_REGISTRY_TOKEN", "secret")
+			registry := manifesto.Registry{
+				Name:    "private",
+				Url:     "https://registry.example.com/modules.json",
+				Timeout: "5s",
+				Auth:    &manifesto.RegistryAuth{Type: manifesto.AuthTypeBearer, TokenEnv: "PRIVATE_REGISTRY_TOKEN"},
+			}
+
+			req, client, err := flag.NewRegistryRequest(
+				context.Background(),
+				registry,
+				"https://registry.example.com/files/config.yaml",
+				false,
+			)
+			otherReq, _, errOther := flag.NewRegistryRequest(
+				context.Background(),
+				registry,
+				"https://raw.githubusercontent.com/company/billing/config.yaml",
+				false,
+			)
+
+			t.Log("Given a private registry with the bearer auth and the timeout")
+			t.Log("When create the requests to the module files")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			require.NoError(t, errOther)
+			t.Log("	The file of the registry host should be requested with the credentials and the registry timeout")
+			require.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+			require.Equal(t, 5*time.Second, client.Timeout)
+			t.Log("	The file of another host should be requested without the credentials")
+			require.Empty(t, otherReq.Header.Get("Authorization"))
+		},
+	)
+}
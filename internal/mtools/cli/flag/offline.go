@@ -0,0 +1,9 @@
+package flag
+
+import "github.com/urfave/cli/v2"
+
+// OfflineValue returns the value of the global --offline flag.
+// In the offline mode the registry manifests and the module files are taken only from the mtools cache.
+func OfflineValue(ctx *cli.Context) bool {
+	return ctx.Bool("offline")
+}
@@ -0,0 +1,26 @@
+package manifest
+
+import (
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/urfave/cli/v2"
+)
+
+func NewManifestCommand(
+	validate *Validate,
+	schema *Schema,
+	migrate *Migrate,
+) *cli.Command {
+	return &cli.Command{
+		Name: "manifest",
+		Usage: `A set of commands for the modules.json manifests of projects and registries.
+Example: mtools manifest
+`,
+		Before: flag.DryRunBefore,
+		After:  flag.DryRunAfter,
+		Subcommands: []*cli.Command{
+			NewValidateCommand(validate),
+			NewSchemaCommand(schema),
+			NewMigrateCommand(migrate),
+		},
+	}
+}
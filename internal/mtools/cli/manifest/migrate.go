@@ -0,0 +1,85 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/urfave/cli/v2"
+)
+
+type Migrate struct {
+}
+
+func NewMigrate() *Migrate {
+	return &Migrate{}
+}
+
+func NewMigrateCommand(migrate *Migrate) *cli.Command {
+	return &cli.Command{
+		Name: "migrate",
+		Usage: `Rewrite the modules.json file of the project in the newest format.
+The original file is saved to modules.json.bak. The file already in the newest format is kept untouched.
+Example: mtools manifest migrate
+`,
+		Action: migrate.Invoke,
+	}
+}
+
+func (m *Migrate) Invoke(ctx *cli.Context) error {
+	projPath := flag.ProjPathValue(ctx)
+	filename := projPath + "/modules.json"
+	manifestLock, err := manifesto.LockLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot lock the modules.json file: %s", err.Error()))
+		return err
+	}
+	defer manifestLock.Unlock()
+
+	data, err := fsys.ReadFile(filename)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the manifest %s: %s", filename, err.Error()))
+		return err
+	}
+
+	migrated, version, err := manifesto.Migrate(data)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot migrate the manifest %s: %s", filename, err.Error()))
+		return err
+	}
+	if version == manifesto.SchemaVersion {
+		fmt.Println(color.GreenString("The manifest already has the newest schema version %d.", version))
+		return nil
+	}
+
+	manifest := &manifesto.LocalManifesto{}
+	err = manifest.ReadFromStrictJSON(migrated, manifesto.LocalSchema)
+	if err != nil {
+		fmt.Println(color.RedString("The migrated manifest does not match the schema:\n%s", err.Error()))
+		fmt.Println(color.YellowString("Hint: %s", "Fix the reported fields in the modules.json file and run the command again."))
+		return err
+	}
+
+	backup := filename + ".bak"
+	err = fsys.WriteFile(backup, data, 0644)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot save the backup %s: %s", backup, err.Error()))
+		return err
+	}
+	err = manifest.SaveAsLocalManifest(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot save the manifest %s: %s", filename, err.Error()))
+		return err
+	}
+	fmt.Println(
+		color.GreenString(
+			"The manifest is migrated from the schema version %d to %d. The original file is saved to",
+			version,
+			manifesto.SchemaVersion,
+		),
+		color.BlueString(backup),
+	)
+	return nil
+}
@@ -0,0 +1,47 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/urfave/cli/v2"
+)
+
+type Schema struct {
+}
+
+func NewSchema() *Schema {
+	return &Schema{}
+}
+
+func NewSchemaCommand(schema *Schema) *cli.Command {
+	return &cli.Command{
+		Name: "schema",
+		Usage: `Print the JSON Schema of the modules.json file to use it in an editor or in CI.
+Example: mtools manifest schema > modules.schema.json
+Example for a registry: mtools manifest schema --registry
+`,
+		Action: schema.Invoke,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "registry",
+				Usage: "Print the schema of a registry manifest instead of a project one",
+			},
+		},
+	}
+}
+
+func (s *Schema) Invoke(ctx *cli.Context) error {
+	schemaName := manifesto.LocalSchema
+	if ctx.Bool("registry") {
+		schemaName = manifesto.RegistrySchema
+	}
+	data, err := manifesto.Schema(schemaName)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the schema: %s", err.Error()))
+		return err
+	}
+	_, err = ctx.App.Writer.Write(data)
+	return err
+}
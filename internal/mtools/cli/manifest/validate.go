@@ -0,0 +1,92 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrManifestIsInvalid = errbuilder.New("manifest does not match the schema").
+	WithHint("Fix the reported fields. Run mtools manifest schema to see the allowed fields.").Build()
+
+type Validate struct {
+}
+
+func NewValidate() *Validate {
+	return &Validate{}
+}
+
+func NewValidateCommand(validate *Validate) *cli.Command {
+	return &cli.Command{
+		Name: "validate",
+		Usage: `Check the manifests against the JSON Schema and report the unknown fields and wrong values with their positions.
+Validates the modules.json file of the project if no files are passed. Exits with a non-zero code if any file is invalid.
+Example: mtools manifest validate
+Example for a registry: mtools manifest validate --registry registry/modules.json
+`,
+		ArgsUsage: "[files...]",
+		Action:    validate.Invoke,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "registry",
+				Usage: "Validate the files as the manifests of a registry instead of a project",
+			},
+		},
+	}
+}
+
+func (v *Validate) Invoke(ctx *cli.Context) error {
+	schemaName := manifesto.LocalSchema
+	if ctx.Bool("registry") {
+		schemaName = manifesto.RegistrySchema
+	}
+	filenames := ctx.Args().Slice()
+	if len(filenames) == 0 {
+		filenames = []string{flag.ProjPathValue(ctx) + "/modules.json"}
+	}
+
+	invalid := false
+	for _, filename := range filenames {
+		data, err := fsys.ReadFile(filename)
+		if err != nil {
+			fmt.Println(color.RedString("Cannot read the manifest %s: %s", filename, err.Error()))
+			return err
+		}
+		errs, err := manifesto.Validate(data, schemaName)
+		if err != nil {
+			fmt.Println(color.RedString("Cannot validate the manifest %s: %s", filename, err.Error()))
+			return err
+		}
+		if len(errs) == 0 {
+			fmt.Printf("%s %s\n", color.BlueString(filename), color.GreenString("is valid"))
+			continue
+		}
+		invalid = true
+		PrintFieldErrors(os.Stdout, filename, errs)
+	}
+
+	if invalid {
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(ErrManifestIsInvalid)))
+		return ErrManifestIsInvalid
+	}
+	return nil
+}
+
+// PrintFieldErrors prints the schema errors of the manifest file in the file:line:col: path: message format
+func PrintFieldErrors(w io.Writer, filename string, errs []*manifesto.FieldError) {
+	for _, e := range errs {
+		path := ""
+		if e.Path != "" {
+			path = e.Path + ": "
+		}
+		_, _ = fmt.Fprintf(w, "%s:%d:%d: %s%s\n", filename, e.Line, e.Column, path, color.RedString(e.Message))
+	}
+}
@@ -1,6 +1,7 @@
 package module
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 
@@ -139,6 +140,7 @@ func (a *AddCli) createCommandFile(
 		fmt.Println(
 			color.RedString("Cannot add a constructor to the module.go file: %s", err.Error()),
 		)
+		printInsertionPointHint(err, "cmd.New"+structName)
 		return err
 	}
 
@@ -147,12 +149,28 @@ func (a *AddCli) createCommandFile(
 		fmt.Println(
 			color.RedString("Cannot add a CLI command constructor to the module.go file: %s", err.Error()),
 		)
+		printInsertionPointHint(err, "cmd.New"+structName+"Command")
 		return err
 	}
 
 	return nil
 }
 
+// printInsertionPointHint explains how to finish the generation manually if the module.go file has an unknown shape
+func printInsertionPointHint(err error, constructor string) {
+	var insertionErr *files.InsertionPointError
+	if errors.As(err, &insertionErr) {
+		fmt.Println(
+			color.YellowString(
+				"Hint: add %s to the %s call of the module in the function %s manually",
+				constructor,
+				insertionErr.Method,
+				insertionErr.Function,
+			),
+		)
+	}
+}
+
 func (a *AddCli) askCommandName() string {
 	for {
 		prompt := promptui.Prompt{
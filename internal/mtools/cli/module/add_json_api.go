@@ -181,6 +181,7 @@ func (a *AddJsonApi) createApiHandlerFile(
 		fmt.Println(
 			color.RedString("Cannot add a constructor to the module.go file: %s", err.Error()),
 		)
+		printInsertionPointHint(err, "api.New"+structName)
 		return err
 	}
 
@@ -189,6 +190,7 @@ func (a *AddJsonApi) createApiHandlerFile(
 		fmt.Println(
 			color.RedString("Cannot add a route constructor to the module.go file: %s", err.Error()),
 		)
+		printInsertionPointHint(err, "api.New"+structName+"Route")
 		return err
 	}
 
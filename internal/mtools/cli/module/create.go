@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"html/template"
 	"log/slog"
-	"os"
 	"regexp"
 	"slices"
 
@@ -15,14 +14,15 @@ import (
 	"github.com/go-modulus/modulus/module"
 	"github.com/go-modulus/mtools/internal/manifesto"
 	"github.com/go-modulus/mtools/internal/mtools/action"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
 	"github.com/go-modulus/mtools/internal/mtools/files"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
 	"github.com/go-modulus/mtools/internal/mtools/templates"
 	"github.com/go-modulus/mtools/internal/mtools/utils"
 	"github.com/manifoldco/promptui"
 	"github.com/urfave/cli/v2"
 )
 
-var moduleNameRegexp = regexp.MustCompile(`module\s+([a-zA-Z0-9_\-\/\.]+)+`)
 var pckgNameRegexp = regexp.MustCompile(`^[a-z]+[a-z0-9]+`)
 
 type features struct {
@@ -83,6 +83,10 @@ Example filling default values without UI: mtools module create --package=mypckg
 				Name:  "without",
 				Usage: "Set the list of features to install the module without. Available values: storage, graphql",
 			},
+			flag.NewEntries(
+				"A comma-separated list of the entrypoints names to wire the module into. " +
+					"The entrypoints are chosen interactively or all of them are used in the silent mode",
+			),
 		},
 	}
 }
@@ -107,7 +111,7 @@ func (c *Create) Invoke(
 		return err
 	}
 
-	err = os.MkdirAll(projPath+"/"+manifestItem.LocalPath, 0755)
+	err = fsys.MkdirAll(projPath+"/"+manifestItem.LocalPath, 0755)
 	if err != nil {
 		fmt.Println(color.RedString("Cannot create a directory %s: %s", manifestItem.LocalPath, err.Error()))
 		return err
@@ -128,7 +132,7 @@ func (c *Create) Invoke(
 		return err
 	}
 
-	err = c.updateEntripoints(projPath, manifestItem)
+	err = c.updateEntripoints(ctx, projPath, manifestItem)
 	if err != nil {
 		return err
 	}
@@ -141,17 +145,34 @@ func (c *Create) Invoke(
 }
 
 func (c *Create) updateEntripoints(
+	ctx *cli.Context,
 	projPath string,
 	md module.Manifesto,
 ) error {
 	fmt.Println(color.BlueString("Updating entrypoints..."))
 
+	manifestLock, err := manifesto.LockLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot lock the modules.json file: %s", err.Error()))
+		return err
+	}
+	defer manifestLock.Unlock()
+
 	manifest, err := manifesto.LoadLocalManifesto(projPath)
 	if err != nil {
 		fmt.Println(color.RedString("Cannot get a local manifest: %s", err.Error()))
 		return err
 	}
+	allEntries := manifesto.EntryNames(manifest.Entries)
+	entryNames, err := flag.EntriesValue(ctx, allEntries, !ctx.Bool("silent"))
+	if err != nil {
+		fmt.Println(color.RedString("Cannot choose the entrypoints: %s", err.Error()))
+		return err
+	}
 	for _, entry := range manifest.Entries {
+		if !slices.Contains(entryNames, entry.Name) {
+			continue
+		}
 		err = files.AddModuleToEntrypoint(md.Package, projPath+"/"+entry.LocalPath)
 		if err != nil {
 			fmt.Println(
@@ -166,6 +187,15 @@ func (c *Create) updateEntripoints(
 		}
 	}
 
+	if len(allEntries) == 0 {
+		return nil
+	}
+	manifest.WireModule(md.Package, entryNames)
+	err = manifest.SaveAsLocalManifest(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot save a local manifest: %s", err.Error()))
+		return err
+	}
 	return nil
 }
 
@@ -311,7 +341,7 @@ func (c *Create) addModuleFile(
 		return err
 	}
 
-	err = os.WriteFile(md.ModulePath(projPath)+"/module.go", b.Bytes(), 0644)
+	err = fsys.WriteFile(md.ModulePath(projPath)+"/module.go", b.Bytes(), 0644)
 	if err != nil {
 		fmt.Println(color.RedString("Cannot write a module file: %s", err.Error()))
 		return err
@@ -320,6 +350,13 @@ func (c *Create) addModuleFile(
 }
 
 func (c *Create) saveManifestItem(manifestItem module.Manifesto, projPath string) (err error) {
+	manifestLock, err := manifesto.LockLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot lock the modules.json file: %s", err.Error()))
+		return err
+	}
+	defer manifestLock.Unlock()
+
 	manifest, err := manifesto.LoadLocalManifesto(projPath)
 	if err != nil {
 		fmt.Println(color.RedString("Cannot get a local manifest: %s", err.Error()))
@@ -342,26 +379,6 @@ func (c *Create) saveManifestItem(manifestItem module.Manifesto, projPath string
 	return nil
 }
 
-func (c *Create) getProjModuleName(projPath string) (string, error) {
-	if _, err := os.Stat(projPath + "/go.mod"); os.IsNotExist(err) {
-		fmt.Println(color.RedString("The go.mod file is not found. Try to run the command in the root of the project"))
-		return "", err
-	}
-	content, err := os.ReadFile(projPath + "/go.mod")
-	if err != nil {
-		fmt.Println(color.RedString("Cannot read a go.mod file: %s", err.Error()))
-		return "", err
-	}
-
-	moduleStr := moduleNameRegexp.FindStringSubmatch(string(content))
-	if len(moduleStr) < 2 {
-		fmt.Println(color.RedString("Cannot find a module name in the go.mod file"))
-		return "", errors.New("cannot find a module name in the go.mod file")
-	}
-
-	return moduleStr[1], nil
-}
-
 func (c *Create) getManifestItem(ctx *cli.Context, projPath string) (
 	res module.Manifesto,
 	err error,
@@ -419,8 +436,9 @@ func (c *Create) getManifestItem(ctx *cli.Context, projPath string) (
 		path += "/" + pckg
 	}
 
-	projPckg, err := c.getProjModuleName(projPath)
+	projPckg, err := utils.ProjectPackage(projPath)
 	if err != nil {
+		fmt.Println(color.RedString("Cannot get the project package: %s", err.Error()))
 		return module.Manifesto{}, err
 	}
 
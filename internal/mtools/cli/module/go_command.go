@@ -0,0 +1,19 @@
+package module
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/go-modulus/mtools/internal/mtools/cache"
+)
+
+// newGoCommand creates the go command.
+// In the offline mode the go command takes the modules only from the module cache and does not use the Go proxy.
+func newGoCommand(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	if cache.IsOffline(ctx) {
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	}
+	return cmd
+}
@@ -0,0 +1,209 @@
+package module
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrModuleIsNotFound = errbuilder.New("module is not found").
+	WithHint("Run the mtools module list command to see the names of the installed and available modules.").Build()
+
+type ModulePaths struct {
+	Module  string `json:"module" yaml:"module"`
+	Storage string `json:"storage" yaml:"storage"`
+	Cli     string `json:"cli" yaml:"cli"`
+	Api     string `json:"api" yaml:"api"`
+}
+
+type EnvVarInfo struct {
+	Key     string `json:"key" yaml:"key"`
+	Value   string `json:"value" yaml:"value"`
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+type ModuleInfo struct {
+	Name         string       `json:"name" yaml:"name"`
+	Package      string       `json:"package" yaml:"package"`
+	Description  string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Version      string       `json:"version,omitempty" yaml:"version,omitempty"`
+	Status       string       `json:"status" yaml:"status"`
+	Paths        *ModulePaths `json:"paths,omitempty" yaml:"paths,omitempty"`
+	EnvVars      []EnvVarInfo `json:"envVars" yaml:"envVars"`
+	Dependencies []string     `json:"dependencies" yaml:"dependencies"`
+	Dependents   []string     `json:"dependents" yaml:"dependents"`
+}
+
+type Info struct {
+	logger *slog.Logger
+}
+
+func NewInfo(
+	logger *slog.Logger,
+) *Info {
+	return &Info{
+		logger: logger,
+	}
+}
+
+func NewInfoCommand(info *Info) *cli.Command {
+	return &cli.Command{
+		Name: "info",
+		Usage: `Shows the details of the module: its package, paths, env variables, dependencies and the installed modules depending on it.
+The module is searched in the modules.json file of the project first and then in the registry manifest.
+Example: mtools module info pgx
+Example: mtools module info --format=json "dbmate migrator"
+`,
+		ArgsUsage: "<module name>",
+		Action:    info.Invoke,
+		Flags: []cli.Flag{
+			flag.NewFormat("An output format: table, json or yaml"),
+			flag.NewManifest(
+				`A path to the global manifest with all available modules.
+Example: mtools module info --manifest="local_folder/modules.json" pgx`,
+			),
+		},
+	}
+}
+
+func (c *Info) Invoke(
+	ctx *cli.Context,
+) error {
+	projPath := flag.ProjPathValue(ctx)
+	format, err := flag.FormatValue(ctx)
+	w := flag.MessagesWriter(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintln(w, color.RedString(err.Error()))
+		return err
+	}
+	name := strings.Join(ctx.Args().Slice(), " ")
+	if name == "" {
+		_, _ = fmt.Fprintln(w, color.RedString("The module name is required. Example: mtools module info pgx"))
+		return ErrModuleIsNotFound
+	}
+
+	manifest, err := manifesto.LoadLocalManifesto(projPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot get the local modules.json manifest file: %s", err.Error()))
+		return err
+	}
+
+	md, found := manifest.FindModule(name)
+	status := StatusInstalled
+	if md.IsLocalModule {
+		status = StatusLocal
+	}
+	if !found {
+		availableModulesManifest, err := flag.ManifestValue(ctx)
+		if err != nil {
+			_, _ = fmt.Fprintln(w, color.RedString("Cannot get the manifest file: %s", err.Error()))
+			return err
+		}
+		md, found = availableModulesManifest.FindModule(name)
+		status = StatusAvailable
+	}
+	if !found {
+		_, _ = fmt.Fprintln(
+			w,
+			color.RedString("The module"),
+			color.BlueString(name),
+			color.RedString("is found neither in the project nor in the registry manifest"),
+		)
+		_, _ = fmt.Fprintln(w, color.YellowString("Hint: %s", errors.Hint(ErrModuleIsNotFound)))
+		return ErrModuleIsNotFound
+	}
+
+	info := newModuleInfo(md, status, manifest, projPath)
+	if format == flag.FormatTable {
+		printModuleInfo(ctx.App.Writer, info)
+		return nil
+	}
+	return writeFormatted(ctx.App.Writer, format, info)
+}
+
+func newModuleInfo(
+	md module.Manifesto,
+	status string,
+	local *manifesto.LocalManifesto,
+	projPath string,
+) ModuleInfo {
+	res := ModuleInfo{
+		Name:         md.Name,
+		Package:      md.Package,
+		Description:  md.Description,
+		Version:      md.Version,
+		Status:       status,
+		EnvVars:      make([]EnvVarInfo, 0, len(md.Install.EnvVars)),
+		Dependencies: append(make([]string, 0, len(md.Install.Dependencies)), md.Install.Dependencies...),
+		Dependents:   make([]string, 0),
+	}
+	if md.LocalPath != "" {
+		res.Paths = &ModulePaths{
+			Module:  md.ModulePath(projPath),
+			Storage: md.StoragePath(projPath),
+			Cli:     md.CliPath(projPath),
+			Api:     md.ApiPath(projPath),
+		}
+	}
+	for _, envVar := range md.Install.EnvVars {
+		res.EnvVars = append(
+			res.EnvVars, EnvVarInfo{
+				Key:     envVar.Key,
+				Value:   envVar.Value,
+				Comment: envVar.Comment,
+			},
+		)
+	}
+	for _, dependent := range local.DependentModules(md.Name) {
+		res.Dependents = append(res.Dependents, dependent.Name)
+	}
+	return res
+}
+
+func printModuleInfo(out io.Writer, info ModuleInfo) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "Name:\t%s\n", info.Name)
+	_, _ = fmt.Fprintf(w, "Package:\t%s\n", info.Package)
+	if info.Description != "" {
+		_, _ = fmt.Fprintf(w, "Description:\t%s\n", info.Description)
+	}
+	_, _ = fmt.Fprintf(w, "Version:\t%s\n", versionOrDash(info.Version))
+	_, _ = fmt.Fprintf(w, "Status:\t%s\n", colorStatus(info.Status))
+	if info.Paths != nil {
+		_, _ = fmt.Fprintf(w, "Module path:\t%s\n", info.Paths.Module)
+		_, _ = fmt.Fprintf(w, "Storage path:\t%s\n", info.Paths.Storage)
+		_, _ = fmt.Fprintf(w, "CLI path:\t%s\n", info.Paths.Cli)
+		_, _ = fmt.Fprintf(w, "API path:\t%s\n", info.Paths.Api)
+	}
+	_, _ = fmt.Fprintf(w, "Dependencies:\t%s\n", listOrDash(info.Dependencies))
+	_, _ = fmt.Fprintf(w, "Required by:\t%s\n", listOrDash(info.Dependents))
+	_ = w.Flush()
+
+	if len(info.EnvVars) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ENV VARIABLE\tDEFAULT VALUE\tCOMMENT")
+	for _, envVar := range info.EnvVars {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", envVar.Key, envVar.Value, envVar.Comment)
+	}
+	_ = w.Flush()
+}
+
+func listOrDash(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,77 @@
+package module_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/cli/module"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestInfo_Invoke(t *testing.T) {
+	t.Run(
+		"show info of installed module", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+			err = installModulesInTmpDir(projDir, "dbmate migrator")
+			require.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			app := cli.NewApp()
+			app.Writer = out
+			set := flag.NewFlagSet("test", 0)
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			set.String("proj-path", projDir, "doc")
+			set.String("format", "json", "doc")
+			err = set.Parse([]string{"pgx"})
+			require.NoError(t, err)
+			ctx := cli.NewContext(app, set, nil)
+			err = moduleInfo.Invoke(ctx)
+
+			info := module.ModuleInfo{}
+			errJson := json.Unmarshal(out.Bytes(), &info)
+
+			t.Log("When show the info of a module installed as a dependency")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			require.NoError(t, errJson)
+			t.Log("	The module should have the installed status")
+			require.Equal(t, module.StatusInstalled, info.Status)
+			require.Equal(t, "github.com/go-modulus/modulus/db/pgx", info.Package)
+			t.Log("	The env variables and dependencies should be shown")
+			require.Equal(t, "DB_NAME", info.EnvVars[0].Key)
+			require.Equal(t, []string{"slog logger"}, info.Dependencies)
+			t.Log("	The dependent module should be shown")
+			require.Equal(t, []string{"dbmate migrator"}, info.Dependents)
+		},
+	)
+
+	t.Run(
+		"fail on unknown module", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			set.String("proj-path", projDir, "doc")
+			err := set.Parse([]string{"unknown"})
+			require.NoError(t, err)
+			ctx := cli.NewContext(app, set, nil)
+			err = moduleInfo.Invoke(ctx)
+
+			t.Log("When show the info of a module that is neither installed nor available")
+			t.Log("	The ErrModuleIsNotFound error should be returned")
+			require.ErrorIs(t, err, module.ErrModuleIsNotFound)
+		},
+	)
+}
@@ -6,10 +6,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
-	"os/exec"
 	"path"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -19,8 +18,11 @@ import (
 	"github.com/go-modulus/modulus/errors/errbuilder"
 	"github.com/go-modulus/modulus/module"
 	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cache"
 	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
 	"github.com/go-modulus/mtools/internal/mtools/files"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/go-modulus/mtools/internal/mtools/journal"
 	"github.com/go-modulus/mtools/internal/mtools/utils"
 	"github.com/manifoldco/promptui"
 	"github.com/urfave/cli/v2"
@@ -29,6 +31,10 @@ import (
 var ErrPackageIsEmpty = errbuilder.New("package is empty").
 	WithHint("Please provide a package for the module in the manifest file.").Build()
 var ErrCannotRunGoGetCommand = errbuilder.New("cannot run go get command").Build()
+var ErrChecksumMismatch = errbuilder.New("checksum of the downloaded file does not match the manifest").
+	WithHint("The file may be changed or corrupted. Check the source URL and the sha256 of the file in the registry manifest.").Build()
+var ErrCannotAddModuleToEntrypoint = errbuilder.New("cannot add the module to the entrypoint").
+	WithHint("The entrypoint should have the modules slice passed to the app. Fix the entrypoint or type the initialization code manually.").Build()
 var ErrCannotInstallModule = errbuilder.New("cannot install the module").
 	WithHint("The install field in the manifest file should be a valid command running under 'go run'").Build()
 
@@ -39,13 +45,16 @@ type InstalledFileVars struct {
 
 type Install struct {
 	logger *slog.Logger
+	cache  *cache.Cache
 }
 
 func NewInstall(
 	logger *slog.Logger,
+	cache *cache.Cache,
 ) *Install {
 	return &Install{
 		logger: logger,
+		cache:  cache,
 	}
 }
 
@@ -58,6 +67,12 @@ Adds the chosen module to the project and inits it with default files.
 Example: mtools module install
 Example without UI: mtools module install --modules="urfave cli,pgx"
 Example with a custom manifest located at proj-dir/manifest/modules.json: mtools module install --manifest="proj-dir/manifest/modules.json"
+Example with the versions from the lock file: mtools module install --modules="pgx" --frozen
+Example wiring the modules only into the chosen entrypoints: mtools module install --modules="chi" --entries=api,worker
+
+The versions of modules are resolved from the semver constraints declared in the "constraints" section of the manifests
+(e.g. "constraints": {"pgx": "^0.5.0"}) and in the dependencies of modules (e.g. "dependencies": ["pgx@^0.5.0"]).
+The resolved versions are saved to the modules.lock.json file next to the modules.json file.
 `,
 		Action: addModule.Invoke,
 		Flags: []cli.Flag{
@@ -66,10 +81,18 @@ Example with a custom manifest located at proj-dir/manifest/modules.json: mtools
 				Usage:   "A comma-separated list of modules names to add to the project",
 				Aliases: []string{"m"},
 			},
+			&cli.BoolFlag{
+				Name:  "frozen",
+				Usage: "Install exactly the versions from the modules.lock.json file and fail if the lock file has to be changed",
+			},
 			flag.NewManifest(
 				`A path to the global manifest with all available modules to install.
 Example: mtools module install --manifest="local_folder/modules.json"`,
 			),
+			flag.NewEntries(
+				"A comma-separated list of the entrypoints names to wire the modules into. " +
+					"The entrypoints are chosen interactively or all of them are used with the --modules flag",
+			),
 		},
 	}
 }
@@ -87,21 +110,18 @@ func (c *Install) Invoke(
 		return err
 	}
 	projPath := ctx.String("proj-path")
-	if projPath != "" {
-		curDir, err := os.Getwd()
-		if err != nil {
-			fmt.Println(color.RedString("Cannot get the current directory: %s", err.Error()))
-			return err
-		}
-		err = os.Chdir(projPath)
-		if err != nil {
-			fmt.Println(color.RedString("Cannot change the current directory to %s: %s", projPath, err.Error()))
-			return err
-		}
-		curDirAfterChange, _ := os.Getwd()
-		fmt.Printf("Changing the current dir to %s\n", color.BlueString(curDirAfterChange))
-		defer func() { _ = os.Chdir(curDir) }()
+	restoreDir, err := changeDir(projPath)
+	if err != nil {
+		return err
 	}
+	defer restoreDir()
+
+	manifestLock, err := manifesto.LockLocalManifesto(".")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot lock the modules.json file: %s", err.Error()))
+		return err
+	}
+	defer manifestLock.Unlock()
 
 	manifest, err := c.getLocalManifest()
 	if err != nil {
@@ -147,7 +167,7 @@ func (c *Install) Invoke(
 		return err
 	}
 
-	entrypoints, err := c.getEntrypoints()
+	entrypoints, err := getEntrypoints(".")
 	if err != nil {
 		fmt.Println(color.RedString("Cannot get the entrypoints: %s", err.Error()))
 		return err
@@ -162,34 +182,86 @@ func (c *Install) Invoke(
 		)
 		return nil
 	}
-	hasErrors := false
+	allEntries := entrypointNames(entrypoints)
+	entryNames, err := flag.EntriesValue(ctx, allEntries, len(modulesValue) == 0)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot choose the entrypoints: %s", err.Error()))
+		return err
+	}
+	entrypoints = filterEntrypoints(entrypoints, entryNames)
+
+	lock, err := manifesto.LoadLock(".")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the %s file: %s", manifesto.LockFile, err.Error()))
+		return err
+	}
+	cmdCtx := cache.WithOffline(ctx.Context, flag.OfflineValue(ctx))
+	versions, err := c.resolveVersions(cmdCtx, availableModulesManifest, manifest, modules, lock, ctx.Bool("frozen"))
+	if err != nil {
+		fmt.Println(color.RedString("Cannot resolve the versions of the modules: %s", err.Error()))
+		if errors.Hint(err) != "" {
+			fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
+		}
+		return err
+	}
+
+	jrnl, err := journal.Begin(".", "module install "+strings.Join(moduleNames(modules), ", "))
+	if err != nil {
+		fmt.Println(color.RedString("Cannot start the install journal: %s", err.Error()))
+		return err
+	}
+	err = c.snapshotProjectFiles(jrnl, entrypoints)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot save the project files to the install journal: %s", err.Error()))
+		c.rollback(jrnl)
+		return err
+	}
+
 	localModulesMap := make(map[string]struct{})
 	for _, md := range manifest.Modules {
 		localModulesMap[md.Package] = struct{}{}
 	}
 	for _, md := range modules {
-		err = c.installModule(ctx.Context, md, entrypoints, projPath)
+		locked := versions[md.Package]
+		err = c.installModule(cmdCtx, jrnl, md, &locked, availableModulesManifest, entrypoints, projPath)
 		if err != nil {
 			fmt.Println(color.RedString("Cannot install the module %s: %s", md.Name, err.Error()))
 			if errors.Hint(err) != "" {
 				fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
 			}
-			hasErrors = true
-			continue
+			c.rollback(jrnl)
+			return err
 		}
-		if _, ok := localModulesMap[md.Package]; !ok {
-			manifest.Modules = append(manifest.Modules, md)
-
-			err = manifest.SaveAsLocalManifest("./")
+		if locked.Version == "" {
+			locked.Version, err = c.installedVersion(cmdCtx, md.Package)
 			if err != nil {
-				fmt.Println(color.RedString("Cannot save the local manifest file modules.json: %s", err.Error()))
-				hasErrors = true
+				fmt.Println(color.YellowString("Cannot get the installed version of the package %s: %s", md.Package, err.Error()))
 			}
 		}
+		lock.Update(locked)
+		if _, ok := localModulesMap[md.Package]; !ok {
+			manifest.Modules = append(manifest.Modules, md)
+		} else if _, ok := manifest.ModuleEntries[md.Package]; !ok {
+			// the module installed before is wired into all entrypoints
+			manifest.WireModule(md.Package, allEntries)
+		}
+		manifest.WireModule(md.Package, entryNames)
+		err = manifest.SaveAsLocalManifest("./")
+		if err != nil {
+			fmt.Println(color.RedString("Cannot save the local manifest file modules.json: %s", err.Error()))
+			c.rollback(jrnl)
+			return err
+		}
 	}
-	if hasErrors {
-		fmt.Println(color.YellowString("Some modules were not installed. Exiting..."))
-		return nil
+	err = lock.Save(".")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot save the %s file: %s", manifesto.LockFile, err.Error()))
+		c.rollback(jrnl)
+		return err
+	}
+	err = jrnl.Commit()
+	if err != nil {
+		fmt.Println(color.YellowString("Cannot save the install journal, the install cannot be undone: %s", err.Error()))
 	}
 	fmt.Println(
 		"Congratulations! Your project has been updated.",
@@ -198,13 +270,70 @@ func (c *Install) Invoke(
 	return nil
 }
 
+// snapshotProjectFiles saves the files changed by any module installation to the journal
+func (c *Install) snapshotProjectFiles(jrnl *journal.Journal, entrypoints []entripoint) error {
+	paths := []string{"modules.json", manifesto.LockFile, "go.mod", "go.sum", ".env"}
+	for _, entrypoint := range entrypoints {
+		paths = append(paths, entrypoint.path)
+	}
+	for _, p := range paths {
+		err := jrnl.Snapshot(p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Install) rollback(jrnl *journal.Journal) {
+	fmt.Println(color.YellowString("Rolling back the changes..."))
+	err := jrnl.Rollback()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot roll back the changes: %s", err.Error()))
+		return
+	}
+	fmt.Println(color.YellowString("The project files are restored to the state before the install."))
+}
+
+func moduleNames(modules []module.Manifesto) []string {
+	names := make([]string, 0, len(modules))
+	for _, md := range modules {
+		names = append(names, md.Name)
+	}
+	return names
+}
+
+// changeDir changes the current directory to the project one if it is set.
+// Returns a function to return back to the previous directory.
+func changeDir(projPath string) (func(), error) {
+	if projPath == "" {
+		return func() {}, nil
+	}
+	curDir, err := os.Getwd()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the current directory: %s", err.Error()))
+		return nil, err
+	}
+	err = os.Chdir(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot change the current directory to %s: %s", projPath, err.Error()))
+		return nil, err
+	}
+	curDirAfterChange, _ := os.Getwd()
+	fmt.Printf("Changing the current dir to %s\n", color.BlueString(curDirAfterChange))
+	return func() { _ = os.Chdir(curDir) }, nil
+}
+
 func (c *Install) getLocalManifest() (*manifesto.LocalManifesto, error) {
 	return manifesto.LoadLocalManifesto(".")
 }
 
 func (c *Install) installModule(
 	ctx context.Context,
+	jrnl *journal.Journal,
 	md module.Manifesto,
+	locked *manifesto.LockedModule,
+	available *manifesto.LocalManifesto,
 	entrypoints []entripoint,
 	projPath string,
 ) error {
@@ -213,39 +342,35 @@ func (c *Install) installModule(
 		return ErrPackageIsEmpty
 	}
 
-	fmt.Printf("Getting a package %s...\n", color.BlueString(md.Package))
+	pckg := md.Package
+	if locked.Version != "" {
+		pckg += "@" + locked.Version
+	}
+	fmt.Printf("Getting a package %s...\n", color.BlueString(pckg))
 	cmdCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
-	err := exec.CommandContext(cmdCtx, "go", "get", md.Package).Run()
+	err := fsys.Run(newGoCommand(cmdCtx, "get", pckg))
 	if err != nil {
 		return errors.WithCause(ErrCannotRunGoGetCommand, err)
 	}
 
-	for _, entrypoint := range entrypoints {
-		fmt.Printf("Adding module initialization to the entrypoint %s ...\n", color.BlueString(entrypoint.name))
-		err = files.AddModuleToEntrypoint(md.Package, entrypoint.path)
-		if err != nil {
-			fmt.Println(
-				color.RedString(
-					"Cannot add the module %s to the entrypoint %s: %s. Try to type initialization code manually",
-					color.BlueString(md.Name),
-					color.BlueString(entrypoint.path),
-					err.Error(),
-				),
-			)
-			continue
-		}
-		fmt.Printf("File %s is updated\n", color.BlueString(entrypoint.path))
+	err = addModuleToEntrypoints(md.Package, entrypoints)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("Running %s...\n", color.BlueString("go mod tidy"))
-	err = exec.CommandContext(cmdCtx, "go", "mod", "tidy").Run()
+	err = fsys.Run(newGoCommand(cmdCtx, "mod", "tidy"))
 	if err != nil {
 		return errors.WithCause(ErrCannotRunGoGetCommand, err)
 	}
 
 	if len(md.Install.EnvVars) != 0 {
-		err = module.WriteEnvVariablesToFile(md.Install.EnvVars, ".env")
+		err = fsys.ApplyToCopy(
+			".env", func(filename string) error {
+				return module.WriteEnvVariablesToFile(md.Install.EnvVars, filename)
+			},
+		)
 		if err != nil {
 			fmt.Println("Cannot update the .env file:", color.RedString(err.Error()))
 			return err
@@ -255,95 +380,127 @@ func (c *Install) installModule(
 		fmt.Println("Downloading the files...")
 		for _, file := range md.Install.Files {
 			fmt.Printf("Downloading the file %s...\n", color.BlueString(file.SourceUrl))
-			err = c.copyRemoteFile(md, file)
+			err = jrnl.Snapshot(file.DestFile)
+			if err != nil {
+				return err
+			}
+			checksum, err := c.copyRemoteFile(cmdCtx, md, file, available)
 			if err != nil {
 				fmt.Println("Cannot download the file:", color.RedString(err.Error()))
+				if errors.Hint(err) != "" {
+					fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
+				}
 				return err
 			}
+			if checksum != "" {
+				locked.Files = append(locked.Files, manifesto.LockedFile{Path: file.DestFile, Sha256: checksum})
+			}
 		}
 	}
 	if md.LocalPath != "" {
 		fmt.Println("Initializing the local module...")
-		projPackage, err := c.getProjPackage()
+		projPackage, err := utils.ProjectPackage(".")
 		if err != nil {
 			return err
 		}
 		localModulePackage := projPackage + "/" + md.LocalPath
 		mdPath := md.ModulePath(".")
 		if !utils.DirExists(mdPath) {
-			err = os.MkdirAll(mdPath, 0755)
+			err = fsys.MkdirAll(mdPath, 0755)
 			if err != nil {
 				fmt.Println("Cannot create the module directory:", color.RedString(err.Error()))
 			}
 		}
 		if utils.FileExists(mdPath + "/module.go") {
-			for _, entrypoint := range entrypoints {
-				fmt.Printf("Adding module initialization to the entrypoint %s ...\n", color.BlueString(entrypoint.name))
-				err = files.AddModuleToEntrypoint(localModulePackage, entrypoint.path)
-				if err != nil {
-					fmt.Println(
-						color.RedString(
-							"Cannot add the module %s to the entrypoint %s: %s. Try to type initialization code manually",
-							color.BlueString(md.Name),
-							color.BlueString(entrypoint.path),
-							err.Error(),
-						),
-					)
-					continue
-				}
-				fmt.Printf("File %s is updated\n", color.BlueString(entrypoint.path))
+			err = addModuleToEntrypoints(localModulePackage, entrypoints)
+			if err != nil {
+				return err
 			}
 		}
 	}
-	if len(md.Install.PostInstallCommands) != 0 {
-		fmt.Printf("Running the post install commands for the module %s...\n", color.BlueString(md.Name))
-		for _, cmd := range md.Install.PostInstallCommands {
-			fmt.Printf("Adding the package %s to the tools.go file...\n", color.BlueString(md.Package))
+	err = c.runPostInstallCommands(cmdCtx, md)
+	if err != nil {
+		return err
+	}
 
-			runPckg := cmd.CmdPackage
-			if !strings.Contains(cmd.CmdPackage, "@") {
-				runPckg = cmd.CmdPackage + "@latest"
-			}
+	fmt.Println(color.GreenString("The module %s has been successfully installed.", color.BlueString(md.Name)))
+	return nil
+}
 
-			fmt.Printf("Running %s...\n", color.BlueString("go run "+runPckg))
-			params := append([]string{"run", runPckg}, cmd.Params...)
-			err = exec.CommandContext(cmdCtx, "go", params...).Run()
-			if err != nil {
-				return errors.WithCause(ErrCannotInstallModule, err)
-			}
-		}
+func (c *Install) runPostInstallCommands(
+	ctx context.Context,
+	md module.Manifesto,
+) error {
+	if len(md.Install.PostInstallCommands) == 0 {
+		return nil
 	}
+	fmt.Printf("Running the post install commands for the module %s...\n", color.BlueString(md.Name))
+	for _, cmd := range md.Install.PostInstallCommands {
+		fmt.Printf("Adding the package %s to the tools.go file...\n", color.BlueString(md.Package))
 
-	fmt.Println(color.GreenString("The module %s has been successfully installed.", color.BlueString(md.Name)))
+		runPckg := cmd.CmdPackage
+		if !strings.Contains(cmd.CmdPackage, "@") {
+			runPckg = cmd.CmdPackage + "@latest"
+		}
+
+		fmt.Printf("Running %s...\n", color.BlueString("go run "+runPckg))
+		params := append([]string{"run", runPckg}, cmd.Params...)
+		err := fsys.Run(newGoCommand(ctx, params...))
+		if err != nil {
+			return errors.WithCause(ErrCannotInstallModule, err)
+		}
+	}
 	return nil
 }
 
+// copyRemoteFile downloads the file, renders it as a template and writes to the destination path.
+// The file is downloaded with the timeout and credentials of the registry the module is taken from.
+// The downloaded content is checked against the sha256 checksum from the registry manifest if it is declared.
+// Returns the sha256 checksum of the written file or an empty string if the existing file is kept.
 func (c *Install) copyRemoteFile(
+	ctx context.Context,
 	md module.Manifesto,
 	file module.InstalledFile,
-) error {
+	available *manifesto.LocalManifesto,
+) (string, error) {
 	if utils.FileExists(file.DestFile) {
 		fmt.Println(
 			color.YellowString("The file"),
 			color.BlueString(file.DestFile),
 			color.YellowString("already exists. Skipping..."),
 		)
-		return nil
-	}
-	//download file
-	resp, err := http.Get(file.SourceUrl)
-	defer func() { _ = resp.Body.Close() }()
+		return "", nil
+	}
+	//download file through the cache to be able to install the module offline
+	req, client, err := flag.NewRegistryRequest(
+		ctx,
+		available.ModuleRegistries[md.Package],
+		file.SourceUrl,
+		cache.IsOffline(ctx),
+	)
 	if err != nil {
-		return err
+		return "", err
 	}
-
-	var rb bytes.Buffer
-	_, err = rb.ReadFrom(resp.Body)
+	content, err := c.cache.Fetch(client, req, cache.IsOffline(ctx))
 	if err != nil {
-		fmt.Println("Cannot read the response body:", color.RedString(err.Error()))
-		return err
+		return "", err
 	}
-	tpl := `{{define "main"}}` + rb.String() + `{{end}}`
+	expectedChecksum := available.FileChecksums[file.SourceUrl]
+	if expectedChecksum != "" {
+		checksum := utils.Sha256(content)
+		if checksum != expectedChecksum {
+			fmt.Println(
+				color.RedString(
+					"The checksum of the file %s is %s, expected %s",
+					file.SourceUrl,
+					checksum,
+					expectedChecksum,
+				),
+			)
+			return "", ErrChecksumMismatch
+		}
+	}
+	tpl := `{{define "main"}}` + string(content) + `{{end}}`
 
 	tmpl := template.Must(
 		template.New("main").
@@ -352,9 +509,9 @@ func (c *Install) copyRemoteFile(
 			),
 	)
 
-	projPackage, err := c.getProjPackage()
+	projPackage, err := utils.ProjectPackage(".")
 	if err != nil {
-		return err
+		return "", err
 	}
 	var b bytes.Buffer
 	w := bufio.NewWriter(&b)
@@ -364,11 +521,11 @@ func (c *Install) copyRemoteFile(
 	}
 	err = tmpl.ExecuteTemplate(w, "main", &vars)
 	if err != nil {
-		return err
+		return "", err
 	}
 	err = w.Flush()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	dir := path.Dir(file.DestFile)
@@ -376,70 +533,32 @@ func (c *Install) copyRemoteFile(
 		fmt.Println("Preparing directory for the file...")
 		err = utils.CreateDirIfNotExists(dir)
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 
-	err = os.WriteFile(file.DestFile, b.Bytes(), 0644)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (c *Install) getProjPackage() (string, error) {
-	if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
-		fmt.Println(color.RedString("The go.mod file is not found. Try to run the command in the root of the project"))
-		return "", err
-	}
-	content, err := os.ReadFile("go.mod")
+	err = fsys.WriteFile(file.DestFile, b.Bytes(), 0644)
 	if err != nil {
-		fmt.Println(color.RedString("Cannot read a go.mod file: %s", err.Error()))
 		return "", err
 	}
-
-	moduleStr := moduleNameRegexp.FindStringSubmatch(string(content))
-	if len(moduleStr) < 2 {
-		fmt.Println(color.RedString("Cannot find a module name in the go.mod file"))
-		return "", errors.New("cannot find a module name in the go.mod file")
-	}
-
-	return moduleStr[1], nil
+	return utils.Sha256(b.Bytes()), nil
 }
 
+// addDependedModulesToInstall adds all not installed transitive dependencies to the modules to install
+// and sorts the result so that every module is installed after its dependencies.
 func (c *Install) addDependedModulesToInstall(
 	availableModulesManifest *manifesto.LocalManifesto,
 	installedModules []module.Manifesto,
 	modulesToInstall []module.Manifesto,
 ) ([]module.Manifesto, error) {
-	addedModules := make([]module.Manifesto, 0)
-	addedModulesMap := make(map[string]struct{})
-	availableModulesMap := make(map[string]module.Manifesto)
-	for _, md := range availableModulesManifest.Modules {
-		availableModulesMap[md.Name] = md
-	}
-	for _, md := range installedModules {
-		addedModulesMap[md.Name] = struct{}{}
-	}
-	for _, md := range modulesToInstall {
-		addedModulesMap[md.Package] = struct{}{}
+	res, err := manifesto.ResolveDependencies(availableModulesManifest, installedModules, modulesToInstall)
+	if err != nil {
+		return nil, err
 	}
-
-	for _, md := range modulesToInstall {
-		if len(md.Install.Dependencies) == 0 {
-			continue
-		}
-		for _, depName := range md.Install.Dependencies {
-			if availableDep, ok := availableModulesMap[depName]; ok {
-				if _, ok := addedModulesMap[availableDep.Name]; !ok {
-					addedModules = append(addedModules, availableDep)
-					addedModulesMap[availableDep.Name] = struct{}{}
-				}
-			}
-		}
+	if len(res) > len(modulesToInstall) {
+		fmt.Printf("Modules to install including dependencies: %s\n", color.BlueString(strings.Join(moduleNames(res), ", ")))
 	}
-
-	return append(modulesToInstall, addedModules...), nil
+	return res, nil
 }
 
 func (c *Install) askModulesFromManifest(
@@ -547,8 +666,27 @@ type entripoint struct {
 	path string
 }
 
-func (c *Install) getEntrypoints() (entripoints []entripoint, err error) {
-	cmdFolder := "./cmd"
+func entrypointNames(entrypoints []entripoint) []string {
+	names := make([]string, 0, len(entrypoints))
+	for _, entrypoint := range entrypoints {
+		names = append(names, entrypoint.name)
+	}
+	return names
+}
+
+// filterEntrypoints returns the entrypoints with the names
+func filterEntrypoints(entrypoints []entripoint, names []string) []entripoint {
+	res := make([]entripoint, 0, len(names))
+	for _, entrypoint := range entrypoints {
+		if slices.Contains(names, entrypoint.name) {
+			res = append(res, entrypoint)
+		}
+	}
+	return res
+}
+
+func getEntrypoints(projPath string) (entripoints []entripoint, err error) {
+	cmdFolder := projPath + "/cmd"
 	entries, err := os.ReadDir(cmdFolder)
 	if err != nil {
 		return
@@ -577,3 +715,16 @@ func (c *Install) getEntrypoints() (entripoints []entripoint, err error) {
 
 	return
 }
+
+// addModuleToEntrypoints adds the initialization of the module package to the entrypoints
+func addModuleToEntrypoints(packagePath string, entrypoints []entripoint) error {
+	for _, entrypoint := range entrypoints {
+		fmt.Printf("Adding module initialization to the entrypoint %s ...\n", color.BlueString(entrypoint.name))
+		err := files.AddModuleToEntrypoint(packagePath, entrypoint.path)
+		if err != nil {
+			return errors.WithCause(ErrCannotAddModuleToEntrypoint, fmt.Errorf("%s: %w", entrypoint.path, err))
+		}
+		fmt.Printf("File %s is updated\n", color.BlueString(entrypoint.path))
+	}
+	return nil
+}
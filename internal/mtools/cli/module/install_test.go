@@ -3,9 +3,14 @@ package module_test
 import (
 	"flag"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/module"
+	"github.com/go-modulus/mtools/internal/mtools/utils"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 )
@@ -319,4 +324,187 @@ func TestInstall_Invoke(t *testing.T) {
 			require.Contains(t, string(entrypointFileContent), "graphql2.NewModule()")
 		},
 	)
+
+	t.Run(
+		"install module saves the resolved version to the lock file", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.Var(cli.NewStringSlice("pgx"), "modules", "doc")
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = installModule.Invoke(ctx)
+
+			lockContent, errCont := os.ReadFile(fmt.Sprintf("%s/modules.lock.json", projDir))
+
+			t.Log("When install a new module to a project")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The lock file should be created with the installed module")
+			require.NoError(t, errCont)
+			require.Contains(t, string(lockContent), "github.com/go-modulus/modulus/db/pgx")
+			require.Contains(t, string(lockContent), "\"version\": \"v")
+		},
+	)
+
+	t.Run(
+		"fail in the frozen mode if the module is not locked", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.Var(cli.NewStringSlice("pgx"), "modules", "doc")
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			set.Bool("frozen", true, "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = installModule.Invoke(ctx)
+
+			modulesContent, errCont := os.ReadFile(fmt.Sprintf("%s/modules.json", projDir))
+
+			t.Log("When install a module absent in the lock file in the frozen mode")
+			t.Log("	The error should be returned")
+			require.ErrorIs(t, err, module.ErrLockIsOutdated)
+			t.Log("	The modules.json file should not be changed")
+			require.NoError(t, errCont)
+			require.NotContains(t, string(modulesContent), "github.com/go-modulus/modulus/db/pgx")
+		},
+	)
+}
+
+const remoteFileTemplate = "package config\n\n// {{.ModuleName}} config of {{.ProjectPackage}}\n"
+
+const remoteFileContent = "package config\n\n// slog logger config of testproj\n"
+
+// remoteFileModulesJson is a manifest with the module downloading a file from the %s URL with the %s sha256 checksum
+const remoteFileModulesJson = `{
+  "modules": [
+    {
+      "name": "slog logger",
+      "package": "github.com/go-modulus/modulus/logger",
+      "install": {
+        "files": [
+          {
+            "sourceUrl": "%s",
+            "destFile": "internal/config/config.go",
+            "sha256": "%s"
+          }
+        ]
+      },
+      "version": "1.0.0"
+    }
+  ]
+}`
+
+// newRemoteFileServer serves the remote file template with the status
+func newRemoteFileServer(t *testing.T, status int) *httptest.Server {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(remoteFileTemplate))
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func invokeRemoteFileInstall(t *testing.T, projDir string, sourceUrl string, checksum string) error {
+	createFile(t, projDir, "manifest/modules.json", fmt.Sprintf(remoteFileModulesJson, sourceUrl, checksum))
+	err := os.Chdir(projDir)
+	require.NoError(t, err)
+	app := cli.NewApp()
+	set := flag.NewFlagSet("test", 0)
+	set.Var(cli.NewStringSlice("slog logger"), "modules", "doc")
+	set.String("manifest", projDir+"/manifest/modules.json", "doc")
+	ctx := cli.NewContext(app, set, nil)
+	return installModule.Invoke(ctx)
+}
+
+func TestInstall_InvokeRemoteFiles(t *testing.T) {
+	t.Run(
+		"install the file matching the checksum", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+			server := newRemoteFileServer(t, http.StatusOK)
+			checksum := utils.Sha256([]byte(remoteFileTemplate))
+
+			err := invokeRemoteFileInstall(t, projDir, server.URL+"/config.go.tmpl", checksum)
+
+			fileContent, errFile := os.ReadFile(projDir + "/internal/config/config.go")
+			lock, errLock := manifesto.LoadLock(projDir)
+
+			t.Log("When install a module with the file which checksum matches the manifest")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The file should be rendered with the project variables")
+			require.NoError(t, errFile)
+			require.Equal(t, remoteFileContent, string(fileContent))
+			t.Log("	The checksum of the rendered file should be saved to the lock file")
+			require.NoError(t, errLock)
+			locked, ok := lock.Find("github.com/go-modulus/modulus/logger")
+			require.True(t, ok)
+			require.Equal(
+				t,
+				[]manifesto.LockedFile{
+					{Path: "internal/config/config.go", Sha256: utils.Sha256([]byte(remoteFileContent))},
+				},
+				locked.Files,
+			)
+		},
+	)
+
+	t.Run(
+		"refuse the file with the wrong checksum", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+			server := newRemoteFileServer(t, http.StatusOK)
+			checksum := utils.Sha256([]byte("another content"))
+
+			err := invokeRemoteFileInstall(t, projDir, server.URL+"/config.go.tmpl", checksum)
+
+			_, errFile := os.Stat(projDir + "/internal/config/config.go")
+			modulesContent, errModules := os.ReadFile(projDir + "/modules.json")
+
+			t.Log("When install a module with the file which checksum differs from the manifest")
+			t.Log("	The error should be ErrChecksumMismatch")
+			require.ErrorIs(t, err, module.ErrChecksumMismatch)
+			t.Log("	The file should not be written")
+			require.True(t, os.IsNotExist(errFile))
+			t.Log("	The module should not be added to the modules.json file")
+			require.NoError(t, errModules)
+			require.NotContains(t, string(modulesContent), "github.com/go-modulus/modulus/logger")
+		},
+	)
+
+	t.Run(
+		"refuse the file answered with the error status", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+			server := newRemoteFileServer(t, http.StatusNotFound)
+
+			err := invokeRemoteFileInstall(t, projDir, server.URL+"/config.go.tmpl", "")
+
+			_, errFile := os.Stat(projDir + "/internal/config/config.go")
+
+			t.Log("When install a module with the file the server answers with the 404 status")
+			t.Log("	The error should be returned")
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "404")
+			t.Log("	The error page should not be written to the file")
+			require.True(t, os.IsNotExist(errFile))
+		},
+	)
 }
@@ -0,0 +1,214 @@
+package module
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StatusInstalled = "installed"
+	StatusOutdated  = "outdated"
+	StatusLocal     = "local"
+	StatusAvailable = "available"
+)
+
+type ModuleListItem struct {
+	Name      string `json:"name" yaml:"name"`
+	Package   string `json:"package" yaml:"package"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Status    string `json:"status" yaml:"status"`
+	LocalPath string `json:"localPath,omitempty" yaml:"localPath,omitempty"`
+}
+
+type EntrypointListItem struct {
+	Name      string `json:"name" yaml:"name"`
+	LocalPath string `json:"localPath" yaml:"localPath"`
+}
+
+type ModuleList struct {
+	Modules     []ModuleListItem     `json:"modules" yaml:"modules"`
+	Entrypoints []EntrypointListItem `json:"entrypoints" yaml:"entrypoints"`
+}
+
+type List struct {
+	logger *slog.Logger
+}
+
+func NewList(
+	logger *slog.Logger,
+) *List {
+	return &List{
+		logger: logger,
+	}
+}
+
+func NewListCommand(list *List) *cli.Command {
+	return &cli.Command{
+		Name: "list",
+		Usage: `Shows the modules installed in the project, the local modules, the entrypoints and the modules available in the registry.
+A module is outdated if the registry manifest has a newer version of it than the one saved in the modules.json file.
+Example: mtools module list
+Example: mtools module list --format=json
+Example: mtools module list --format=yaml --manifest="local_folder/modules.json"
+`,
+		Action: list.Invoke,
+		Flags: []cli.Flag{
+			flag.NewFormat("An output format: table, json or yaml"),
+			flag.NewManifest(
+				`A path to the global manifest with all available modules.
+Example: mtools module list --manifest="local_folder/modules.json"`,
+			),
+		},
+	}
+}
+
+func (c *List) Invoke(
+	ctx *cli.Context,
+) error {
+	projPath := flag.ProjPathValue(ctx)
+	format, err := flag.FormatValue(ctx)
+	w := flag.MessagesWriter(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintln(w, color.RedString(err.Error()))
+		return err
+	}
+
+	manifest, err := manifesto.LoadLocalManifesto(projPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot get the local modules.json manifest file: %s", err.Error()))
+		return err
+	}
+	availableModulesManifest, err := flag.ManifestValue(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot get the manifest file: %s", err.Error()))
+		return err
+	}
+	entries, err := manifesto.ReadEntries(projPath)
+	if err != nil {
+		_, _ = fmt.Fprintln(w, color.RedString("Cannot get the entrypoints: %s", err.Error()))
+		return err
+	}
+
+	list := buildModuleList(manifest, availableModulesManifest, entries)
+	if format == flag.FormatTable {
+		printModuleList(ctx.App.Writer, list)
+		return nil
+	}
+	return writeFormatted(ctx.App.Writer, format, list)
+}
+
+// buildModuleList puts the installed modules first, then the local modules and then the not installed modules of the registry
+func buildModuleList(
+	local *manifesto.LocalManifesto,
+	available *manifesto.LocalManifesto,
+	entries []manifesto.Entrypoint,
+) ModuleList {
+	res := ModuleList{
+		Modules:     make([]ModuleListItem, 0, len(local.Modules)+len(available.Modules)),
+		Entrypoints: make([]EntrypointListItem, 0, len(entries)),
+	}
+	installed := make(map[string]struct{}, len(local.Modules))
+	for _, md := range local.Modules {
+		if md.IsLocalModule {
+			continue
+		}
+		installed[md.Package] = struct{}{}
+		status := StatusInstalled
+		if availableMd, ok := findByPackage(available.Modules, md.Package); ok &&
+			isNewerVersion(availableMd.Version, md.Version) {
+			status = StatusOutdated
+		}
+		res.Modules = append(res.Modules, newModuleListItem(md, status))
+	}
+	for _, md := range local.LocalModules() {
+		installed[md.Package] = struct{}{}
+		res.Modules = append(res.Modules, newModuleListItem(md, StatusLocal))
+	}
+	for _, md := range available.Modules {
+		if _, ok := installed[md.Package]; ok {
+			continue
+		}
+		res.Modules = append(res.Modules, newModuleListItem(md, StatusAvailable))
+	}
+	for _, entry := range entries {
+		res.Entrypoints = append(
+			res.Entrypoints, EntrypointListItem{
+				Name:      entry.Name,
+				LocalPath: entry.LocalPath,
+			},
+		)
+	}
+	return res
+}
+
+func newModuleListItem(md module.Manifesto, status string) ModuleListItem {
+	return ModuleListItem{
+		Name:      md.Name,
+		Package:   md.Package,
+		Version:   md.Version,
+		Status:    status,
+		LocalPath: md.LocalPath,
+	}
+}
+
+func printModuleList(out io.Writer, list ModuleList) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "MODULE\tPACKAGE\tVERSION\tSTATUS")
+	for _, item := range list.Modules {
+		_, _ = fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\n",
+			item.Name,
+			item.Package,
+			versionOrDash(item.Version),
+			colorStatus(item.Status),
+		)
+	}
+	_ = w.Flush()
+
+	_, _ = fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ENTRYPOINT\tPATH")
+	for _, item := range list.Entrypoints {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", item.Name, item.LocalPath)
+	}
+	_ = w.Flush()
+}
+
+func colorStatus(status string) string {
+	switch status {
+	case StatusInstalled:
+		return color.GreenString(status)
+	case StatusOutdated:
+		return color.YellowString(status)
+	case StatusLocal:
+		return color.BlueString(status)
+	}
+	return status
+}
+
+// writeFormatted writes the value in the json or yaml format
+func writeFormatted(out io.Writer, format string, value any) error {
+	if format == flag.FormatYaml {
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(2)
+		err := enc.Encode(value)
+		if err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
@@ -0,0 +1,109 @@
+package module_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/cli/module"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func TestList_Invoke(t *testing.T) {
+	t.Run(
+		"list modules in json format", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			app := cli.NewApp()
+			app.Writer = out
+			set := flag.NewFlagSet("test", 0)
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			set.String("proj-path", projDir, "doc")
+			set.String("format", "json", "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = listModules.Invoke(ctx)
+
+			list := module.ModuleList{}
+			errJson := json.Unmarshal(out.Bytes(), &list)
+			statuses := make(map[string]string)
+			for _, item := range list.Modules {
+				statuses[item.Name] = item.Status
+			}
+
+			t.Log("When list the modules of a project in the json format")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The output should be a valid json")
+			require.NoError(t, errJson)
+			t.Log("	The installed module should have the installed status")
+			require.Equal(t, module.StatusInstalled, statuses["urfave cli"])
+			t.Log("	The not installed module of the registry should have the available status")
+			require.Equal(t, module.StatusAvailable, statuses["pgx"])
+			t.Log("	The entrypoints of the project should be listed")
+			require.Len(t, list.Entrypoints, 1)
+			require.Equal(t, "console", list.Entrypoints[0].Name)
+			require.Equal(t, "cmd/console/main.go", list.Entrypoints[0].LocalPath)
+		},
+	)
+
+	t.Run(
+		"list modules in yaml format", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			app := cli.NewApp()
+			app.Writer = out
+			set := flag.NewFlagSet("test", 0)
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			set.String("proj-path", projDir, "doc")
+			set.String("format", "yaml", "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = listModules.Invoke(ctx)
+
+			list := module.ModuleList{}
+			errYaml := yaml.Unmarshal(out.Bytes(), &list)
+
+			t.Log("When list the modules of a project in the yaml format")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The output should be a valid yaml")
+			require.NoError(t, errYaml)
+			require.NotEmpty(t, list.Modules)
+		},
+	)
+
+	t.Run(
+		"fail on unknown format", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			set.String("proj-path", projDir, "doc")
+			set.String("format", "xml", "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err := listModules.Invoke(ctx)
+
+			t.Log("When list the modules in an unknown format")
+			t.Log("	The error should be returned")
+			require.Error(t, err)
+		},
+	)
+}
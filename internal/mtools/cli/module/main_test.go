@@ -11,9 +11,13 @@ import (
 )
 
 var (
-	installModule *module.Install
-	createModule  *module.Create
-	addJsonApi    *module.AddJsonApi
+	installModule   *module.Install
+	uninstallModule *module.Uninstall
+	upgradeModule   *module.Upgrade
+	listModules     *module.List
+	moduleInfo      *module.Info
+	createModule    *module.Create
+	addJsonApi      *module.AddJsonApi
 )
 
 func TestMain(m *testing.M) {
@@ -28,6 +32,10 @@ func TestMain(m *testing.M) {
 		module2.BuildFx(currentModule),
 		fx.Populate(
 			&installModule,
+			&uninstallModule,
+			&upgradeModule,
+			&listModules,
+			&moduleInfo,
 			&createModule,
 			&addJsonApi,
 		),
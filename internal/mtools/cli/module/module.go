@@ -1,10 +1,17 @@
 package module
 
-import "github.com/urfave/cli/v2"
+import (
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/urfave/cli/v2"
+)
 
 func NewModuleCommand(
 	create *Create,
 	install *Install,
+	uninstall *Uninstall,
+	upgrade *Upgrade,
+	list *List,
+	info *Info,
 	addCli *AddCli,
 	addJsonApi *AddJsonApi,
 ) *cli.Command {
@@ -13,9 +20,15 @@ func NewModuleCommand(
 		Usage: `A set of commands for modules manipulations.
 Example: mtools module
 `,
+		Before: flag.DryRunBefore,
+		After:  flag.DryRunAfter,
 		Subcommands: []*cli.Command{
 			NewCreateCommand(create),
 			NewInstallCommand(install),
+			NewUninstallCommand(uninstall),
+			NewUpgradeCommand(upgrade),
+			NewListCommand(list),
+			NewInfoCommand(info),
 			NewAddCliCommand(addCli),
 			NewAddJsonApiCommand(addJsonApi),
 		},
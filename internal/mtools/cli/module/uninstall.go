@@ -0,0 +1,352 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cache"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/files"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/go-modulus/mtools/internal/mtools/utils"
+	"github.com/manifoldco/promptui"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrModuleIsNotInstalled = errbuilder.New("module is not installed").
+	WithHint("Please check the name of the module in the modules.json file of the project.").Build()
+var ErrModuleHasDependents = errbuilder.New("module is required by other installed modules").
+	WithHint("Uninstall the dependent modules first or run the command with the --cascade flag.").Build()
+var ErrCannotRunGoModTidyCommand = errbuilder.New("cannot run go mod tidy command").Build()
+
+type Uninstall struct {
+	logger *slog.Logger
+}
+
+func NewUninstall(
+	logger *slog.Logger,
+) *Uninstall {
+	return &Uninstall{
+		logger: logger,
+	}
+}
+
+func NewUninstallCommand(uninstall *Uninstall) *cli.Command {
+	return &cli.Command{
+		Name: "uninstall",
+		Usage: `Removes the installed modules from the project.
+Reverts the changes made by the install command: removes the module from the modules.json file and from the entrypoints,
+removes its env variables from the .env file and runs go mod tidy.
+Example: mtools module uninstall
+Example without UI: mtools module uninstall --modules="pgx" --silent
+Example with removing the dependent modules: mtools module uninstall --modules="pgx" --cascade
+Example with removing the downloaded files: mtools module uninstall --modules="gqlgen" --delete-files
+`,
+		Action: uninstall.Invoke,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "modules",
+				Usage:   "A comma-separated list of modules names to remove from the project",
+				Aliases: []string{"m"},
+			},
+			&cli.BoolFlag{
+				Name:  "cascade",
+				Usage: "Uninstall the modules that depend on the removed ones as well",
+			},
+			&cli.BoolFlag{
+				Name:  "delete-files",
+				Usage: "Delete the files downloaded during the module installation",
+			},
+			flag.NewSilent("Do not ask for any input"),
+		},
+	}
+}
+
+func (c *Uninstall) Invoke(
+	ctx *cli.Context,
+) error {
+	projPath := flag.ProjPathValue(ctx)
+	isSilent := flag.SilentValue(ctx)
+
+	manifestLock, err := manifesto.LockLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot lock the modules.json file: %s", err.Error()))
+		return err
+	}
+	defer manifestLock.Unlock()
+
+	manifest, err := manifesto.LoadLocalManifesto(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the local modules.json manifest file: %s", err.Error()))
+		return err
+	}
+
+	modulesValue := ctx.StringSlice("modules")
+	if len(modulesValue) == 0 {
+		if isSilent {
+			fmt.Println(color.RedString("The modules to uninstall are required. Use the --modules flag"))
+			return errors.New("modules are not provided")
+		}
+		name, err := c.askModuleName(manifest)
+		if err != nil {
+			return err
+		}
+		modulesValue = []string{name}
+	}
+
+	modules := make([]module.Manifesto, 0, len(modulesValue))
+	for _, val := range modulesValue {
+		md, ok := manifest.FindModule(val)
+		if !ok {
+			fmt.Println(
+				color.RedString("The module"),
+				color.BlueString(val),
+				color.RedString("is not found in the local manifest file"),
+				color.BlueString("%s/modules.json", projPath),
+			)
+			return ErrModuleIsNotInstalled
+		}
+		modules = append(modules, md)
+	}
+
+	modules, err = c.addDependentModules(manifest, modules, ctx.Bool("cascade"), isSilent)
+	if err != nil {
+		if errors.Hint(err) != "" {
+			fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
+		}
+		return err
+	}
+
+	entrypoints, err := getEntrypoints(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the entrypoints: %s", err.Error()))
+		return err
+	}
+
+	lock, err := manifesto.LoadLock(projPath)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the %s file: %s", manifesto.LockFile, err.Error()))
+		return err
+	}
+
+	for _, md := range modules {
+		err = c.uninstallModule(md, manifest, entrypoints, projPath, ctx.Bool("delete-files"))
+		if err != nil {
+			fmt.Println(color.RedString("Cannot uninstall the module %s: %s", md.Name, err.Error()))
+			return err
+		}
+		manifest.RemoveModule(md.Package)
+		err = manifest.SaveAsLocalManifest(projPath)
+		if err != nil {
+			fmt.Println(color.RedString("Cannot save the local manifest file modules.json: %s", err.Error()))
+			return err
+		}
+		if lock.Remove(md.Package) {
+			err = lock.Save(projPath)
+			if err != nil {
+				fmt.Println(color.RedString("Cannot save the %s file: %s", manifesto.LockFile, err.Error()))
+				return err
+			}
+		}
+		fmt.Println(color.GreenString("The module %s has been successfully uninstalled.", color.BlueString(md.Name)))
+	}
+
+	fmt.Printf("Running %s...\n", color.BlueString("go mod tidy"))
+	cmdCtx, cancel := context.WithTimeout(cache.WithOffline(ctx.Context, flag.OfflineValue(ctx)), 5*time.Minute)
+	defer cancel()
+	cmd := newGoCommand(cmdCtx, "mod", "tidy")
+	cmd.Dir = projPath
+	err = fsys.Run(cmd)
+	if err != nil {
+		return errors.WithCause(ErrCannotRunGoModTidyCommand, err)
+	}
+
+	fmt.Println(
+		"Congratulations! Your project has been updated.",
+	)
+	return nil
+}
+
+// addDependentModules adds all installed modules depending on the modules to uninstall.
+// The dependent modules go first in the result to be uninstalled before their dependencies.
+func (c *Uninstall) addDependentModules(
+	manifest *manifesto.LocalManifesto,
+	modulesToUninstall []module.Manifesto,
+	cascade bool,
+	isSilent bool,
+) ([]module.Manifesto, error) {
+	res := make([]module.Manifesto, 0, len(modulesToUninstall))
+	addedModulesMap := make(map[string]struct{})
+	for _, md := range modulesToUninstall {
+		addedModulesMap[md.Package] = struct{}{}
+	}
+
+	queue := append([]module.Manifesto{}, modulesToUninstall...)
+	for len(queue) > 0 {
+		md := queue[0]
+		queue = queue[1:]
+		res = append([]module.Manifesto{md}, res...)
+
+		dependents := make([]module.Manifesto, 0)
+		names := make([]string, 0)
+		for _, dep := range manifest.DependentModules(md.Name) {
+			if _, ok := addedModulesMap[dep.Package]; ok {
+				continue
+			}
+			dependents = append(dependents, dep)
+			names = append(names, dep.Name)
+		}
+		if len(dependents) == 0 {
+			continue
+		}
+
+		fmt.Printf(
+			"The module %s is required by the installed modules: %s\n",
+			color.BlueString(md.Name),
+			color.BlueString(strings.Join(names, ", ")),
+		)
+		if !cascade {
+			if isSilent {
+				return nil, ErrModuleHasDependents
+			}
+			ok, err := c.askYesNo("Do you want to uninstall them too?")
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, ErrModuleHasDependents
+			}
+		}
+		for _, dep := range dependents {
+			addedModulesMap[dep.Package] = struct{}{}
+			queue = append(queue, dep)
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Uninstall) uninstallModule(
+	md module.Manifesto,
+	manifest *manifesto.LocalManifesto,
+	entrypoints []entripoint,
+	projPath string,
+	deleteFiles bool,
+) error {
+	fmt.Printf("Uninstalling the module %s...\n", color.BlueString(md.Name))
+
+	packages := []string{md.Package}
+	if md.LocalPath != "" && !md.IsLocalModule {
+		projPackage, err := utils.ProjectPackage(projPath)
+		if err != nil {
+			return err
+		}
+		packages = append(packages, projPackage+"/"+md.LocalPath)
+	}
+	for _, entrypoint := range entrypoints {
+		isUpdated := true
+		for _, pckg := range packages {
+			err := files.RemoveModuleFromEntrypoint(pckg, entrypoint.path)
+			if err != nil {
+				isUpdated = false
+				fmt.Println(
+					color.RedString(
+						"Cannot remove the module %s from the entrypoint %s: %s. Try to remove initialization code manually",
+						color.BlueString(md.Name),
+						color.BlueString(entrypoint.path),
+						err.Error(),
+					),
+				)
+			}
+		}
+		if isUpdated {
+			fmt.Printf("File %s is updated\n", color.BlueString(entrypoint.path))
+		}
+	}
+
+	// the variables declared by the remaining modules are kept
+	usedKeys := make(map[string]struct{})
+	for _, other := range manifest.Modules {
+		if other.Package == md.Package {
+			continue
+		}
+		for _, envVar := range other.Install.EnvVars {
+			usedKeys[envVar.Key] = struct{}{}
+		}
+	}
+	envVars := md.Install.EnvVars[:0:0]
+	for _, envVar := range md.Install.EnvVars {
+		if _, ok := usedKeys[envVar.Key]; !ok {
+			envVars = append(envVars, envVar)
+		}
+	}
+	if len(envVars) != 0 {
+		err := utils.RemoveEnvVariablesFromFile(envVars, projPath+"/.env")
+		if err != nil {
+			fmt.Println("Cannot update the .env file:", color.RedString(err.Error()))
+			return err
+		}
+	}
+
+	if deleteFiles && len(md.Install.Files) != 0 {
+		fmt.Println("Deleting the downloaded files...")
+		for _, file := range md.Install.Files {
+			filePath := projPath + "/" + file.DestFile
+			if !utils.FileExists(filePath) {
+				continue
+			}
+			err := fsys.Remove(filePath)
+			if err != nil {
+				fmt.Println("Cannot delete the file:", color.RedString(err.Error()))
+				return err
+			}
+			fmt.Printf("File %s is deleted\n", color.BlueString(file.DestFile))
+		}
+		if md.LocalPath != "" && !md.IsLocalModule {
+			// removes the module directory only if it is empty
+			_ = fsys.Remove(md.ModulePath(projPath))
+		}
+	}
+
+	return nil
+}
+
+func (c *Uninstall) askModuleName(manifest *manifesto.LocalManifesto) (string, error) {
+	items := make([]string, 0, len(manifest.Modules))
+	for _, md := range manifest.Modules {
+		items = append(items, md.Name)
+	}
+	sel := promptui.Select{
+		Label: "Select a module to uninstall",
+		Items: items,
+	}
+
+	_, val, err := sel.Run()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot ask module name: %s", err.Error()))
+		return "", err
+	}
+	return val, nil
+}
+
+func (c *Uninstall) askYesNo(label string) (bool, error) {
+	sel := promptui.Select{
+		Label: label,
+		Items: []string{"Yes", "No"},
+	}
+	_, result, err := sel.Run()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot ask a question: %s", err.Error()))
+		return false, err
+	}
+
+	return result == "Yes", nil
+}
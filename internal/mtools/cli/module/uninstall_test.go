@@ -0,0 +1,134 @@
+package module_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/cli/module"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func installModulesInTmpDir(projDir string, modules ...string) error {
+	app := cli.NewApp()
+	set := flag.NewFlagSet("test", 0)
+	set.Var(cli.NewStringSlice(modules...), "modules", "doc")
+	set.String("manifest", projDir+"/manifest/modules.json", "doc")
+	ctx := cli.NewContext(app, set, nil)
+	return installModule.Invoke(ctx)
+}
+
+func TestUninstall_Invoke(t *testing.T) {
+	t.Run(
+		"uninstall module", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+			err = installModulesInTmpDir(projDir, "pgx")
+			require.NoError(t, err)
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.Var(cli.NewStringSlice("pgx"), "modules", "doc")
+			set.String("proj-path", projDir, "doc")
+			set.Bool("silent", true, "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = uninstallModule.Invoke(ctx)
+
+			entrypointFileContent, errCont2 := os.ReadFile(fmt.Sprintf("%s/cmd/console/main.go", projDir))
+			envContent, errCont3 := os.ReadFile(fmt.Sprintf("%s/.env", projDir))
+			modulesContent, errCont4 := os.ReadFile(fmt.Sprintf("%s/modules.json", projDir))
+
+			t.Log("When uninstall an installed module from a project")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The module should be removed from the entrypoint file")
+			require.NoError(t, errCont2)
+			require.NotContains(t, string(entrypointFileContent), "github.com/go-modulus/modulus/db/pgx")
+			require.NotContains(t, string(entrypointFileContent), "pgx.NewModule()")
+			t.Log("	The env variables of the module should be removed from the .env file")
+			require.NoError(t, errCont3)
+			require.NotContains(t, string(envContent), "DB_NAME=test")
+			require.NotContains(t, string(envContent), "# Test comment")
+			t.Log("	The other env variables should not be removed")
+			require.Contains(t, string(envContent), "APP_ENV=local")
+			require.Contains(t, string(envContent), "PG_HOST=myhost")
+			t.Log("	The module should be removed from the modules.json file")
+			require.NoError(t, errCont4)
+			require.NotContains(t, string(modulesContent), "github.com/go-modulus/modulus/db/pgx")
+		},
+	)
+
+	t.Run(
+		"refuse to uninstall module required by other modules", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFullPathFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+			err = installModulesInTmpDir(projDir, "dbmate migrator")
+			require.NoError(t, err)
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.Var(cli.NewStringSlice("pgx"), "modules", "doc")
+			set.String("proj-path", projDir, "doc")
+			set.Bool("silent", true, "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = uninstallModule.Invoke(ctx)
+
+			modulesContent, errCont := os.ReadFile(fmt.Sprintf("%s/modules.json", projDir))
+
+			t.Log("When uninstall pgx that is required by the installed migrator")
+			t.Log("	The error should be returned")
+			require.ErrorIs(t, err, module.ErrModuleHasDependents)
+			t.Log("	The modules.json file should not be changed")
+			require.NoError(t, errCont)
+			require.Contains(t, string(modulesContent), "github.com/go-modulus/modulus/db/pgx")
+			require.Contains(t, string(modulesContent), "github.com/go-modulus/modulus/db/migrator")
+		},
+	)
+
+	t.Run(
+		"uninstall module with dependent modules in cascade", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFullPathFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+			err = installModulesInTmpDir(projDir, "dbmate migrator")
+			require.NoError(t, err)
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.Var(cli.NewStringSlice("pgx"), "modules", "doc")
+			set.String("proj-path", projDir, "doc")
+			set.Bool("silent", true, "doc")
+			set.Bool("cascade", true, "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = uninstallModule.Invoke(ctx)
+
+			entrypointFileContent, errCont2 := os.ReadFile(fmt.Sprintf("%s/cmd/console/main.go", projDir))
+			modulesContent, errCont4 := os.ReadFile(fmt.Sprintf("%s/modules.json", projDir))
+
+			t.Log("When uninstall pgx with the cascade flag")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	Both modules should be removed from the entrypoint file")
+			require.NoError(t, errCont2)
+			require.NotContains(t, string(entrypointFileContent), "pgx.NewModule()")
+			require.NotContains(t, string(entrypointFileContent), "migrator.NewModule()")
+			t.Log("	Both modules should be removed from the modules.json file")
+			require.NoError(t, errCont4)
+			require.NotContains(t, string(modulesContent), "github.com/go-modulus/modulus/db/pgx")
+			require.NotContains(t, string(modulesContent), "github.com/go-modulus/modulus/db/migrator")
+		},
+	)
+}
@@ -0,0 +1,401 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/go-modulus/mtools/internal/mtools/journal"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrUpgradeIsNotAvailableOffline = errbuilder.New("the new versions of the modules cannot be found in the offline mode").
+	WithHint("Run the upgrade command without the --offline flag.").Build()
+
+type Upgrade struct {
+	logger  *slog.Logger
+	install *Install
+}
+
+func NewUpgrade(
+	logger *slog.Logger,
+	install *Install,
+) *Upgrade {
+	return &Upgrade{
+		logger:  logger,
+		install: install,
+	}
+}
+
+func NewUpgradeCommand(upgrade *Upgrade) *cli.Command {
+	return &cli.Command{
+		Name: "upgrade",
+		Usage: `Upgrades the installed modules to the newest versions from the registry manifest matching the version constraints.
+Shows the installed and available versions of modules, gets the new versions of packages,
+adds the env variables and files introduced in the newer manifest and runs its new post install commands.
+Without the --module and --all flags only shows the table of versions.
+Example: mtools module upgrade
+Example: mtools module upgrade --module="pgx" --module="dbmate migrator"
+Example: mtools module upgrade --all
+`,
+		Action: upgrade.Invoke,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "module",
+				Usage:   "A name of the module to upgrade. Can be repeated",
+				Aliases: []string{"m"},
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Upgrade all outdated modules",
+			},
+			flag.NewManifest(
+				`A path to the global manifest with all available modules.
+Example: mtools module upgrade --manifest="local_folder/modules.json"`,
+			),
+		},
+	}
+}
+
+type upgradeItem struct {
+	installed module.Manifesto
+	available module.Manifesto
+	current   string
+	locked    manifesto.LockedModule
+}
+
+func (i upgradeItem) isOutdated() bool {
+	return isNewerVersion(i.locked.Version, i.current)
+}
+
+func (c *Upgrade) Invoke(
+	ctx *cli.Context,
+) error {
+	if flag.OfflineValue(ctx) {
+		fmt.Println(color.RedString("Cannot find the new versions of the modules: %s", ErrUpgradeIsNotAvailableOffline.Error()))
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(ErrUpgradeIsNotAvailableOffline)))
+		return ErrUpgradeIsNotAvailableOffline
+	}
+	availableModulesManifest, err := flag.ManifestValue(ctx)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the manifest file: %s", err.Error()))
+		return err
+	}
+	restoreDir, err := changeDir(flag.ProjPathValue(ctx))
+	if err != nil {
+		return err
+	}
+	defer restoreDir()
+
+	manifestLock, err := manifesto.LockLocalManifesto(".")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot lock the modules.json file: %s", err.Error()))
+		return err
+	}
+	defer manifestLock.Unlock()
+
+	manifest, err := manifesto.LoadLocalManifesto(".")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the local modules.json manifest file: %s", err.Error()))
+		return err
+	}
+	lock, err := manifesto.LoadLock(".")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the %s file: %s", manifesto.LockFile, err.Error()))
+		return err
+	}
+
+	items, err := c.getUpgradeItems(ctx.Context, availableModulesManifest, manifest, lock)
+	if err != nil {
+		if errors.Hint(err) != "" {
+			fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
+		}
+		return err
+	}
+	c.printVersions(items)
+
+	names := ctx.StringSlice("module")
+	isAll := ctx.Bool("all")
+	if len(names) == 0 && !isAll {
+		fmt.Printf(
+			"Use the %s or %s flag to upgrade the modules.\n",
+			color.BlueString("--module"),
+			color.BlueString("--all"),
+		)
+		return nil
+	}
+
+	toUpgrade := make([]upgradeItem, 0, len(items))
+	for _, item := range items {
+		if isAll || slices.ContainsFunc(
+			names, func(name string) bool {
+				return strings.EqualFold(name, item.installed.Name)
+			},
+		) {
+			if item.isOutdated() {
+				toUpgrade = append(toUpgrade, item)
+			}
+		}
+	}
+	for _, name := range names {
+		_, found := manifest.FindModule(name)
+		if !found {
+			fmt.Println(color.RedString("The module %s is not installed", name))
+			return ErrModuleIsNotInstalled
+		}
+	}
+	if len(toUpgrade) == 0 {
+		fmt.Println(color.GreenString("All chosen modules are up to date."))
+		return nil
+	}
+
+	entrypoints, err := getEntrypoints(".")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot get the entrypoints: %s", err.Error()))
+		return err
+	}
+	jrnl, err := journal.Begin(".", "module upgrade")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot start the upgrade journal: %s", err.Error()))
+		return err
+	}
+	err = c.install.snapshotProjectFiles(jrnl, entrypoints)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot save the project files to the upgrade journal: %s", err.Error()))
+		c.install.rollback(jrnl)
+		return err
+	}
+
+	for _, item := range toUpgrade {
+		err = c.upgradeModule(ctx.Context, jrnl, &item, availableModulesManifest)
+		if err != nil {
+			fmt.Println(color.RedString("Cannot upgrade the module %s: %s", item.installed.Name, err.Error()))
+			if errors.Hint(err) != "" {
+				fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
+			}
+			c.install.rollback(jrnl)
+			return err
+		}
+		manifest.UpdateModule(item.available)
+		lock.Update(item.locked)
+	}
+
+	err = manifest.SaveAsLocalManifest(".")
+	if err == nil {
+		err = lock.Save(".")
+	}
+	if err != nil {
+		fmt.Println(color.RedString("Cannot save the local manifest files: %s", err.Error()))
+		c.install.rollback(jrnl)
+		return err
+	}
+	err = jrnl.Commit()
+	if err != nil {
+		fmt.Println(color.YellowString("Cannot save the upgrade journal, the upgrade cannot be undone: %s", err.Error()))
+	}
+
+	fmt.Println(
+		"Congratulations! Your project has been updated.",
+	)
+	return nil
+}
+
+// getUpgradeItems compares the installed not local modules with the ones in the registry manifest
+func (c *Upgrade) getUpgradeItems(
+	ctx context.Context,
+	available *manifesto.LocalManifesto,
+	local *manifesto.LocalManifesto,
+	lock *manifesto.Lock,
+) ([]upgradeItem, error) {
+	items := make([]upgradeItem, 0, len(local.Modules))
+	registryModules := make([]module.Manifesto, 0, len(local.Modules))
+	for _, md := range local.Modules {
+		if md.IsLocalModule {
+			continue
+		}
+		availableMd, ok := findByPackage(available.Modules, md.Package)
+		if !ok {
+			fmt.Println(color.YellowString("The module %s is not found in the registry manifest. Skipping...", md.Name))
+			continue
+		}
+		current := ""
+		if locked, ok := lock.Find(md.Package); ok {
+			current = locked.Version
+		} else {
+			current, _ = c.install.installedVersion(ctx, md.Package)
+		}
+		items = append(
+			items, upgradeItem{
+				installed: md,
+				available: availableMd,
+				current:   current,
+			},
+		)
+		registryModules = append(registryModules, availableMd)
+	}
+
+	// an empty lock is passed to get the newest versions instead of the locked ones
+	versions, err := c.install.resolveVersions(ctx, available, local, registryModules, &manifesto.Lock{}, false)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		items[i].locked = versions[item.available.Package]
+		if lockedBefore, ok := lock.Find(item.available.Package); ok {
+			items[i].locked.Files = lockedBefore.Files
+		}
+		if items[i].locked.Version == "" {
+			items[i].locked.Version = item.current
+		}
+	}
+	return items, nil
+}
+
+func (c *Upgrade) printVersions(items []upgradeItem) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "MODULE\tPACKAGE\tCURRENT\tAVAILABLE\tSTATUS")
+	for _, item := range items {
+		status := color.GreenString("up to date")
+		if item.isOutdated() {
+			status = color.YellowString("outdated")
+		}
+		_, _ = fmt.Fprintf(
+			w,
+			"%s\t%s\t%s\t%s\t%s\n",
+			item.installed.Name,
+			item.installed.Package,
+			versionOrDash(item.current),
+			versionOrDash(item.locked.Version),
+			status,
+		)
+	}
+	_ = w.Flush()
+}
+
+func (c *Upgrade) upgradeModule(
+	ctx context.Context,
+	jrnl *journal.Journal,
+	item *upgradeItem,
+	available *manifesto.LocalManifesto,
+) error {
+	md := item.available
+	pckg := md.Package + "@" + item.locked.Version
+	fmt.Printf("Getting a package %s...\n", color.BlueString(pckg))
+	cmdCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	err := fsys.Run(newGoCommand(cmdCtx, "get", pckg))
+	if err != nil {
+		return errors.WithCause(ErrCannotRunGoGetCommand, err)
+	}
+
+	newEnvVars := md.Install.EnvVars[:0:0]
+	for _, envVar := range md.Install.EnvVars {
+		isNew := true
+		for _, old := range item.installed.Install.EnvVars {
+			if old.Key == envVar.Key {
+				isNew = false
+				break
+			}
+		}
+		if isNew {
+			newEnvVars = append(newEnvVars, envVar)
+		}
+	}
+	if len(newEnvVars) != 0 {
+		fmt.Println("Adding the new env variables...")
+		err = fsys.ApplyToCopy(
+			".env", func(filename string) error {
+				return module.WriteEnvVariablesToFile(newEnvVars, filename)
+			},
+		)
+		if err != nil {
+			fmt.Println("Cannot update the .env file:", color.RedString(err.Error()))
+			return err
+		}
+	}
+
+	for _, file := range md.Install.Files {
+		if slices.ContainsFunc(
+			item.installed.Install.Files, func(old module.InstalledFile) bool {
+				return old.DestFile == file.DestFile
+			},
+		) {
+			continue
+		}
+		fmt.Printf("Downloading the new file %s...\n", color.BlueString(file.SourceUrl))
+		err = jrnl.Snapshot(file.DestFile)
+		if err != nil {
+			return err
+		}
+		checksum, err := c.install.copyRemoteFile(cmdCtx, md, file, available)
+		if err != nil {
+			fmt.Println("Cannot download the file:", color.RedString(err.Error()))
+			return err
+		}
+		if checksum != "" {
+			item.locked.Files = append(item.locked.Files, manifesto.LockedFile{Path: file.DestFile, Sha256: checksum})
+		}
+	}
+
+	newCommandsMd := md
+	newCommandsMd.Install.PostInstallCommands = md.Install.PostInstallCommands[:0:0]
+	for _, cmd := range md.Install.PostInstallCommands {
+		isNew := true
+		for _, old := range item.installed.Install.PostInstallCommands {
+			if old.CmdPackage == cmd.CmdPackage && slices.Equal(old.Params, cmd.Params) {
+				isNew = false
+				break
+			}
+		}
+		if isNew {
+			newCommandsMd.Install.PostInstallCommands = append(newCommandsMd.Install.PostInstallCommands, cmd)
+		}
+	}
+	err = c.install.runPostInstallCommands(cmdCtx, newCommandsMd)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Running %s...\n", color.BlueString("go mod tidy"))
+	err = fsys.Run(newGoCommand(cmdCtx, "mod", "tidy"))
+	if err != nil {
+		return errors.WithCause(ErrCannotRunGoModTidyCommand, err)
+	}
+
+	fmt.Println(
+		color.GreenString(
+			"The module %s has been upgraded to %s.",
+			color.BlueString(md.Name),
+			color.BlueString(item.locked.Version),
+		),
+	)
+	return nil
+}
+
+func findByPackage(modules []module.Manifesto, pckg string) (module.Manifesto, bool) {
+	for _, md := range modules {
+		if md.Package == pckg {
+			return md, true
+		}
+	}
+	return module.Manifesto{}, false
+}
+
+func versionOrDash(version string) string {
+	if version == "" {
+		return "-"
+	}
+	return version
+}
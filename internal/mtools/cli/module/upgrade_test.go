@@ -0,0 +1,110 @@
+package module_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestUpgrade_Invoke(t *testing.T) {
+	t.Run(
+		"show versions without upgrading", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+			err = installModulesInTmpDir(projDir, "pgx")
+			require.NoError(t, err)
+			lockBefore, errCont1 := os.ReadFile(fmt.Sprintf("%s/%s", projDir, manifesto.LockFile))
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			set.String("proj-path", projDir, "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = upgradeModule.Invoke(ctx)
+
+			lockAfter, errCont2 := os.ReadFile(fmt.Sprintf("%s/%s", projDir, manifesto.LockFile))
+
+			t.Log("When run the upgrade command without the --module and --all flags")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The lock file should not be changed")
+			require.NoError(t, errCont1)
+			require.NoError(t, errCont2)
+			require.Equal(t, string(lockBefore), string(lockAfter))
+		},
+	)
+
+	t.Run(
+		"upgrade outdated module with new env variables", func(t *testing.T) {
+			projDir := "/tmp/testproj"
+			rb := initProject(t, projDir, goModFile)
+			defer rb()
+
+			err := os.Chdir(projDir)
+			require.NoError(t, err)
+			err = installModulesInTmpDir(projDir, "pgx")
+			require.NoError(t, err)
+
+			lock, err := manifesto.LoadLock(projDir)
+			require.NoError(t, err)
+			locked, ok := lock.Find("github.com/go-modulus/modulus/db/pgx")
+			require.True(t, ok)
+			locked.Version = "v0.0.1"
+			lock.Update(locked)
+			err = lock.Save(projDir)
+			require.NoError(t, err)
+
+			newManifest := strings.Replace(
+				availableModulesJson,
+				`"key": "DB_NAME",`,
+				`"key": "DB_POOL_SIZE",
+            "value": "10",
+            "comment": "Added in a new version"
+          },
+          {
+            "key": "DB_NAME",`,
+				1,
+			)
+			createFile(t, projDir+"/manifest", "modules.json", newManifest)
+
+			app := cli.NewApp()
+			set := flag.NewFlagSet("test", 0)
+			set.String("manifest", projDir+"/manifest/modules.json", "doc")
+			set.String("proj-path", projDir, "doc")
+			set.Var(cli.NewStringSlice("pgx"), "module", "doc")
+			ctx := cli.NewContext(app, set, nil)
+			err = upgradeModule.Invoke(ctx)
+
+			envContent, errCont1 := os.ReadFile(fmt.Sprintf("%s/.env", projDir))
+			modulesContent, errCont2 := os.ReadFile(fmt.Sprintf("%s/modules.json", projDir))
+			lockAfter, errCont3 := manifesto.LoadLock(projDir)
+
+			t.Log("When upgrade an outdated module which manifest has a new env variable")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The new env variable should be added to the .env file")
+			require.NoError(t, errCont1)
+			require.Contains(t, string(envContent), "DB_POOL_SIZE=10")
+			t.Log("	The existing env variable should not be duplicated")
+			require.Equal(t, 1, strings.Count(string(envContent), "DB_NAME="))
+			t.Log("	The local manifest should contain the new env variable")
+			require.NoError(t, errCont2)
+			require.Contains(t, string(modulesContent), "DB_POOL_SIZE")
+			t.Log("	The lock file should contain the new version")
+			require.NoError(t, errCont3)
+			lockedAfter, ok := lockAfter.Find("github.com/go-modulus/modulus/db/pgx")
+			require.True(t, ok)
+			require.NotEqual(t, "v0.0.1", lockedAfter.Version)
+		},
+	)
+}
@@ -0,0 +1,313 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cache"
+	"github.com/go-modulus/mtools/internal/mtools/semver"
+)
+
+var ErrCannotResolveVersion = errbuilder.New("cannot resolve the module version").
+	WithHint("Check the version constraints of the module in the manifests and the available versions of its package.").Build()
+var ErrLockIsOutdated = errbuilder.New("the modules.lock.json file does not match the manifests").
+	WithHint("Run the install command without the --frozen flag to update the lock file.").Build()
+var ErrConflictingConstraints = errbuilder.New("the version constraints of the modules from the same Go module cannot be satisfied together").
+	WithHint("go get installs one version of a Go module for all its packages. Relax the constraints of the modules so that they allow a common version.").Build()
+var ErrVersionIsNotLocked = errbuilder.New("the module version is not locked for the offline mode").
+	WithHint("Run the command without the --offline flag once to lock the versions in the modules.lock.json file and to download the modules.").Build()
+
+// goModuleGroup is the modules to install whose packages belong to the same Go module.
+// go get changes the version of the whole Go module, so they are resolved to one version.
+type goModuleGroup struct {
+	path     string
+	versions []string
+	modules  []module.Manifesto
+	locked   []manifesto.LockedModule
+	// constraints are the constraints of all packages of the Go module
+	constraints []semver.Constraint
+	// isPackage is true if the Go module of the package without tagged versions is unknown, the path is the package then
+	isPackage bool
+}
+
+// resolveVersions finds the exact version of every module to install.
+// The packages of the same Go module get one version matching the constraints of all of them.
+// The version from the lock file is used while it matches the constraints of the manifests.
+// In the frozen and offline modes only the versions from the lock file are allowed.
+// The version is empty if the package has no tagged versions, in this case the latest commit is installed.
+func (c *Install) resolveVersions(
+	ctx context.Context,
+	available *manifesto.LocalManifesto,
+	local *manifesto.LocalManifesto,
+	modules []module.Manifesto,
+	lock *manifesto.Lock,
+	frozen bool,
+) (map[string]manifesto.LockedModule, error) {
+	groups := make([]*goModuleGroup, 0)
+	for _, md := range modules {
+		constraints, constraintStrs, err := versionConstraints(md, available, local, modules)
+		if err != nil {
+			return nil, errors.WithCause(ErrCannotResolveVersion, err)
+		}
+		locked := manifesto.LockedModule{
+			Name:       md.Name,
+			Package:    md.Package,
+			Constraint: strings.Join(constraintStrs, "; "),
+		}
+		if lockedBefore, ok := lock.Find(md.Package); ok {
+			locked.GoModule = lockedBefore.GoModule
+		}
+
+		group := findGoModuleGroup(groups, md.Package)
+		if group == nil && locked.GoModule != "" {
+			group = findGoModuleGroup(groups, locked.GoModule)
+		}
+		if group == nil {
+			group = &goModuleGroup{path: locked.GoModule}
+			if group.path == "" && !frozen && !cache.IsOffline(ctx) {
+				fmt.Printf("Resolving a version of the package %s...\n", color.BlueString(md.Package))
+				group.path, group.versions = c.listVersions(ctx, md.Package)
+				if group.versions == nil {
+					group.versions = []string{}
+				}
+				if existing := findGoModuleGroup(groups, group.path); existing != nil {
+					group = existing
+				}
+			}
+			if group.path == "" {
+				// the package without tagged versions is resolved separately
+				group.path = md.Package
+				group.isPackage = true
+			}
+			if !slices.Contains(groups, group) {
+				groups = append(groups, group)
+			}
+		}
+		locked.GoModule = ""
+		if !group.isPackage {
+			locked.GoModule = group.path
+		}
+		group.modules = append(group.modules, md)
+		group.locked = append(group.locked, locked)
+		group.constraints = append(group.constraints, constraints...)
+	}
+
+	res := make(map[string]manifesto.LockedModule, len(modules))
+	for _, group := range groups {
+		version, err := c.resolveGroupVersion(ctx, group, lock, frozen)
+		if err != nil {
+			return nil, err
+		}
+		for _, locked := range group.locked {
+			locked.Version = version
+			res[locked.Package] = locked
+		}
+	}
+	return res, nil
+}
+
+// resolveGroupVersion returns one version for all packages of the Go module
+func (c *Install) resolveGroupVersion(
+	ctx context.Context,
+	group *goModuleGroup,
+	lock *manifesto.Lock,
+	frozen bool,
+) (string, error) {
+	lockedVersion, inLock := lock.FindGoModule(group.path)
+	if !inLock && len(group.locked) == 1 {
+		var lockedBefore manifesto.LockedModule
+		lockedBefore, inLock = lock.Find(group.locked[0].Package)
+		lockedVersion = lockedBefore.Version
+	}
+	if inLock && matchesConstraints(lockedVersion, group.constraints) {
+		return lockedVersion, nil
+	}
+	if frozen {
+		if inLock {
+			fmt.Println(
+				color.RedString(
+					"The locked version %s of the Go module %s does not match the constraints %s",
+					lockedVersion,
+					group.path,
+					group.constraintsString(),
+				),
+			)
+		} else {
+			fmt.Println(color.RedString("The module %s is not found in the lock file", group.modules[0].Name))
+		}
+		return "", ErrLockIsOutdated
+	}
+	if cache.IsOffline(ctx) {
+		fmt.Println(
+			color.RedString(
+				"Cannot resolve a version of the Go module %s matching the constraints %s without the network",
+				group.path,
+				group.constraintsString(),
+			),
+		)
+		return "", ErrVersionIsNotLocked
+	}
+
+	versions := group.versions
+	if versions == nil {
+		fmt.Printf("Resolving a version of the Go module %s...\n", color.BlueString(group.path))
+		_, versions = c.listVersions(ctx, group.modules[0].Package)
+	}
+	if len(versions) == 0 && len(group.constraints) == 0 {
+		return "", nil
+	}
+	version, ok := semver.Latest(versions, group.constraints...)
+	if ok {
+		return version, nil
+	}
+	if len(group.modules) > 1 {
+		fmt.Println(
+			color.RedString(
+				"The modules %s are in the same Go module %s, no its version matches all their constraints %s. Available versions: %s",
+				strings.Join(moduleNames(group.modules), ", "),
+				group.path,
+				group.constraintsString(),
+				strings.Join(versions, ", "),
+			),
+		)
+		return "", ErrConflictingConstraints
+	}
+	fmt.Println(
+		color.RedString(
+			"No version of the package %s matches the constraints %s. Available versions: %s",
+			group.modules[0].Package,
+			group.constraintsString(),
+			strings.Join(versions, ", "),
+		),
+	)
+	return "", ErrCannotResolveVersion
+}
+
+// constraintsString returns the constraints of the packages of the group, e.g. "pgx: ^0.5; logger: ^0.6"
+func (g *goModuleGroup) constraintsString() string {
+	parts := make([]string, 0, len(g.locked))
+	for _, locked := range g.locked {
+		if locked.Constraint == "" {
+			continue
+		}
+		if len(g.locked) == 1 {
+			return locked.Constraint
+		}
+		parts = append(parts, locked.Name+": "+locked.Constraint)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// findGoModuleGroup returns the group of the Go module containing the package
+func findGoModuleGroup(groups []*goModuleGroup, pckg string) *goModuleGroup {
+	if pckg == "" {
+		return nil
+	}
+	for _, group := range groups {
+		if pckg == group.path || strings.HasPrefix(pckg, group.path+"/") {
+			return group
+		}
+	}
+	return nil
+}
+
+// versionConstraints collects the constraints of the module from the available and local manifests
+// and from the dependencies declarations of the other modules in the "name@constraint" format
+func versionConstraints(
+	md module.Manifesto,
+	available *manifesto.LocalManifesto,
+	local *manifesto.LocalManifesto,
+	modules []module.Manifesto,
+) ([]semver.Constraint, []string, error) {
+	strs := make([]string, 0)
+	for _, m := range []*manifesto.LocalManifesto{available, local} {
+		if constraint, ok := m.Constraints[md.Name]; ok && constraint != "" {
+			strs = append(strs, constraint)
+		}
+	}
+	dependents := append(append([]module.Manifesto{}, modules...), local.Modules...)
+	for _, dependent := range dependents {
+		for _, dependency := range dependent.Install.Dependencies {
+			name, constraint := manifesto.SplitDependency(dependency)
+			if name == md.Name && constraint != "" {
+				strs = append(strs, constraint)
+			}
+		}
+	}
+
+	res := make([]semver.Constraint, 0, len(strs))
+	for _, str := range strs {
+		constraint, err := semver.ParseConstraint(str)
+		if err != nil {
+			return nil, nil, err
+		}
+		res = append(res, constraint)
+	}
+	return res, strs, nil
+}
+
+func matchesConstraints(version string, constraints []semver.Constraint) bool {
+	if version == "" {
+		return len(constraints) == 0
+	}
+	v, err := semver.Parse(version)
+	if err != nil {
+		// pseudo versions of untagged packages cannot be checked
+		return len(constraints) == 0
+	}
+	for _, constraint := range constraints {
+		if !constraint.Check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNewerVersion reports whether the available version is greater than the installed one.
+// It is the outdated check of both the module list and upgrade commands.
+// The versions that cannot be parsed, e.g. (devel), are never outdated to avoid a downgrade.
+func isNewerVersion(available string, installed string) bool {
+	availableVersion, err := semver.Parse(available)
+	if err != nil {
+		return false
+	}
+	installedVersion, err := semver.Parse(installed)
+	if err != nil {
+		return false
+	}
+	return availableVersion.Compare(installedVersion) > 0
+}
+
+// listVersions returns the path and the tagged versions of the Go module containing the package.
+// The module path is found by trimming the package path until the Go proxy knows the versions of it.
+// Returns an empty path and list if the module has no tagged versions.
+func (c *Install) listVersions(ctx context.Context, pckg string) (string, []string) {
+	for modPath := pckg; strings.Count(modPath, "/") >= 2; modPath = path.Dir(modPath) {
+		out, err := newGoCommand(ctx, "list", "-m", "-versions", modPath).Output()
+		if err != nil {
+			continue
+		}
+		fields := strings.Fields(string(out))
+		if len(fields) > 1 {
+			return modPath, fields[1:]
+		}
+	}
+	return "", nil
+}
+
+// installedVersion returns the version of the module containing the package from the go.mod file of the project
+func (c *Install) installedVersion(ctx context.Context, pckg string) (string, error) {
+	out, err := newGoCommand(ctx, "list", "-f", "{{if .Module}}{{.Module.Version}}{{end}}", pckg).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,224 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/manifoldco/promptui"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrModuleNameIsEmpty = errbuilder.New("module name is empty").
+	WithHint("Set the --name flag or run the command without the --silent flag").Build()
+var ErrModulePackageIsEmpty = errbuilder.New("module package is empty").
+	WithHint("Set the --package flag or run the command without the --silent flag").Build()
+var ErrInvalidEnvFlag = errbuilder.New("invalid env variable").
+	WithHint("Use the KEY=VALUE format, e.g. --env=PGX_DSN=postgres://localhost:5432/db").Build()
+var ErrInvalidFileFlag = errbuilder.New("invalid install file").
+	WithHint("Use the DEST=URL format, e.g. --files=internal/pgx/config.go=https://example.com/pgx/config.go").Build()
+
+type Add struct {
+}
+
+func NewAdd() *Add {
+	return &Add{}
+}
+
+func NewAddCommand(add *Add) *cli.Command {
+	return &cli.Command{
+		Name: "add",
+		Usage: `Add a module entry to the registry manifest. The other entries are kept as is.
+Example: mtools registry add
+Example without UI: mtools registry add --name=pgx --package=github.com/go-modulus/modulus/db/pgx --dependencies=logger --env=PGX_DSN=postgres://localhost:5432/db --silent
+`,
+		Action: add.Invoke,
+		Flags: []cli.Flag{
+			newRegistryFlag(),
+			&cli.StringFlag{
+				Name:    "name",
+				Usage:   "The name of the module used to install it, e.g. pgx",
+				Aliases: []string{"n"},
+			},
+			&cli.StringFlag{
+				Name:    "package",
+				Usage:   "The Go package of the module, e.g. github.com/go-modulus/modulus/db/pgx",
+				Aliases: []string{"pkg"},
+			},
+			&cli.StringFlag{
+				Name:    "description",
+				Usage:   "The description of the module",
+				Aliases: []string{"d"},
+			},
+			&cli.StringFlag{
+				Name:  "version",
+				Usage: "The version of the module",
+			},
+			&cli.StringFlag{
+				Name:  "local-path",
+				Usage: "The path inside the project the local files of the module are placed to, e.g. internal/pgx",
+			},
+			&cli.StringSliceFlag{
+				Name:  "dependencies",
+				Usage: "A comma-separated list of the names of the modules the module depends on with optional constraints, e.g. logger,pgx@^0.5.0",
+			},
+			&cli.StringSliceFlag{
+				Name:  "env",
+				Usage: "An env variable added to the .env file of the project in the KEY=VALUE format. Can be repeated",
+			},
+			&cli.StringSliceFlag{
+				Name:  "files",
+				Usage: "A file downloaded to the project in the DEST=URL format. Can be repeated",
+			},
+			&cli.StringSliceFlag{
+				Name:  "post-install",
+				Usage: `A command run after the installation in the "package param1 param2" format. Can be repeated`,
+			},
+			flag.NewSilent("Do not ask for any input"),
+		},
+	}
+}
+
+func (a *Add) Invoke(ctx *cli.Context) error {
+	filename := registryValue(ctx)
+	data, err := fsys.ReadFile(filename)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the registry %s: %s", filename, err.Error()))
+		fmt.Println(color.YellowString("Hint: run mtools registry init to create a new registry"))
+		return err
+	}
+
+	md, err := a.getModule(ctx, flag.SilentValue(ctx))
+	if err != nil {
+		printError("Cannot get the module", err)
+		return err
+	}
+	data, err = manifesto.AddRegistryModule(data, md)
+	if err != nil {
+		printError("Cannot add the module to the registry", err)
+		return err
+	}
+	err = fsys.WriteFile(filename, data, 0644)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot write the registry %s: %s", filename, err.Error()))
+		return err
+	}
+	fmt.Println(
+		color.GreenString("The module"),
+		color.BlueString(md.Name),
+		color.GreenString("is added to the registry"),
+		color.BlueString(filename),
+	)
+
+	registry := &manifesto.LocalManifesto{}
+	err = registry.ReadFromJSON(data)
+	if err != nil {
+		return err
+	}
+	for _, e := range manifesto.CheckRegistry(registry) {
+		fmt.Println(color.YellowString("Warning: %s", e.Error()))
+	}
+	return nil
+}
+
+func (a *Add) getModule(ctx *cli.Context, isSilent bool) (module.Manifesto, error) {
+	name, err := askValue(ctx.String("name"), "Enter a name of the module (e.g. pgx): ", isSilent, ErrModuleNameIsEmpty)
+	if err != nil {
+		return module.Manifesto{}, err
+	}
+	pckg, err := askValue(
+		ctx.String("package"),
+		"Enter a Go package of the module (e.g. github.com/go-modulus/modulus/db/pgx): ",
+		isSilent,
+		ErrModulePackageIsEmpty,
+	)
+	if err != nil {
+		return module.Manifesto{}, err
+	}
+	description := ctx.String("description")
+	if description == "" && !isSilent {
+		prompt := promptui.Prompt{
+			Label: "Enter a description of the module: ",
+		}
+		description, err = prompt.Run()
+		if err != nil {
+			return module.Manifesto{}, err
+		}
+	}
+
+	md := module.Manifesto{
+		Name:        name,
+		Package:     pckg,
+		Description: description,
+		Version:     ctx.String("version"),
+		LocalPath:   ctx.String("local-path"),
+	}
+	for _, dependency := range ctx.StringSlice("dependencies") {
+		if dependency = strings.TrimSpace(dependency); dependency != "" {
+			md.Install.Dependencies = append(md.Install.Dependencies, dependency)
+		}
+	}
+	for _, env := range ctx.StringSlice("env") {
+		key, value, ok := strings.Cut(env, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return module.Manifesto{}, errors.WithCause(ErrInvalidEnvFlag, fmt.Errorf("%q", env))
+		}
+		md.Install.EnvVars = append(md.Install.EnvVars, module.ConfigEnvVariable{Key: strings.TrimSpace(key), Value: value})
+	}
+	for _, file := range ctx.StringSlice("files") {
+		dest, url, ok := strings.Cut(file, "=")
+		if !ok || strings.TrimSpace(dest) == "" || strings.TrimSpace(url) == "" {
+			return module.Manifesto{}, errors.WithCause(ErrInvalidFileFlag, fmt.Errorf("%q", file))
+		}
+		md.Install.Files = append(
+			md.Install.Files,
+			module.InstalledFile{SourceUrl: strings.TrimSpace(url), DestFile: strings.TrimSpace(dest)},
+		)
+	}
+	for _, cmd := range ctx.StringSlice("post-install") {
+		fields := strings.Fields(cmd)
+		if len(fields) == 0 {
+			continue
+		}
+		md.Install.PostInstallCommands = append(
+			md.Install.PostInstallCommands,
+			module.PostInstallCommand{CmdPackage: fields[0], Params: fields[1:]},
+		)
+	}
+	return md, nil
+}
+
+// askValue returns the value of the flag or asks the user to enter it if the flag is not set
+func askValue(value string, label string, isSilent bool, errEmpty error) (string, error) {
+	value = strings.TrimSpace(value)
+	if value != "" {
+		return value, nil
+	}
+	if isSilent {
+		return "", errEmpty
+	}
+	prompt := promptui.Prompt{
+		Label: label,
+		Validate: func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return errEmpty
+			}
+			return nil
+		},
+	}
+	value, err := prompt.Run()
+	return strings.TrimSpace(value), err
+}
+
+func printError(msg string, err error) {
+	fmt.Println(color.RedString("%s: %s", msg, err.Error()))
+	if errors.Hint(err) != "" {
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(err)))
+	}
+}
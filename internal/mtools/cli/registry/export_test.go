@@ -0,0 +1,14 @@
+package registry
+
+import "github.com/go-modulus/modulus/module"
+
+var SelectModules = selectModules
+var ServeDir = serveDir
+var RewriteBaseUrl = rewriteBaseUrl
+var PrintReport = printReport
+
+type TestResult = testResult
+
+func NewTestResult(md module.Manifesto, step string, output []byte) TestResult {
+	return testResult{module: md, step: step, output: output}
+}
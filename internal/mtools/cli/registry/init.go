@@ -0,0 +1,91 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrRegistryExists = errbuilder.New("registry manifest already exists").
+	WithHint("Use mtools registry add to add modules to the existing registry").Build()
+
+// registryJson is the content of a new registry manifest
+type registryJson struct {
+	Schema      string             `json:"$schema"`
+	Name        string             `json:"name,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Modules     []module.Manifesto `json:"modules"`
+}
+
+type Init struct {
+}
+
+func NewInit() *Init {
+	return &Init{}
+}
+
+func NewInitCommand(init *Init) *cli.Command {
+	return &cli.Command{
+		Name: "init",
+		Usage: `Create an empty modules.json manifest of a registry.
+Example: mtools registry init --name=company --description="The modules of our company"
+Example for another path: mtools registry init --registry=registry/modules.json
+`,
+		Action: init.Invoke,
+		Flags: []cli.Flag{
+			newRegistryFlag(),
+			&cli.StringFlag{
+				Name:    "name",
+				Usage:   "The name of the registry",
+				Aliases: []string{"n"},
+			},
+			&cli.StringFlag{
+				Name:    "description",
+				Usage:   "The description of the registry",
+				Aliases: []string{"d"},
+			},
+		},
+	}
+}
+
+func (i *Init) Invoke(ctx *cli.Context) error {
+	filename := registryValue(ctx)
+	if fsys.IsFile(filename) {
+		fmt.Println(color.RedString("Cannot create the registry %s: %s", filename, ErrRegistryExists.Error()))
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(ErrRegistryExists)))
+		return ErrRegistryExists
+	}
+
+	data, err := json.MarshalIndent(
+		registryJson{
+			Schema:      manifesto.SchemaBaseUrl + manifesto.RegistrySchema,
+			Name:        ctx.String("name"),
+			Description: ctx.String("description"),
+			Modules:     make([]module.Manifesto, 0),
+		}, "", "  ",
+	)
+	if err != nil {
+		return err
+	}
+	err = fsys.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot create the registry folder: %s", err.Error()))
+		return err
+	}
+	err = fsys.WriteFile(filename, data, 0644)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot write the registry %s: %s", filename, err.Error()))
+		return err
+	}
+	fmt.Println(color.GreenString("The registry"), color.BlueString(filename), color.GreenString("is created."))
+	fmt.Println("To add a module, run the command: " + color.CyanString("mtools registry add"))
+	return nil
+}
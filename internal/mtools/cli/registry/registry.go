@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/urfave/cli/v2"
+)
+
+func NewRegistryCommand(
+	init *Init,
+	add *Add,
+	validate *Validate,
+	test *Test,
+) *cli.Command {
+	return &cli.Command{
+		Name: "registry",
+		Usage: `A set of commands for authoring the modules.json manifest of a registry with the modules available to install.
+Example: mtools registry
+`,
+		Before: flag.DryRunBefore,
+		After:  flag.DryRunAfter,
+		Subcommands: []*cli.Command{
+			NewInitCommand(init),
+			NewAddCommand(add),
+			NewValidateCommand(validate),
+			NewTestCommand(test),
+		},
+	}
+}
+
+func newRegistryFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "registry",
+		Usage:   "A path to the modules.json file of the registry",
+		Aliases: []string{"r"},
+		Value:   "modules.json",
+	}
+}
+
+func registryValue(ctx *cli.Context) string {
+	return ctx.String("registry")
+}
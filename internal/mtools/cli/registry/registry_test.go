@@ -0,0 +1,150 @@
+package registry_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/registry"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func newContext(filename string, values map[string]string, slices map[string][]string) *cli.Context {
+	set := flag.NewFlagSet("test", 0)
+	set.String("registry", filename, "")
+	set.Bool("silent", true, "")
+	for name, value := range values {
+		set.String(name, value, "")
+	}
+	for name, values := range slices {
+		slice := cli.NewStringSlice(values...)
+		set.Var(slice, name, "")
+	}
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestInit_Invoke(t *testing.T) {
+	t.Run(
+		"create an empty registry", func(t *testing.T) {
+			filename := t.TempDir() + "/registry/modules.json"
+
+			err := registry.NewInit().Invoke(newContext(filename, map[string]string{"name": "company"}, nil))
+			data, errRead := os.ReadFile(filename)
+			errSecond := registry.NewInit().Invoke(newContext(filename, nil, nil))
+
+			t.Log("When init a registry")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The registry should match the schema")
+			require.NoError(t, errRead)
+			errs, err := manifesto.Validate(data, manifesto.RegistrySchema)
+			require.NoError(t, err)
+			require.Empty(t, errs)
+			require.Contains(t, string(data), `"name": "company"`)
+			t.Log("	The second init should fail")
+			require.ErrorIs(t, errSecond, registry.ErrRegistryExists)
+		},
+	)
+}
+
+func TestAdd_Invoke(t *testing.T) {
+	t.Run(
+		"add a module to the registry", func(t *testing.T) {
+			filename := t.TempDir() + "/modules.json"
+			require.NoError(t, registry.NewInit().Invoke(newContext(filename, nil, nil)))
+
+			errLogger := registry.NewAdd().Invoke(
+				newContext(
+					filename,
+					map[string]string{"name": "logger", "package": "github.com/company/logger"},
+					nil,
+				),
+			)
+			errPgx := registry.NewAdd().Invoke(
+				newContext(
+					filename,
+					map[string]string{
+						"name":        "pgx",
+						"package":     "github.com/company/pgx",
+						"description": "A pgx connection pool",
+					},
+					map[string][]string{
+						"dependencies": {"logger@^0.5.0"},
+						"env":          {"PGX_DSN=postgres://localhost:5432/db?sslmode=disable&pool_max_conns=10"},
+						"files":        {"internal/pgx/config.go=https://example.com/pgx/config.go"},
+						"post-install": {"github.com/company/pgx/cmd/install --force"},
+					},
+				),
+			)
+			data, errRead := os.ReadFile(filename)
+			require.NoError(t, errRead)
+			require.Contains(t, string(data), "sslmode=disable&pool_max_conns=10")
+			m := &manifesto.LocalManifesto{}
+			require.NoError(t, m.ReadFromJSON(data))
+			errValidate := registry.NewValidate().Invoke(newContext(filename, nil, nil))
+
+			t.Log("Given an empty registry")
+			t.Log("When add the modules")
+			t.Log("	The errors should be nil")
+			require.NoError(t, errLogger)
+			require.NoError(t, errPgx)
+			t.Log("	The modules should be added with the install instructions")
+			require.Len(t, m.Modules, 2)
+			pgx := m.Modules[1]
+			require.Equal(t, "A pgx connection pool", pgx.Description)
+			require.Equal(t, []string{"logger@^0.5.0"}, pgx.Install.Dependencies)
+			require.Equal(t, "PGX_DSN", pgx.Install.EnvVars[0].Key)
+			require.Equal(t, "postgres://localhost:5432/db?sslmode=disable&pool_max_conns=10", pgx.Install.EnvVars[0].Value)
+			require.Equal(t, "internal/pgx/config.go", pgx.Install.Files[0].DestFile)
+			require.Equal(t, "https://example.com/pgx/config.go", pgx.Install.Files[0].SourceUrl)
+			require.Equal(t, "github.com/company/pgx/cmd/install", pgx.Install.PostInstallCommands[0].CmdPackage)
+			require.Equal(t, []string{"--force"}, pgx.Install.PostInstallCommands[0].Params)
+			t.Log("	The registry should be valid")
+			require.NoError(t, errValidate)
+		},
+	)
+
+	t.Run(
+		"reject a module without a package in the silent mode", func(t *testing.T) {
+			filename := t.TempDir() + "/modules.json"
+			require.NoError(t, registry.NewInit().Invoke(newContext(filename, nil, nil)))
+
+			err := registry.NewAdd().Invoke(newContext(filename, map[string]string{"name": "pgx"}, nil))
+
+			t.Log("When add a module without a package")
+			t.Log("	The error should be ErrModulePackageIsEmpty")
+			require.ErrorIs(t, err, registry.ErrModulePackageIsEmpty)
+		},
+	)
+}
+
+func TestValidate_Invoke(t *testing.T) {
+	t.Run(
+		"report the mistakes of the registry", func(t *testing.T) {
+			filename := t.TempDir() + "/modules.json"
+			require.NoError(
+				t, os.WriteFile(
+					filename, []byte(`{
+  "modules": [
+    {
+      "name": "pgx",
+      "package": "github.com/company/pgx",
+      "install": {
+        "dependencies": ["logger"]
+      }
+    }
+  ]
+}`), 0644,
+				),
+			)
+
+			err := registry.NewValidate().Invoke(newContext(filename, nil, nil))
+
+			t.Log("When validate a registry with an unknown dependency")
+			t.Log("	The error should be ErrRegistryIsInvalid")
+			require.ErrorIs(t, err, registry.ErrRegistryIsInvalid)
+		},
+	)
+}
@@ -0,0 +1,326 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cache"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrRegistryModuleNotFound = errbuilder.New("module is not found in the registry").
+	WithHint("Run mtools registry validate to see the modules of the registry").Build()
+var ErrCannotInitTestProject = errbuilder.New("cannot init the test project").
+	WithHint("Check that mtools init works in the current environment, it needs the network to get the Go packages").Build()
+var ErrRegistryTestFailed = errbuilder.New("some modules of the registry cannot be installed").
+	WithHint("Fix the reported entries. Run the command with the --keep flag to inspect the test projects").Build()
+
+// testProjectPackage is the Go module name of the throwaway projects the registry modules are installed into
+const testProjectPackage = "example.com/registrytest"
+
+// outputTailLines is the number of the last lines of the failed step output printed in the report
+const outputTailLines = 20
+
+const stepTimeout = 10 * time.Minute
+
+// testResult is the result of the installation of one registry module
+type testResult struct {
+	module module.Manifesto
+	// step is the failed step, it is empty if the module is installed successfully
+	step   string
+	output []byte
+}
+
+type Test struct {
+}
+
+func NewTest() *Test {
+	return &Test{}
+}
+
+func NewTestCommand(test *Test) *cli.Command {
+	return &cli.Command{
+		Name: "test",
+		Usage: `Install each module of the registry into a throwaway project created by mtools init in a temp dir and build the project.
+The install files published under the --base-url are served from the registry folder by a local file server,
+so the changes of the files can be tested before publishing them. Reports the modules that cannot be installed.
+Example: mtools registry test --base-url=https://raw.githubusercontent.com/company/registry/main/
+Example for the chosen modules: mtools registry test --modules=pgx,logger --keep
+`,
+		Action: test.Invoke,
+		Flags: []cli.Flag{
+			newRegistryFlag(),
+			&cli.StringSliceFlag{
+				Name:    "modules",
+				Usage:   "A comma-separated list of the modules names to test. All modules of the registry are tested by default",
+				Aliases: []string{"m"},
+			},
+			&cli.StringFlag{
+				Name: "base-url",
+				Usage: "The URL the registry folder is published at. The install files under this URL are served from the registry folder. " +
+					"All files are downloaded from the network if it is not set",
+			},
+			&cli.BoolFlag{
+				Name:  "keep",
+				Usage: "Keep the test projects in the temp dir to inspect them",
+			},
+		},
+	}
+}
+
+func (t *Test) Invoke(ctx *cli.Context) error {
+	filename := registryValue(ctx)
+	data, err := fsys.ReadFile(filename)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the registry %s: %s", filename, err.Error()))
+		return err
+	}
+	registry := &manifesto.LocalManifesto{}
+	err = registry.ReadFromJSON(data)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot parse the registry %s: %s", filename, err.Error()))
+		return err
+	}
+	modules, err := selectModules(registry, ctx.StringSlice("modules"))
+	if err != nil {
+		printError("Cannot choose the modules to test", err)
+		return err
+	}
+	mtoolsBin, err := os.Executable()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot find the mtools binary: %s", err.Error()))
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "mtools-registry-test")
+	if err != nil {
+		fmt.Println(color.RedString("Cannot create the temp dir: %s", err.Error()))
+		return err
+	}
+	if ctx.Bool("keep") {
+		defer fmt.Println("The test projects are kept in", color.BlueString(dir))
+	} else {
+		defer os.RemoveAll(dir)
+	}
+
+	baseUrl := ctx.String("base-url")
+	if baseUrl != "" {
+		serverUrl, stop, err := serveDir(filepath.Dir(filename))
+		if err != nil {
+			fmt.Println(color.RedString("Cannot start the local file server: %s", err.Error()))
+			return err
+		}
+		defer stop()
+		fmt.Printf("Serving the files of %s from %s\n", color.BlueString(baseUrl), color.BlueString(serverUrl))
+		data = rewriteBaseUrl(data, baseUrl, serverUrl)
+	} else {
+		fmt.Println(color.YellowString("The --base-url flag is not set, the install files are downloaded from the network"))
+	}
+	testRegistry := filepath.Join(dir, "modules.json")
+	err = os.WriteFile(testRegistry, data, 0644)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot write the test registry: %s", err.Error()))
+		return err
+	}
+
+	// the downloaded files of the tested modules should not get into the user cache
+	env := append(os.Environ(), cache.DirEnv+"="+filepath.Join(dir, "cache"))
+	// the project is initialized once and copied for each module
+	templateDir := filepath.Join(dir, "template")
+	fmt.Println("Initializing the test project...")
+	output, err := run(ctx.Context, env, "", mtoolsBin, "init", "--path", templateDir, "--name", testProjectPackage)
+	if err != nil {
+		printOutput(os.Stdout, output)
+		printError("Cannot init the test project", errors.WithCause(ErrCannotInitTestProject, err))
+		return ErrCannotInitTestProject
+	}
+
+	results := make([]testResult, 0, len(modules))
+	for _, md := range modules {
+		fmt.Printf("Testing the module %s...\n", color.BlueString(md.Name))
+		res := t.testModule(ctx.Context, env, mtoolsBin, templateDir, filepath.Join(dir, "projects"), testRegistry, md)
+		if res.step == "" {
+			fmt.Println(color.GreenString("The module %s is installed and built", md.Name))
+		} else {
+			fmt.Println(color.RedString("The module %s failed at the step: %s", md.Name, res.step))
+		}
+		results = append(results, res)
+	}
+	return printReport(os.Stdout, results)
+}
+
+// testModule installs the module into a copy of the template project and builds the project
+func (t *Test) testModule(
+	ctx context.Context,
+	env []string,
+	mtoolsBin string,
+	templateDir string,
+	projectsDir string,
+	testRegistry string,
+	md module.Manifesto,
+) testResult {
+	res := testResult{module: md}
+	projDir := filepath.Join(projectsDir, strings.ReplaceAll(strings.ToLower(md.Name), " ", "-"))
+	err := copyDir(templateDir, projDir)
+	if err != nil {
+		res.step = "copy the test project"
+		res.output = []byte(err.Error())
+		return res
+	}
+	res.output, err = run(
+		ctx,
+		env,
+		"",
+		mtoolsBin,
+		"--proj-path", projDir,
+		"module", "install",
+		"--manifest", testRegistry,
+		"--modules", md.Name,
+	)
+	if err != nil {
+		res.step = "mtools module install"
+		return res
+	}
+	res.output, err = run(ctx, env, projDir, "go", "build", "./...")
+	if err != nil {
+		res.step = "go build"
+		return res
+	}
+	res.output = nil
+	return res
+}
+
+// printReport prints the results of the modules to the writer.
+// Returns ErrRegistryTestFailed if any module is failed.
+func printReport(w io.Writer, results []testResult) error {
+	failed := 0
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Results:")
+	for _, res := range results {
+		if res.step == "" {
+			_, _ = fmt.Fprintf(w, "	%s: %s\n", color.BlueString(res.module.Name), color.GreenString("ok"))
+			continue
+		}
+		failed++
+		_, _ = fmt.Fprintf(w, "	%s: %s\n", color.BlueString(res.module.Name), color.RedString("failed at %s", res.step))
+		printOutput(w, res.output)
+	}
+	if failed == 0 {
+		_, _ = fmt.Fprintln(w, color.GreenString("All %d modules are installed successfully.", len(results)))
+		return nil
+	}
+	_, _ = fmt.Fprintln(w, color.RedString("%d of %d modules cannot be installed.", failed, len(results)))
+	_, _ = fmt.Fprintln(w, color.YellowString("Hint: %s", errors.Hint(ErrRegistryTestFailed)))
+	return ErrRegistryTestFailed
+}
+
+// selectModules returns the registry modules with the names or all modules if the names are empty
+func selectModules(registry *manifesto.LocalManifesto, names []string) ([]module.Manifesto, error) {
+	if len(names) == 0 {
+		return registry.Modules, nil
+	}
+	res := make([]module.Manifesto, 0, len(names))
+	for _, name := range names {
+		md, ok := registry.FindModule(strings.TrimSpace(name))
+		if !ok {
+			return nil, errors.WithCause(ErrRegistryModuleNotFound, fmt.Errorf("%s", name))
+		}
+		res = append(res, md)
+	}
+	return res, nil
+}
+
+// rewriteBaseUrl replaces the URLs under the base URL in the registry data with the URLs of the local file server
+func rewriteBaseUrl(data []byte, baseUrl string, serverUrl string) []byte {
+	if !strings.HasSuffix(baseUrl, "/") {
+		baseUrl += "/"
+	}
+	return bytes.ReplaceAll(data, []byte(baseUrl), []byte(serverUrl+"/"))
+}
+
+// serveDir starts the local file server of the directory.
+// Returns the URL of the server and the function stopping it.
+func serveDir(dir string) (string, func(), error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	server := &http.Server{
+		Handler:           http.FileServer(http.Dir(dir)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return "http://" + listener.Addr().String(), func() { _ = server.Close() }, nil
+}
+
+// run runs the command in the directory and returns its combined output
+func run(ctx context.Context, env []string, dir string, name string, args ...string) ([]byte, error) {
+	cmdCtx, cancel := context.WithTimeout(ctx, stepTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, name, args...)
+	cmd.Env = env
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// printOutput prints the last lines of the command output to the writer
+func printOutput(w io.Writer, output []byte) {
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	if len(lines) > outputTailLines {
+		lines = lines[len(lines)-outputTailLines:]
+	}
+	for _, line := range lines {
+		if line != "" {
+			_, _ = fmt.Fprintln(w, color.WhiteString("		%s", line))
+		}
+	}
+}
+
+// copyDir copies the files of the directory keeping their modes
+func copyDir(src string, dest string) error {
+	return filepath.WalkDir(
+		src, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(dest, rel)
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return os.MkdirAll(target, info.Mode().Perm())
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, content, info.Mode().Perm())
+		},
+	)
+}
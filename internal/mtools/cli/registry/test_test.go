@@ -0,0 +1,166 @@
+package registry_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/registry"
+	"github.com/stretchr/testify/require"
+)
+
+const testedRegistryJson = `{
+  "schemaVersion": 1,
+  "modules": [
+    {
+      "name": "pgx",
+      "package": "github.com/go-modulus/modulus/db/pgx",
+      "install": {
+        "files": [
+          {"sourceUrl": "https://example.com/registry/pgx/config.go.tmpl", "destFile": "internal/config.go"},
+          {"sourceUrl": "https://other.com/registry/pgx/config.go.tmpl", "destFile": "internal/other.go"}
+        ]
+      }
+    },
+    {
+      "name": "slog logger",
+      "package": "github.com/go-modulus/modulus/logger"
+    }
+  ]
+}`
+
+func TestServeDir(t *testing.T) {
+	t.Run(
+		"serve the install files under the base url", func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(dir+"/pgx", 0755))
+			require.NoError(t, os.WriteFile(dir+"/pgx/config.go.tmpl", []byte("package config\n"), 0644))
+
+			serverUrl, stop, err := registry.ServeDir(dir)
+			require.NoError(t, err)
+			data := registry.RewriteBaseUrl([]byte(testedRegistryJson), "https://example.com/registry", serverUrl)
+			m := &manifesto.LocalManifesto{}
+			require.NoError(t, m.ReadFromJSON(data))
+			files := m.Modules[0].Install.Files
+
+			resp, errGet := http.Get(files[0].SourceUrl)
+			require.NoError(t, errGet)
+			content, errRead := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			stop()
+			_, errStopped := http.Get(files[0].SourceUrl)
+
+			t.Log("Given the registry folder with the install file of the pgx module")
+			t.Log("When serve the folder and rewrite the base url of the registry")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The urls under the base url should point to the local file server")
+			require.Equal(t, serverUrl+"/pgx/config.go.tmpl", files[0].SourceUrl)
+			t.Log("	The other urls should be kept")
+			require.Equal(t, "https://other.com/registry/pgx/config.go.tmpl", files[1].SourceUrl)
+			t.Log("	The file should be served from the registry folder")
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.NoError(t, errRead)
+			require.Equal(t, "package config\n", string(content))
+			t.Log("	The server should not answer after it is stopped")
+			require.Error(t, errStopped)
+		},
+	)
+}
+
+func TestSelectModules(t *testing.T) {
+	m := &manifesto.LocalManifesto{}
+	require.NoError(t, m.ReadFromJSON([]byte(testedRegistryJson)))
+
+	t.Run(
+		"select all modules", func(t *testing.T) {
+			modules, err := registry.SelectModules(m, nil)
+
+			t.Log("When select the modules without the names")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	All modules of the registry should be selected")
+			require.Len(t, modules, 2)
+		},
+	)
+
+	t.Run(
+		"select the modules by names", func(t *testing.T) {
+			modules, err := registry.SelectModules(m, []string{" slog logger", "pgx"})
+
+			t.Log("When select the modules by the names")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The modules should be selected in the order of the names")
+			require.Len(t, modules, 2)
+			require.Equal(t, "slog logger", modules[0].Name)
+			require.Equal(t, "pgx", modules[1].Name)
+		},
+	)
+
+	t.Run(
+		"select an unknown module", func(t *testing.T) {
+			_, err := registry.SelectModules(m, []string{"pgx", "chi"})
+
+			t.Log("When select a module absent in the registry")
+			t.Log("	The error should be ErrRegistryModuleNotFound")
+			require.ErrorIs(t, err, registry.ErrRegistryModuleNotFound)
+		},
+	)
+}
+
+func TestPrintReport(t *testing.T) {
+	t.Run(
+		"report the installed modules", func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := registry.PrintReport(
+				w, []registry.TestResult{
+					registry.NewTestResult(module.Manifesto{Name: "pgx"}, "", nil),
+					registry.NewTestResult(module.Manifesto{Name: "slog logger"}, "", nil),
+				},
+			)
+
+			t.Log("When report the modules installed successfully")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The report should list the modules as ok")
+			require.Contains(t, w.String(), "pgx: ok")
+			require.Contains(t, w.String(), "slog logger: ok")
+			require.Contains(t, w.String(), "All 2 modules are installed successfully.")
+		},
+	)
+
+	t.Run(
+		"report the failed modules", func(t *testing.T) {
+			lines := make([]string, 0, 25)
+			for i := 1; i <= 25; i++ {
+				lines = append(lines, fmt.Sprintf("build error %d", i))
+			}
+			w := &bytes.Buffer{}
+			err := registry.PrintReport(
+				w, []registry.TestResult{
+					registry.NewTestResult(module.Manifesto{Name: "pgx"}, "go build", []byte(strings.Join(lines, "\n")+"\n")),
+					registry.NewTestResult(module.Manifesto{Name: "slog logger"}, "", nil),
+				},
+			)
+
+			t.Log("When report a module failed at the build step")
+			t.Log("	The error should be ErrRegistryTestFailed")
+			require.ErrorIs(t, err, registry.ErrRegistryTestFailed)
+			t.Log("	The report should list the failed step")
+			require.Contains(t, w.String(), "pgx: failed at go build")
+			require.Contains(t, w.String(), "slog logger: ok")
+			require.Contains(t, w.String(), "1 of 2 modules cannot be installed.")
+			t.Log("	The report should contain only the last lines of the output")
+			require.Contains(t, w.String(), "build error 6\n")
+			require.Contains(t, w.String(), "build error 25\n")
+			require.NotContains(t, w.String(), "build error 5\n")
+		},
+	)
+}
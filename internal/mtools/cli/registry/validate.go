@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/modulus/errors"
+	"github.com/go-modulus/modulus/errors/errbuilder"
+	"github.com/go-modulus/mtools/internal/manifesto"
+	"github.com/go-modulus/mtools/internal/mtools/cli/manifest"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/urfave/cli/v2"
+)
+
+var ErrRegistryIsInvalid = errbuilder.New("registry manifest is invalid").
+	WithHint("Fix the reported entries. Run mtools manifest schema --registry to see the allowed fields.").Build()
+
+type Validate struct {
+}
+
+func NewValidate() *Validate {
+	return &Validate{}
+}
+
+func NewValidateCommand(validate *Validate) *cli.Command {
+	return &cli.Command{
+		Name: "validate",
+		Usage: `Check the registry manifest against the JSON Schema and find the mistakes in the module entries:
+duplicated modules, unknown or cyclic dependencies, wrong version constraints, install files and env variables.
+Exits with a non-zero code if the registry is invalid.
+Example: mtools registry validate
+Example for another path: mtools registry validate --registry=registry/modules.json
+`,
+		Action: validate.Invoke,
+		Flags: []cli.Flag{
+			newRegistryFlag(),
+		},
+	}
+}
+
+func (v *Validate) Invoke(ctx *cli.Context) error {
+	filename := registryValue(ctx)
+	data, err := fsys.ReadFile(filename)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot read the registry %s: %s", filename, err.Error()))
+		return err
+	}
+
+	fieldErrs, err := manifesto.Validate(data, manifesto.RegistrySchema)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot validate the registry %s: %s", filename, err.Error()))
+		return err
+	}
+	manifest.PrintFieldErrors(os.Stdout, filename, fieldErrs)
+	invalid := len(fieldErrs) != 0
+
+	registry := &manifesto.LocalManifesto{}
+	err = registry.ReadFromJSON(data)
+	if err != nil {
+		fmt.Println(color.RedString("Cannot parse the registry %s: %s", filename, err.Error()))
+		return err
+	}
+	for _, e := range manifesto.CheckRegistry(registry) {
+		invalid = true
+		fmt.Printf("%s: %s\n", filename, color.RedString(e.Error()))
+	}
+
+	if invalid {
+		fmt.Println(color.YellowString("Hint: %s", errors.Hint(ErrRegistryIsInvalid)))
+		return ErrRegistryIsInvalid
+	}
+	fmt.Printf(
+		"%s %s\n",
+		color.BlueString(filename),
+		color.GreenString("is valid, %d modules are checked", len(registry.Modules)),
+	)
+	return nil
+}
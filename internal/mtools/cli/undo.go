@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/mtools/internal/mtools/cli/flag"
+	"github.com/go-modulus/mtools/internal/mtools/journal"
+	"github.com/urfave/cli/v2"
+)
+
+type Undo struct {
+	logger *slog.Logger
+}
+
+func NewUndo(
+	logger *slog.Logger,
+) *Undo {
+	return &Undo{
+		logger: logger,
+	}
+}
+
+func NewUndoCommand(c *Undo) *cli.Command {
+	return &cli.Command{
+		Name: "undo",
+		Usage: `Reverts the changes made by the last successful module install.
+Restores all project files saved to the journal in the .mtools folder before the install.
+Files changed by the post install commands are restored only if they were saved to the journal (go.mod, go.sum, .env, modules.json, entrypoints and downloaded files).
+Example: mtools undo
+Example: mtools undo --proj-path=/path/to/project/root
+`,
+		Action: c.Invoke,
+	}
+}
+
+func (c *Undo) Invoke(
+	ctx *cli.Context,
+) error {
+	projPath := flag.ProjPathValue(ctx)
+	if projPath == "" {
+		projPath = "."
+	}
+
+	jrnl, err := journal.Last(projPath)
+	if err != nil {
+		if errors.Is(err, journal.ErrNoJournal) {
+			fmt.Println(color.YellowString("There are no changes to undo. Exiting..."))
+			return nil
+		}
+		fmt.Println(color.RedString("Cannot read the journal: %s", err.Error()))
+		return err
+	}
+
+	fmt.Printf(
+		"Undoing the command %s made at %s...\n",
+		color.BlueString(jrnl.Command),
+		color.BlueString(jrnl.CreatedAt.Format("2006-01-02 15:04:05")),
+	)
+	for i := len(jrnl.Entries) - 1; i >= 0; i-- {
+		entry := jrnl.Entries[i]
+		if entry.Existed {
+			fmt.Printf("Restoring the file %s\n", color.BlueString(entry.Path))
+		} else {
+			fmt.Printf("Removing the file %s\n", color.BlueString(entry.Path))
+		}
+	}
+
+	err = jrnl.Rollback()
+	if err != nil {
+		fmt.Println(color.RedString("Cannot undo the changes: %s", err.Error()))
+		return err
+	}
+
+	fmt.Println(color.GreenString("The changes are reverted."))
+	return nil
+}
@@ -0,0 +1,383 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+)
+
+var ErrOverlappingEdits = errors.New("the changes of the file overlap")
+
+// edit replaces the source bytes between the start and end offsets with the text
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// sourceEditor changes a go file by patching its source bytes at the positions found in the AST.
+// Unlike printing the changed AST, the patching keeps the comments, blank lines and layout of the untouched code.
+type sourceEditor struct {
+	filename string
+	fset     *token.FileSet
+	file     *ast.File
+	src      []byte
+	edits    []edit
+	// changed is true if the edits were applied by reload and the source differs from the file
+	changed bool
+}
+
+func newSourceEditor(filename string) (*sourceEditor, error) {
+	src, err := fsys.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return &sourceEditor{
+		filename: filename,
+		fset:     fset,
+		file:     file,
+		src:      src,
+	}, nil
+}
+
+func (e *sourceEditor) offset(pos token.Pos) int {
+	return e.fset.Position(pos).Offset
+}
+
+func (e *sourceEditor) line(pos token.Pos) int {
+	return e.fset.Position(pos).Line
+}
+
+func (e *sourceEditor) insert(offset int, text string) {
+	e.edits = append(e.edits, edit{start: offset, end: offset, text: text})
+}
+
+func (e *sourceEditor) remove(start, end int) {
+	e.edits = append(e.edits, edit{start: start, end: end})
+}
+
+// lineStart returns the offset of the first byte of the line containing the offset
+func (e *sourceEditor) lineStart(offset int) int {
+	return bytes.LastIndexByte(e.src[:offset], '\n') + 1
+}
+
+// lineEnd returns the offset of the new line character ending the line containing the offset
+func (e *sourceEditor) lineEnd(offset int) int {
+	i := bytes.IndexByte(e.src[offset:], '\n')
+	if i == -1 {
+		return len(e.src)
+	}
+	return offset + i
+}
+
+// indent returns the leading whitespaces of the line containing the offset
+func (e *sourceEditor) indent(offset int) string {
+	start := e.lineStart(offset)
+	end := start
+	for end < len(e.src) && (e.src[end] == ' ' || e.src[end] == '\t') {
+		end++
+	}
+	return string(e.src[start:end])
+}
+
+// isLineHead checks if there are only whitespaces between the line start and the offset
+func (e *sourceEditor) isLineHead(offset int) bool {
+	return strings.TrimSpace(string(e.src[e.lineStart(offset):offset])) == ""
+}
+
+// isLineTail checks if there are only a comma, whitespaces or a line comment between the offset and the line end
+func (e *sourceEditor) isLineTail(offset int) bool {
+	rest := strings.TrimSpace(string(e.src[offset:e.lineEnd(offset)]))
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+	return rest == "" || strings.HasPrefix(rest, "//")
+}
+
+// appendToList adds the element to the end of the list of call arguments or composite literal elements.
+// The opening and closing positions are the parentheses or braces of the list.
+// A multiline list gets the element on a new line with the indentation of the list,
+// a single line list gets it after a comma, an empty list becomes multiline.
+func (e *sourceEditor) appendToList(elts []ast.Expr, open, closing token.Pos, element string) {
+	closeOffset := e.offset(closing)
+	if len(elts) == 0 && e.line(open) == e.line(closing) {
+		ind := e.indent(closeOffset)
+		e.insert(closeOffset, "\n"+ind+"\t"+element+",\n"+ind)
+		return
+	}
+	if len(elts) == 0 || e.line(elts[len(elts)-1].End()) < e.line(closing) {
+		lineStart := e.lineStart(closeOffset)
+		if !e.isLineHead(closeOffset) {
+			// the closing parenthesis is placed after the last comment, e.g. "// comment\n\t\t/* x */)"
+			e.insert(closeOffset, "\n"+e.indent(closeOffset)+"\t"+element+",\n"+e.indent(closeOffset))
+			return
+		}
+		e.insert(lineStart, e.indent(closeOffset)+"\t"+element+",\n")
+		return
+	}
+	e.insert(e.offset(elts[len(elts)-1].End()), ", "+element)
+}
+
+// removeFromList removes the element with the index from the list of call arguments or composite literal elements.
+// The element placed on its own lines is removed with the lines, including its trailing comment.
+func (e *sourceEditor) removeFromList(elts []ast.Expr, i int) {
+	start := e.offset(elts[i].Pos())
+	end := e.offset(elts[i].End())
+	if e.isLineHead(start) && e.isLineTail(end) {
+		e.remove(e.lineStart(start), e.lineEnd(end)+1)
+		return
+	}
+	if i+1 < len(elts) {
+		e.remove(start, e.offset(elts[i+1].Pos()))
+		return
+	}
+	if i > 0 {
+		e.remove(e.offset(elts[i-1].End()), end)
+		return
+	}
+	if end < len(e.src) && e.src[end] == ',' {
+		end++
+	}
+	e.remove(start, end)
+}
+
+// importPath returns the unquoted path of the import spec
+func importPath(spec *ast.ImportSpec) string {
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return spec.Path.Value
+	}
+	return path
+}
+
+// addImport adds the import of the package with the alias.
+// The import is placed into the block with the most similar packages keeping the block sorted.
+// The alias is omitted if it is empty. Does nothing if the package is already imported.
+func (e *sourceEditor) addImport(alias string, packagePath string) {
+	spec := strconv.Quote(packagePath)
+	if alias != "" {
+		spec = alias + " " + spec
+	}
+
+	var bestDecl *ast.GenDecl
+	var bestSpec *ast.ImportSpec
+	bestLen := -1
+	for _, decl := range e.file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		if bestDecl == nil {
+			bestDecl = genDecl
+		}
+		for _, s := range genDecl.Specs {
+			imp := s.(*ast.ImportSpec)
+			if importPath(imp) == packagePath {
+				return
+			}
+			n := importSimilarity(importPath(imp), packagePath)
+			if n > bestLen || (n == bestLen && n == 0) {
+				bestLen = n
+				bestDecl = genDecl
+				bestSpec = imp
+			}
+		}
+	}
+
+	if bestDecl == nil {
+		e.insert(e.offset(e.file.Name.End()), "\n\nimport "+spec)
+		return
+	}
+	if !bestDecl.Lparen.IsValid() {
+		e.insert(e.lineEnd(e.offset(bestDecl.End())), "\nimport "+spec)
+		return
+	}
+	if bestSpec == nil {
+		rparen := e.offset(bestDecl.Rparen)
+		e.insert(rparen, "\n\t"+spec+"\n")
+		return
+	}
+
+	group := e.importGroup(bestDecl, bestSpec)
+	for _, imp := range group {
+		if importPath(imp) > packagePath {
+			start := e.offset(imp.Pos())
+			if imp.Doc != nil {
+				start = e.offset(imp.Doc.Pos())
+			}
+			e.insert(e.lineStart(start), e.indent(start)+spec+"\n")
+			return
+		}
+	}
+	last := e.offset(group[len(group)-1].End())
+	e.insert(e.lineEnd(last), "\n"+e.indent(last)+spec)
+}
+
+// importGroup returns the specs of the block separated by blank lines that contains the spec
+func (e *sourceEditor) importGroup(decl *ast.GenDecl, spec *ast.ImportSpec) []*ast.ImportSpec {
+	groups := make([][]*ast.ImportSpec, 0)
+	lastLine := 0
+	for _, s := range decl.Specs {
+		imp := s.(*ast.ImportSpec)
+		startLine := e.line(imp.Pos())
+		if imp.Doc != nil {
+			startLine = e.line(imp.Doc.Pos())
+		}
+		if len(groups) == 0 || startLine > lastLine+1 {
+			groups = append(groups, make([]*ast.ImportSpec, 0))
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], imp)
+		lastLine = e.line(imp.End())
+	}
+	for _, group := range groups {
+		for _, imp := range group {
+			if imp == spec {
+				return group
+			}
+		}
+	}
+	return []*ast.ImportSpec{spec}
+}
+
+// removeImport removes the import of the package. The import declaration is removed if it becomes empty.
+func (e *sourceEditor) removeImport(packagePath string) {
+	for _, decl := range e.file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		for _, s := range genDecl.Specs {
+			imp := s.(*ast.ImportSpec)
+			if importPath(imp) != packagePath {
+				continue
+			}
+			node := ast.Node(imp)
+			if len(genDecl.Specs) == 1 {
+				node = genDecl
+			}
+			start := e.offset(node.Pos())
+			if doc := docOf(node); doc != nil {
+				start = e.offset(doc.Pos())
+			}
+			end := e.offset(node.End())
+			if e.isLineHead(start) && e.isLineTail(end) {
+				e.remove(e.lineStart(start), e.lineEnd(end)+1)
+			} else {
+				e.remove(start, end)
+			}
+			return
+		}
+	}
+}
+
+func docOf(node ast.Node) *ast.CommentGroup {
+	switch n := node.(type) {
+	case *ast.ImportSpec:
+		return n.Doc
+	case *ast.GenDecl:
+		return n.Doc
+	}
+	return nil
+}
+
+// importSimilarity returns the score of the import paths similarity.
+// The paths with more equal leading segments are more similar,
+// the standard library packages are more similar to each other than to the other packages.
+func importSimilarity(a, b string) int {
+	aParts := strings.Split(a, "/")
+	bParts := strings.Split(b, "/")
+	n := 0
+	for n < len(aParts) && n < len(bParts) && aParts[n] == bParts[n] {
+		n++
+	}
+	n *= 2
+	if strings.Contains(aParts[0], ".") == strings.Contains(bParts[0], ".") {
+		n++
+	}
+	return n
+}
+
+// source applies the edits and checks that the result is a valid go file
+func (e *sourceEditor) source() ([]byte, error) {
+	edits := make([]edit, len(e.edits))
+	copy(edits, e.edits)
+	// the stable sort keeps the order of several insertions at the same offset
+	sort.SliceStable(
+		edits, func(i, j int) bool {
+			return edits[i].start < edits[j].start
+		},
+	)
+
+	res := bytes.Buffer{}
+	last := 0
+	prev := edit{}
+	for _, ed := range edits {
+		if ed.start < last {
+			return nil, fmt.Errorf(
+				"%w: %s:%d-%d and %d-%d",
+				ErrOverlappingEdits,
+				e.filename,
+				prev.start,
+				prev.end,
+				ed.start,
+				ed.end,
+			)
+		}
+		res.Write(e.src[last:ed.start])
+		res.WriteString(ed.text)
+		last = ed.end
+		prev = ed
+	}
+	res.Write(e.src[last:])
+
+	_, err := parser.ParseFile(token.NewFileSet(), e.filename, res.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return res.Bytes(), nil
+}
+
+// reload applies the edits and parses the result, so the next edits can rely on the changed AST
+func (e *sourceEditor) reload() error {
+	if len(e.edits) == 0 {
+		return nil
+	}
+	source, err := e.source()
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, e.filename, source, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	e.fset = fset
+	e.file = file
+	e.src = source
+	e.edits = nil
+	e.changed = true
+	return nil
+}
+
+// save writes the changed file. The file is not touched if there are no changes.
+func (e *sourceEditor) save() error {
+	if len(e.edits) == 0 && !e.changed {
+		return nil
+	}
+	source, err := e.source()
+	if err != nil {
+		return err
+	}
+	return fsys.WriteFile(e.filename, source, 0644)
+}
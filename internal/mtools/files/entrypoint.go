@@ -0,0 +1,312 @@
+package files
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+)
+
+var ErrModulesSliceNotFound = errors.New("the slice of modules is not found in the entrypoint")
+
+// modulesAppend is a call adding modules to the slice, e.g. modules = append(modules, pgx.NewModule())
+type modulesAppend struct {
+	call *ast.CallExpr
+	stmt *ast.AssignStmt
+}
+
+// entrypointModules is the slice of modules of the entrypoint.
+// The slice can be declared as a local or a package variable, returned from a function
+// and extended with the append calls:
+//
+//	modules := []*module.Module{cli.NewModule()}
+//	var modules = []*module.Module{cli.NewModule()}
+//	modules = append(modules, pgx.NewModule())
+//	func modules() []*module.Module { return []*module.Module{cli.NewModule()} }
+type entrypointModules struct {
+	literals []*ast.CompositeLit
+	appends  []modulesAppend
+	seen     map[ast.Node]struct{}
+}
+
+func findEntrypointModules(astFile *ast.File) *entrypointModules {
+	res := &entrypointModules{seen: make(map[ast.Node]struct{})}
+	res.collect(astFile, "modules")
+
+	for _, decl := range astFile.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || !returnsModulesSlice(fn) {
+			continue
+		}
+		ast.Inspect(
+			fn.Body, func(node ast.Node) bool {
+				switch n := node.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.ReturnStmt:
+					if len(n.Results) != 1 {
+						return false
+					}
+					if name := identName(n.Results[0]); name != "" {
+						res.collect(fn.Body, name)
+						return false
+					}
+					res.add(n.Results[0], nil, "")
+					return false
+				}
+				return true
+			},
+		)
+	}
+	return res
+}
+
+// collect finds the values assigned to the variable with the name in the node
+func (m *entrypointModules) collect(node ast.Node, name string) {
+	ast.Inspect(
+		node, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					if identName(lhs) == name && i < len(n.Rhs) {
+						m.add(n.Rhs[i], n, name)
+					}
+				}
+			case *ast.ValueSpec:
+				for i, ident := range n.Names {
+					if ident.Name == name && i < len(n.Values) {
+						m.add(n.Values[i], nil, name)
+					}
+				}
+			}
+			return true
+		},
+	)
+}
+
+// add remembers the slice literal of modules or the append call extending the variable with the name
+func (m *entrypointModules) add(value ast.Expr, stmt *ast.AssignStmt, name string) {
+	if _, ok := m.seen[value]; ok {
+		return
+	}
+	switch v := value.(type) {
+	case *ast.CompositeLit:
+		if v.Type != nil && !isModulesSlice(v.Type) {
+			return
+		}
+		m.literals = append(m.literals, v)
+	case *ast.CallExpr:
+		if identName(v.Fun) != "append" || len(v.Args) == 0 || identName(v.Args[0]) != name || stmt == nil {
+			return
+		}
+		m.appends = append(m.appends, modulesAppend{call: v, stmt: stmt})
+	default:
+		return
+	}
+	m.seen[value] = struct{}{}
+}
+
+func (m *entrypointModules) isEmpty() bool {
+	return len(m.literals) == 0 && len(m.appends) == 0
+}
+
+// elements returns all modules added to the slice
+func (m *entrypointModules) elements() []ast.Expr {
+	res := make([]ast.Expr, 0)
+	for _, lit := range m.literals {
+		res = append(res, lit.Elts...)
+	}
+	for _, a := range m.appends {
+		res = append(res, a.call.Args[1:]...)
+	}
+	return res
+}
+
+// isInitialized checks if the slice has the module created by the package with the alias
+func (m *entrypointModules) isInitialized(alias string) bool {
+	for _, elt := range m.elements() {
+		if rootIdentName(elt) == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// append adds the module to the first slice literal or to the first append call if there are no literals
+func (m *entrypointModules) append(editor *sourceEditor, element string) {
+	if len(m.literals) != 0 {
+		lit := m.literals[0]
+		editor.appendToList(lit.Elts, lit.Lbrace, lit.Rbrace, element)
+		return
+	}
+	call := m.appends[0].call
+	editor.appendToList(call.Args, call.Lparen, call.Rparen, element)
+}
+
+// remove removes all modules created by the package with the alias.
+// The append call left without modules is removed as well.
+func (m *entrypointModules) remove(editor *sourceEditor, alias string) {
+	for _, lit := range m.literals {
+		for i, elt := range lit.Elts {
+			if rootIdentName(elt) == alias {
+				editor.removeFromList(lit.Elts, i)
+			}
+		}
+	}
+	for _, a := range m.appends {
+		removed := 0
+		for i, arg := range a.call.Args {
+			if i != 0 && rootIdentName(arg) == alias {
+				removed++
+			}
+		}
+		if removed == 0 {
+			continue
+		}
+		if removed == len(a.call.Args)-1 {
+			start := editor.offset(a.stmt.Pos())
+			end := editor.offset(a.stmt.End())
+			if editor.isLineHead(start) && editor.isLineTail(end) {
+				editor.remove(editor.lineStart(start), editor.lineEnd(end)+1)
+				continue
+			}
+		}
+		for i, arg := range a.call.Args {
+			if i != 0 && rootIdentName(arg) == alias {
+				editor.removeFromList(a.call.Args, i)
+			}
+		}
+	}
+}
+
+// returnsModulesSlice checks if the function returns only []*module.Module
+func returnsModulesSlice(fn *ast.FuncDecl) bool {
+	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+		return false
+	}
+	return isModulesSlice(fn.Type.Results.List[0].Type)
+}
+
+func isModulesSlice(expr ast.Expr) bool {
+	arr, ok := expr.(*ast.ArrayType)
+	return ok && arr.Len == nil && isModulePointer(arr.Elt)
+}
+
+// AddModuleToEntrypoint adds the import of the package and its module initialization to the slice of modules.
+// Does nothing if the module is already in the slice.
+// Returns ErrModulesSliceNotFound if the entrypoint has no slice of modules.
+func AddModuleToEntrypoint(
+	packagePath string,
+	filename string,
+) error {
+	editor, err := newSourceEditor(filename)
+	if err != nil {
+		return err
+	}
+
+	var importSpec *ast.ImportSpec
+	for _, spec := range editor.file.Imports {
+		if importPath(spec) == packagePath {
+			importSpec = spec
+			break
+		}
+	}
+	alias := ""
+	if importSpec != nil {
+		alias = getDefPkgName(packagePath)
+		if importSpec.Name != nil {
+			alias = importSpec.Name.Name
+		}
+	} else {
+		alias, err = getUniqAlias(packagePath, 0, [][]*ast.ImportSpec{editor.file.Imports})
+		if err != nil {
+			return err
+		}
+	}
+
+	modules := findEntrypointModules(editor.file)
+	if modules.isEmpty() {
+		return ErrModulesSliceNotFound
+	}
+	if modules.isInitialized(alias) {
+		return nil
+	}
+
+	if importSpec == nil {
+		if alias == getDefPkgName(packagePath) {
+			editor.addImport("", packagePath)
+		} else {
+			editor.addImport(alias, packagePath)
+		}
+	}
+	modules.append(editor, alias+".NewModule()")
+	return editor.save()
+}
+
+// RemoveModuleFromEntrypoint removes the import of the package and all its module initializations
+// from the modules slice of the entrypoint file. Does nothing if the package is not imported.
+func RemoveModuleFromEntrypoint(
+	packagePath string,
+	filename string,
+) error {
+	editor, err := newSourceEditor(filename)
+	if err != nil {
+		return err
+	}
+
+	var importSpec *ast.ImportSpec
+	for _, imp := range editor.file.Imports {
+		if importPath(imp) == packagePath {
+			importSpec = imp
+			break
+		}
+	}
+	if importSpec == nil {
+		return nil
+	}
+	alias := getDefPkgName(packagePath)
+	if importSpec.Name != nil {
+		alias = importSpec.Name.Name
+	}
+
+	findEntrypointModules(editor.file).remove(editor, alias)
+	err = editor.reload()
+	if err != nil {
+		return err
+	}
+
+	// the package can be used somewhere else in the entrypoint, e.g. fx.Invoke(cli.Start)
+	if !isAliasUsed(editor.file, alias) {
+		editor.removeImport(packagePath)
+	}
+	return editor.save()
+}
+
+// IsModuleInEntrypoint checks if the package is imported in the entrypoint file
+// and its module is initialized in the modules slice
+func IsModuleInEntrypoint(
+	packagePath string,
+	filename string,
+) (bool, error) {
+	fset := token.NewFileSet()
+
+	astFile, err := parseFile(fset, filename)
+	if err != nil {
+		return false, err
+	}
+
+	alias := ""
+	for _, imp := range astFile.Imports {
+		if importPath(imp) == packagePath {
+			alias = getDefPkgName(packagePath)
+			if imp.Name != nil {
+				alias = imp.Name.Name
+			}
+			break
+		}
+	}
+	if alias == "" {
+		return false, nil
+	}
+
+	return findEntrypointModules(astFile).isInitialized(alias), nil
+}
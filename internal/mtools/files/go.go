@@ -1,19 +1,40 @@
 package files
 
 import (
-	"bytes"
+	"errors"
+	"fmt"
 	"go/ast"
-	"go/format"
 	"go/parser"
-	"go/printer"
 	"go/token"
-	"os"
 	"strconv"
 	"strings"
 
-	"golang.org/x/tools/go/ast/astutil"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
 )
 
+var ErrInsertionPointNotFound = errors.New("cannot find where to add the constructor")
+
+// InsertionPointError is returned if the module constructor has no call the new constructor can be added to
+type InsertionPointError struct {
+	Filename string
+	Function string
+	Method   string
+}
+
+func (e *InsertionPointError) Error() string {
+	return fmt.Sprintf(
+		"%s: there is no module built with NewModule() and %s() in the function %s of the file %s",
+		ErrInsertionPointNotFound.Error(),
+		e.Method,
+		e.Function,
+		e.Filename,
+	)
+}
+
+func (e *InsertionPointError) Unwrap() error {
+	return ErrInsertionPointNotFound
+}
+
 // AddImportToGoFile add an import package call to a go file
 // Returns the package name that can be used in calls
 func AddImportToGoFile(
@@ -26,8 +47,7 @@ func AddImportToGoFile(
 		parts := strings.Split(packageName, "/")
 		pkgName = parts[len(parts)-1]
 	}
-	fset := token.NewFileSet()
-	astFile, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	editor, err := newSourceEditor(filename)
 	if err != nil {
 		return pkgName, err
 	}
@@ -36,7 +56,7 @@ func AddImportToGoFile(
 	basePkgName := pkgName
 	for {
 		breakAfterLoop := true
-		for _, imp := range astFile.Imports {
+		for _, imp := range editor.file.Imports {
 			pkgAlias := ""
 			if imp.Name != nil {
 				pkgAlias = imp.Name.Name
@@ -61,143 +81,42 @@ func AddImportToGoFile(
 		}
 	}
 
-	if alias == "" {
-		astutil.AddImport(fset, astFile, packageName)
-	} else {
-		astutil.AddNamedImport(fset, astFile, alias, packageName)
-	}
-
-	ast.SortImports(fset, astFile)
-	var output []byte
-	buffer := bytes.NewBuffer(output)
-	if err := printer.Fprint(buffer, fset, astFile); err != nil {
-		return alias, err
-	}
-	return alias, os.WriteFile(filename, buffer.Bytes(), 0644)
-}
-
-func AddModuleToEntrypoint(
-	packagePath string,
-	filename string,
-) error {
-	fset := token.NewFileSet()
-
-	astFile, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
-	if err != nil {
-		return err
-	}
-
-	imports := astutil.Imports(fset, astFile)
-
-	for _, specs := range imports {
-		for _, spec := range specs {
-			if spec.Path.Value == "\""+packagePath+"\"" {
-				return nil
-			}
-		}
-	}
-
-	alias, err := getUniqAlias(packagePath, 0, imports)
-	if err != nil {
-		return err
-	}
-	if alias == getDefPkgName(packagePath) {
-		astutil.AddImport(fset, astFile, packagePath)
-	} else {
-		astutil.AddNamedImport(fset, astFile, alias, packagePath)
-	}
-
-	astutil.Apply(astFile, initImportedModule(alias), nil)
-
-	var output []byte
-	buffer := bytes.NewBuffer(output)
-	if err := printer.Fprint(buffer, fset, astFile); err != nil {
-		return err
-	}
-	source, err := format.Source(buffer.Bytes())
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(filename, source, 0644)
+	editor.addImport(alias, packageName)
+	return alias, editor.save()
 }
 
-func initImportedModule(alias string) astutil.ApplyFunc {
-	return func(cursor *astutil.Cursor) bool {
-		//add a value to a slice with name s
-		if cursor.Name() == "Body" {
-			body, ok := cursor.Node().(*ast.BlockStmt)
+func isAliasUsed(astFile *ast.File, alias string) bool {
+	used := false
+	ast.Inspect(
+		astFile, func(node ast.Node) bool {
+			sel, ok := node.(*ast.SelectorExpr)
 			if !ok {
-				return true
+				return !used
 			}
-			for _, stmt := range body.List {
-				astmt, ok := stmt.(*ast.AssignStmt)
-				if !ok {
-					continue
-				}
-				expr, ok := astmt.Lhs[0].(*ast.Ident)
-				if !ok {
-					continue
-				}
-				if expr.Name == "modules" {
-					arExpr, ok := astmt.Rhs[0].(*ast.CompositeLit)
-					if !ok {
-						continue
-					}
-					if isImportInitialized(alias, arExpr) {
-						break
-					}
-					arExpr.Elts = append(
-						arExpr.Elts,
-						&ast.BasicLit{
-							Kind:  token.STRING,
-							Value: "\n" + alias + ".NewModule(),\n",
-						},
-					)
-					break
-				}
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == alias {
+				used = true
 			}
-		}
-		return true
-	}
+			return !used
+		},
+	)
+	return used
 }
 
-func isImportInitialized(alias string, arExpr *ast.CompositeLit) bool {
-	for _, elt := range arExpr.Elts {
-		v, ok := elt.(*ast.CallExpr)
-		if !ok {
-			continue
-		}
-		buildFxExpr, ok := v.Fun.(*ast.SelectorExpr)
-		if !ok {
-			continue
-		}
-		if buildFxExpr.Sel.Name != "BuildFx" {
-			continue
-		}
-
-		newModuleExprX, ok := buildFxExpr.X.(*ast.CallExpr)
-		if !ok {
-			continue
-		}
-		newModuleExpr, ok := newModuleExprX.Fun.(*ast.SelectorExpr)
-		if !ok {
-			continue
-		}
-		if newModuleExpr.Sel.Name != "NewModule" {
-			continue
-		}
-
-		ident, ok := newModuleExpr.X.(*ast.Ident)
-		if !ok {
-			continue
-		}
-
-		if ident.Name == alias {
-			return true
+// rootIdentName returns the name of the identifier the call chain starts from.
+// For example, it returns "cli" for the expression cli.NewModule().InitConfig(cfg)
+func rootIdentName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.CallExpr:
+			expr = e.Fun
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
 		}
 	}
-
-	return false
 }
 
 func getDefPkgName(packagePath string) string {
@@ -261,111 +180,308 @@ func addConstructor(
 	filename string,
 	extendedMethodName string,
 ) error {
-	fset := token.NewFileSet()
-
-	astFile, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	editor, err := newSourceEditor(filename)
 	if err != nil {
 		return err
 	}
 
-	imports := astutil.Imports(fset, astFile)
-
-	alias, err := getUniqAlias(packagePath, 0, imports)
+	alias, err := getUniqAlias(packagePath, 0, [][]*ast.ImportSpec{editor.file.Imports})
 	if err != nil {
 		return err
 	}
+
+	fn := findModuleConstructor(editor.file)
+	if fn == nil {
+		return &InsertionPointError{Filename: filename, Function: "NewModule", Method: extendedMethodName}
+	}
+	if !injectConstructor(editor, fn, alias+"."+constructor, extendedMethodName) {
+		return &InsertionPointError{Filename: filename, Function: fn.Name.Name, Method: extendedMethodName}
+	}
+
 	if alias == getDefPkgName(packagePath) {
-		astutil.AddImport(fset, astFile, packagePath)
+		editor.addImport("", packagePath)
 	} else {
-		astutil.AddNamedImport(fset, astFile, alias, packagePath)
+		editor.addImport(alias, packagePath)
+	}
+	return editor.save()
+}
+
+// findModuleConstructor returns the NewModule function of the file
+// or the first function returning a module if there is no NewModule function
+func findModuleConstructor(astFile *ast.File) *ast.FuncDecl {
+	var res *ast.FuncDecl
+	for _, decl := range astFile.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || fn.Recv != nil {
+			continue
+		}
+		if fn.Name.Name == "NewModule" {
+			return fn
+		}
+		if res == nil && returnsModule(fn) {
+			res = fn
+		}
 	}
+	return res
+}
 
-	//astFile.
-	astutil.Apply(astFile, addProvider(alias, constructor, extendedMethodName), nil)
+// returnsModule checks if the function returns only *module.Module
+func returnsModule(fn *ast.FuncDecl) bool {
+	if fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+		return false
+	}
+	return isModulePointer(fn.Type.Results.List[0].Type)
+}
 
-	var output []byte
-	buffer := bytes.NewBuffer(output)
-	if err := printer.Fprint(buffer, fset, astFile); err != nil {
-		return err
+// isModulePointer checks if the type expression is *module.Module
+func isModulePointer(expr ast.Expr) bool {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
 	}
-	source, err := format.Source(buffer.Bytes())
-	if err != nil {
-		return err
+	switch t := star.X.(type) {
+	case *ast.SelectorExpr:
+		return t.Sel.Name == "Module"
+	case *ast.Ident:
+		return t.Name == "Module"
 	}
-	return os.WriteFile(filename, source, 0644)
+	return false
 }
 
-func addProvider(alias string, constructor string, extendedMethodName string) astutil.ApplyFunc {
-	return func(cursor *astutil.Cursor) bool {
-		//add a value to a slice with name s
-		if cursor.Name() == "Body" {
-			body, ok := cursor.Node().(*ast.BlockStmt)
-			if !ok {
-				return true
+// moduleChainOrder is the order of the module methods calls used to insert a missing call to the chain
+var moduleChainOrder = map[string]int{
+	"NewModule":       0,
+	"AddDependencies": 1,
+	"AddProviders":    2,
+	"AddCliCommands":  3,
+}
+
+// injectConstructor adds the constructor to the call of the module method in the function.
+// If the module is built in several statements, the calls of all statements are checked.
+// The missing method call is inserted to the chain started from NewModule.
+// Returns false if there is no place to add the constructor.
+func injectConstructor(
+	editor *sourceEditor,
+	fn *ast.FuncDecl,
+	element string,
+	extendedMethodName string,
+) bool {
+	chains := moduleChains(editor.file, fn.Body)
+	for _, chain := range chains {
+		call := findCallInChain(chain, extendedMethodName)
+		if call == nil {
+			continue
+		}
+		return appendToCall(editor, fn.Body, call, element)
+	}
+
+	for _, chain := range chains {
+		calls := chainCalls(chain)
+		if len(calls) == 0 || calls[len(calls)-1].method != "NewModule" {
+			continue
+		}
+		// the calls go from the last one, so the first suitable call is the closest to the end of the chain
+		anchor := -1
+		for i, c := range calls {
+			rank, ok := moduleChainOrder[c.method]
+			if ok && rank < moduleChainOrder[extendedMethodName] {
+				anchor = i
+				break
+			}
+		}
+		if anchor == -1 {
+			continue
+		}
+		insertChainCall(editor, calls, anchor, extendedMethodName, element)
+		return true
+	}
+	return false
+}
+
+// moduleChains returns the expressions building the module returned from the function body.
+// For the returned builder variable, e.g. return m, all expressions assigned to the variable
+// in the function or in the package and the method calls on it are returned as well:
+//
+//	m := module.NewModule("example")
+//	var m = module.NewModule("example")
+//	m = m.AddProviders()
+//	m.AddCliCommands()
+func moduleChains(astFile *ast.File, body *ast.BlockStmt) []ast.Expr {
+	var result ast.Expr
+	for _, stmt := range body.List {
+		rstmt, ok := stmt.(*ast.ReturnStmt)
+		if ok && len(rstmt.Results) == 1 {
+			result = rstmt.Results[0]
+		}
+	}
+	if result == nil {
+		return nil
+	}
+
+	variable := rootIdentName(result)
+	chains := make([]ast.Expr, 0)
+	for _, stmt := range body.List {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			if len(s.Lhs) == 1 && len(s.Rhs) == 1 && identName(s.Lhs[0]) == variable {
+				chains = append(chains, s.Rhs[0])
 			}
-			//check if the new module is returned from function
-			// func NewModule() *Module {
-			// 	return module.NewModule().AddProvider()
-			// }
-			for _, stmt := range body.List {
-				rstmt, ok := stmt.(*ast.ReturnStmt)
-				if !ok {
-					continue
-				}
-				if len(rstmt.Results) == 1 {
-					nextNode := rstmt.Results[0]
-					if injectConstructorToAddProviders(nextNode, alias, constructor, extendedMethodName) {
-						return false
-					}
-				}
+		case *ast.DeclStmt:
+			chains = append(chains, varValues(s.Decl, variable)...)
+		case *ast.ExprStmt:
+			if _, ok := s.X.(*ast.CallExpr); ok && rootIdentName(s.X) == variable {
+				chains = append(chains, s.X)
 			}
+		}
+	}
+	if len(chains) == 0 && variable != "" {
+		// the module can be built in a package variable
+		for _, decl := range astFile.Decls {
+			chains = append(chains, varValues(decl, variable)...)
+		}
+	}
+	return append(chains, result)
+}
 
-			// check if the new module is saved into the variable
-			// m := module.NewModule().AddProvider()
-			// return m
-			for _, stmt := range body.List {
-				astmt, ok := stmt.(*ast.AssignStmt)
-				if !ok {
-					continue
-				}
-
-				nextNode := astmt.Rhs[0]
-				if injectConstructorToAddProviders(nextNode, alias, constructor, extendedMethodName) {
-					return false
-				}
+// varValues returns the values assigned to the variable in the var declaration
+func varValues(decl ast.Decl, variable string) []ast.Expr {
+	genDecl, ok := decl.(*ast.GenDecl)
+	if !ok || genDecl.Tok != token.VAR {
+		return nil
+	}
+	res := make([]ast.Expr, 0)
+	for _, spec := range genDecl.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		for i, name := range valueSpec.Names {
+			if name.Name == variable && i < len(valueSpec.Values) {
+				res = append(res, valueSpec.Values[i])
 			}
 		}
+	}
+	return res
+}
+
+func identName(expr ast.Expr) string {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return ident.Name
+}
+
+// appendToCall adds the element to the arguments of the call.
+// If the arguments are passed from a variable, e.g. AddProviders(providers...),
+// the element is added to the slice literal assigned to the variable in the function or in the package.
+func appendToCall(editor *sourceEditor, body *ast.BlockStmt, call *ast.CallExpr, element string) bool {
+	if !call.Ellipsis.IsValid() {
+		editor.appendToList(call.Args, call.Lparen, call.Rparen, element)
 		return true
 	}
+	variable := identName(call.Args[len(call.Args)-1])
+	if variable == "" {
+		return false
+	}
+	values := make([]ast.Expr, 0)
+	for _, stmt := range body.List {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			if len(s.Lhs) == 1 && len(s.Rhs) == 1 && identName(s.Lhs[0]) == variable {
+				values = append(values, s.Rhs[0])
+			}
+		case *ast.DeclStmt:
+			values = append(values, varValues(s.Decl, variable)...)
+		}
+	}
+	if len(values) == 0 {
+		for _, decl := range editor.file.Decls {
+			values = append(values, varValues(decl, variable)...)
+		}
+	}
+	for _, value := range values {
+		lit, ok := value.(*ast.CompositeLit)
+		if ok {
+			editor.appendToList(lit.Elts, lit.Lbrace, lit.Rbrace, element)
+			return true
+		}
+	}
+	return false
+}
+
+// chainCall is a method call in the chain with the selector of the next call if it exists
+type chainCall struct {
+	method string
+	call   *ast.CallExpr
+	next   *ast.SelectorExpr
 }
 
-func injectConstructorToAddProviders(
+// chainCalls returns the calls of the chain from the last one to the first one
+func chainCalls(expr ast.Expr) []chainCall {
+	res := make([]chainCall, 0)
+	var next *ast.SelectorExpr
+	for {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return res
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return res
+		}
+		res = append(res, chainCall{method: sel.Sel.Name, call: call, next: next})
+		next = sel
+		expr = sel.X
+	}
+}
+
+// insertChainCall inserts the method call with the element after the anchor call of the chain.
+// The call is placed on a new line if the chain is multiline.
+func insertChainCall(editor *sourceEditor, calls []chainCall, anchor int, method string, element string) {
+	anchorCall := calls[anchor]
+	// the selector placed on a new line shows that the chain is multiline
+	sel := anchorCall.next
+	prevEnd := anchorCall.call.End()
+	if sel == nil {
+		sel = anchorCall.call.Fun.(*ast.SelectorExpr)
+		prevEnd = sel.X.End()
+	}
+	if editor.line(sel.Sel.Pos()) == editor.line(prevEnd) {
+		editor.insert(editor.offset(anchorCall.call.End()), "."+method+"("+element+")")
+		return
+	}
+	ind := editor.indent(editor.offset(sel.Sel.Pos()))
+	editor.insert(
+		editor.offset(anchorCall.call.End()),
+		".\n"+ind+method+"(\n"+ind+"\t"+element+",\n"+ind+")",
+	)
+}
+
+// findCallInChain returns the call of the method in the chain of calls, e.g. AddProviders in
+// module.NewModule("example").AddProviders().AddCliCommands()
+func findCallInChain(
 	nextNode ast.Expr,
-	alias string,
-	constructor string,
 	extendedMethodName string,
-) bool {
+) *ast.CallExpr {
 	for {
 		callExpr, ok := nextNode.(*ast.CallExpr)
 		if !ok {
-			return false
+			return nil
 		}
 		selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
 		if !ok {
-			return false
+			return nil
 		}
 		if selectorExpr.Sel.Name == extendedMethodName {
-			position := callExpr.Rparen
-			callExpr.Args = append(
-				callExpr.Args,
-				&ast.SelectorExpr{
-					X:   &ast.Ident{Name: alias, NamePos: position},
-					Sel: &ast.Ident{Name: constructor + ",\n", NamePos: position},
-				},
-			)
-
-			return true
+			return callExpr
 		}
 		nextNode = selectorExpr.X
 	}
 }
+
+// parseFile parses the go file with comments taking into account the changes made in the dry run mode
+func parseFile(fset *token.FileSet, filename string) (*ast.File, error) {
+	src, err := fsys.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseFile(fset, filename, src, parser.ParseComments)
+}
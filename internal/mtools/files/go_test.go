@@ -298,6 +298,131 @@ func TestAddModuleToEntrypoint(t *testing.T) {
 	)
 }
 
+func TestRemoveModuleFromEntrypoint(t *testing.T) {
+	t.Run(
+		"Remove an added module from the CLI entrypoint", func(t *testing.T) {
+			fn := fmt.Sprintf("/tmp/%s.go", randstr.String(10))
+			err := os.WriteFile(fn, []byte(entrypointContent), 0644)
+			defer os.Remove(fn)
+			if err != nil {
+				t.Fatal("Cannot create "+fn+" file", err)
+			}
+			err = files.AddModuleToEntrypoint(
+				"github.com/stretchr/testify",
+				fn,
+			)
+			require.NoError(t, err)
+
+			err = files.RemoveModuleFromEntrypoint(
+				"github.com/stretchr/testify",
+				fn,
+			)
+			require.NoError(t, err)
+			fc, err := os.ReadFile(fn)
+			require.NoError(t, err)
+
+			t.Log("Given a module added to the entrypoint")
+			t.Log("When remove the module from the go file")
+			t.Log("	The module should be removed from the array of imported modules")
+			assert.NotContains(t, string(fc), "testify.NewModule()")
+			t.Log("	The import should be removed from the go file")
+			assert.NotContains(t, string(fc), "\"github.com/stretchr/testify\"")
+			t.Log("	Other modules should stay untouched")
+			assert.Contains(t, string(fc), "cli.NewModule(")
+		},
+	)
+
+	t.Run(
+		"Remove a module initialized with a config", func(t *testing.T) {
+			fn := fmt.Sprintf("/tmp/%s.go", randstr.String(10))
+			err := os.WriteFile(fn, []byte(entrypointContent), 0644)
+			defer os.Remove(fn)
+			if err != nil {
+				t.Fatal("Cannot create "+fn+" file", err)
+			}
+
+			err = files.RemoveModuleFromEntrypoint(
+				"github.com/go-modulus/modulus/cli",
+				fn,
+			)
+			require.NoError(t, err)
+			fc, err := os.ReadFile(fn)
+			require.NoError(t, err)
+
+			t.Log("Given a module initialized with the config in the entrypoint")
+			t.Log("When remove the module from the go file")
+			t.Log("	The whole initialization should be removed from the array of imported modules")
+			assert.NotContains(t, string(fc), "cli.NewModule(")
+			assert.NotContains(t, string(fc), "cli.ModuleConfig")
+			t.Log("	The import should be kept because the package is still used in the file")
+			assert.Contains(t, string(fc), "\"github.com/go-modulus/modulus/cli\"")
+			assert.Contains(t, string(fc), "fx.Invoke(cli.Start)")
+		},
+	)
+
+	t.Run(
+		"Do nothing if the module is not imported", func(t *testing.T) {
+			fn := fmt.Sprintf("/tmp/%s.go", randstr.String(10))
+			err := os.WriteFile(fn, []byte(entrypointContent), 0644)
+			defer os.Remove(fn)
+			if err != nil {
+				t.Fatal("Cannot create "+fn+" file", err)
+			}
+
+			err = files.RemoveModuleFromEntrypoint(
+				"github.com/stretchr/testify",
+				fn,
+			)
+			require.NoError(t, err)
+			fc, err := os.ReadFile(fn)
+			require.NoError(t, err)
+
+			t.Log("Given an entrypoint without the module")
+			t.Log("When remove the module from the go file")
+			t.Log("	The file should not be changed")
+			assert.Equal(t, entrypointContent, string(fc))
+		},
+	)
+}
+
+func TestIsModuleInEntrypoint(t *testing.T) {
+	t.Run(
+		"Find modules in the entrypoint", func(t *testing.T) {
+			fn := fmt.Sprintf("/tmp/%s.go", randstr.String(10))
+			err := os.WriteFile(fn, []byte(entrypointContent), 0644)
+			defer os.Remove(fn)
+			if err != nil {
+				t.Fatal("Cannot create "+fn+" file", err)
+			}
+			err = files.AddModuleToEntrypoint(
+				"github.com/stretchr/testify",
+				fn,
+			)
+			require.NoError(t, err)
+
+			added, errAdded := files.IsModuleInEntrypoint("github.com/stretchr/testify", fn)
+			withConfig, errWithConfig := files.IsModuleInEntrypoint("github.com/go-modulus/modulus/cli", fn)
+			notInitialized, errNotInitialized := files.IsModuleInEntrypoint("go.uber.org/zap", fn)
+			notImported, errNotImported := files.IsModuleInEntrypoint("github.com/go-modulus/modulus/db/pgx", fn)
+
+			t.Log("Given an entrypoint with initialized modules")
+			t.Log("When check if the modules are in the entrypoint")
+			require.NoError(t, errAdded)
+			require.NoError(t, errWithConfig)
+			require.NoError(t, errNotInitialized)
+			require.NoError(t, errNotImported)
+			t.Log("	The added module should be found")
+			assert.True(t, added)
+			t.Log("	The module initialized with a config should be found")
+			assert.True(t, withConfig)
+			t.Log("	The imported package without the module initialization should not be found")
+			assert.False(t, notInitialized)
+			t.Log("	The not imported package should not be found")
+			assert.False(t, notImported)
+		},
+	)
+}
+
 func TestAddConstructorToProvider(t *testing.T) {
 	t.Run(
 		"add provider to the empty AddProviders() function", func(t *testing.T) {
@@ -427,3 +552,43 @@ func TestAddConstructorToProvider(t *testing.T) {
 		},
 	)
 }
+
+func TestAddConstructorToProvider_InsertionPointNotFound(t *testing.T) {
+	t.Run(
+		"return a typed error if the module is not built with NewModule", func(t *testing.T) {
+			content := `package example
+
+import "github.com/go-modulus/modulus/module"
+
+func NewModule() *module.Module {
+	return buildModule()
+}
+`
+			fn := fmt.Sprintf("/tmp/%s.go", randstr.String(10))
+			err := os.WriteFile(fn, []byte(content), 0644)
+			defer os.Remove(fn)
+			if err != nil {
+				t.Fatal("Cannot create "+fn+" file", err)
+			}
+			err = files.AddConstructorToProvider(
+				"github.com/stretchr/testify",
+				"NewTestProvider",
+				fn,
+			)
+			fc, errRead := os.ReadFile(fn)
+			require.NoError(t, errRead)
+
+			t.Log("Given a module constructor without the NewModule call chain")
+			t.Log("When new provider is added to the module")
+			t.Log("	The error should name the file and the function")
+			var insertionErr *files.InsertionPointError
+			require.ErrorAs(t, err, &insertionErr)
+			require.ErrorIs(t, err, files.ErrInsertionPointNotFound)
+			assert.Equal(t, fn, insertionErr.Filename)
+			assert.Equal(t, "NewModule", insertionErr.Function)
+			assert.Equal(t, "AddProviders", insertionErr.Method)
+			t.Log("	The file should not be changed")
+			assert.Equal(t, content, string(fc))
+		},
+	)
+}
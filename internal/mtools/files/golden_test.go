@@ -0,0 +1,187 @@
+package files_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/files"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the testdata folder")
+
+// TestGolden changes the testdata/<name>.input files and compares the result with the testdata/<name>.golden files.
+// Run `go test ./internal/mtools/files -update` to regenerate the golden files after reviewing the changes.
+func TestGolden(t *testing.T) {
+	cases := []struct {
+		name   string
+		reason string
+		change func(filename string) error
+	}{
+		{
+			name:   "add_provider_comments",
+			reason: "The comments, blank lines and multiline arguments of AddProviders should be kept",
+			change: func(filename string) error {
+				return files.AddConstructorToProvider("github.com/org/project/internal/example/api", "NewHandler", filename)
+			},
+		},
+		{
+			name:   "add_provider_single_line",
+			reason: "The provider should be added to the same line if AddProviders is written in one line",
+			change: func(filename string) error {
+				return files.AddConstructorToProvider("github.com/org/project/internal/example/api", "NewHandler", filename)
+			},
+		},
+		{
+			name:   "add_provider_empty",
+			reason: "The empty AddProviders() call should become multiline",
+			change: func(filename string) error {
+				return files.AddConstructorToProvider("github.com/org/project/internal/example/api", "NewHandler", filename)
+			},
+		},
+		{
+			name:   "add_provider_template",
+			reason: "The not formatted module.go generated from the template should keep its layout",
+			change: func(filename string) error {
+				return files.AddConstructorToProvider("github.com/org/project/internal/example/cli", "NewHello", filename)
+			},
+		},
+		{
+			name:   "add_cli_command",
+			reason: "The commented out commands should stay above the new command",
+			change: func(filename string) error {
+				return files.AddCliCommand("github.com/org/project/internal/example/cli", "NewWorldCommand", filename)
+			},
+		},
+		{
+			name:   "add_provider_missing_call",
+			reason: "The missing AddProviders call should be inserted after AddDependencies",
+			change: func(filename string) error {
+				return files.AddConstructorToProvider("github.com/org/project/internal/example/api", "NewHandler", filename)
+			},
+		},
+		{
+			name:   "add_cli_command_missing_call",
+			reason: "The missing AddCliCommands call should be inserted after AddProviders in the same line",
+			change: func(filename string) error {
+				return files.AddCliCommand("github.com/org/project/internal/example/cli", "NewHelloCommand", filename)
+			},
+		},
+		{
+			name:   "add_provider_builder_variable",
+			reason: "The missing AddProviders call should be inserted to the NewModule call assigned to the builder variable",
+			change: func(filename string) error {
+				return files.AddConstructorToProvider("github.com/org/project/internal/example/api", "NewHandler", filename)
+			},
+		},
+		{
+			name:   "add_provider_helper_variable",
+			reason: "The constructors should be added to the slices of the helper variables",
+			change: func(filename string) error {
+				err := files.AddConstructorToProvider("github.com/org/project/internal/example/api", "NewHandler", filename)
+				if err != nil {
+					return err
+				}
+				return files.AddCliCommand("github.com/org/project/internal/example/cli", "NewWorldCommand", filename)
+			},
+		},
+		{
+			name:   "add_module_to_entrypoint",
+			reason: "The comments of the modules slice should be kept",
+			change: func(filename string) error {
+				return files.AddModuleToEntrypoint("github.com/go-modulus/modulus/db/pgx", filename)
+			},
+		},
+		{
+			name:   "add_module_to_package_var",
+			reason: "The module should be added to the package variable",
+			change: func(filename string) error {
+				return files.AddModuleToEntrypoint("github.com/go-modulus/modulus/db/pgx", filename)
+			},
+		},
+		{
+			name:   "add_module_to_append",
+			reason: "The module should be added to the first append call if the slice has no literal",
+			change: func(filename string) error {
+				return files.AddModuleToEntrypoint("github.com/go-modulus/modulus/db/pgx", filename)
+			},
+		},
+		{
+			name:   "add_module_to_modules_func",
+			reason: "The module should be added to the slice returned from the function",
+			change: func(filename string) error {
+				return files.AddModuleToEntrypoint("github.com/go-modulus/modulus/db/pgx", filename)
+			},
+		},
+		{
+			name:   "add_module_imported_not_initialized",
+			reason: "The module of the imported package should be added with the existing alias",
+			change: func(filename string) error {
+				return files.AddModuleToEntrypoint("github.com/go-modulus/modulus/db/pgx", filename)
+			},
+		},
+		{
+			name:   "add_existing_module_to_entrypoint",
+			reason: "The file should not be changed if the module is already in the slice",
+			change: func(filename string) error {
+				return files.AddModuleToEntrypoint("github.com/go-modulus/modulus/logger", filename)
+			},
+		},
+		{
+			name:   "remove_module_from_append",
+			reason: "The append call left without modules should be removed",
+			change: func(filename string) error {
+				return files.RemoveModuleFromEntrypoint("github.com/go-modulus/modulus/pprof", filename)
+			},
+		},
+		{
+			name:   "remove_module_from_entrypoint",
+			reason: "The module should be removed with its trailing comment, the comments of other modules should be kept",
+			change: func(filename string) error {
+				return files.RemoveModuleFromEntrypoint("github.com/go-modulus/modulus/db/pgx", filename)
+			},
+		},
+		{
+			name:   "add_import",
+			reason: "The import should be added to the sorted group with the most similar packages",
+			change: func(filename string) error {
+				_, err := files.AddImportToGoFile("github.com/stretchr/testify", "_", filename)
+				if err != nil {
+					return err
+				}
+				_, err = files.AddImportToGoFile("os", "", filename)
+				return err
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(
+			c.name, func(t *testing.T) {
+				input, err := os.ReadFile(filepath.Join("testdata", c.name+".input"))
+				require.NoError(t, err)
+				fn := filepath.Join(t.TempDir(), c.name+".go")
+				require.NoError(t, os.WriteFile(fn, input, 0644))
+
+				err = c.change(fn)
+				require.NoError(t, err)
+				output, err := os.ReadFile(fn)
+				require.NoError(t, err)
+
+				goldenFile := filepath.Join("testdata", c.name+".golden")
+				if *update {
+					require.NoError(t, os.WriteFile(goldenFile, output, 0644))
+				}
+				golden, err := os.ReadFile(goldenFile)
+				require.NoError(t, err)
+
+				t.Log("Given the " + c.name + ".input file")
+				t.Log("When change the file")
+				t.Log("	" + c.reason)
+				require.Equal(t, string(golden), string(output))
+			},
+		)
+	}
+}
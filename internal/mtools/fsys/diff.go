@@ -0,0 +1,156 @@
+package fsys
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// diffContext is a number of the unchanged lines shown around the changes
+const diffContext = 3
+
+type diffLine struct {
+	kind byte
+	text string
+	// old and new are the indexes of the line in the old and new content before the line is applied
+	old, new int
+}
+
+// PrintDiff prints the unified diff of all files changed in the dry run mode
+func PrintDiff(w io.Writer) error {
+	changes := Changes()
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, color.YellowString("Dry run: no files would be changed."))
+		return err
+	}
+	for _, change := range changes {
+		_, err := io.WriteString(w, UnifiedDiff(change))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, color.YellowString("Dry run: %d files would be changed.", len(changes)))
+	return err
+}
+
+// UnifiedDiff returns the colored unified diff of the change
+func UnifiedDiff(change Change) string {
+	oldName := "a/" + change.Name
+	newName := "b/" + change.Name
+	if !change.Existed {
+		oldName = "/dev/null"
+	}
+	if change.Removed {
+		newName = "/dev/null"
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(color.New(color.Bold).Sprintf("--- %s\n+++ %s\n", oldName, newName))
+	lines := diffLines(splitLines(change.Before), splitLines(change.After))
+	for _, hunk := range hunks(lines) {
+		first := hunk[0]
+		oldCount, newCount := 0, 0
+		for _, line := range hunk {
+			if line.kind != '+' {
+				oldCount++
+			}
+			if line.kind != '-' {
+				newCount++
+			}
+		}
+		sb.WriteString(
+			color.CyanString(
+				"@@ -%s +%s @@\n",
+				hunkRange(first.old, oldCount),
+				hunkRange(first.new, newCount),
+			),
+		)
+		for _, line := range hunk {
+			text := string(line.kind) + line.text + "\n"
+			switch line.kind {
+			case '-':
+				text = color.RedString("%s", text)
+			case '+':
+				text = color.GreenString("%s", text)
+			}
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// diffLines finds the longest common subsequence of the lines and marks the rest as removed or added
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	res := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			res = append(res, diffLine{kind: ' ', text: a[i], old: i, new: j})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			res = append(res, diffLine{kind: '+', text: b[j], old: i, new: j})
+			j++
+		default:
+			res = append(res, diffLine{kind: '-', text: a[i], old: i, new: j})
+			i++
+		}
+	}
+	return res
+}
+
+// hunks groups the changed lines with diffContext unchanged lines around them.
+// The groups closer than two contexts to each other are merged.
+func hunks(lines []diffLine) [][]diffLine {
+	res := make([][]diffLine, 0)
+	start, end := -1, -1
+	for i, line := range lines {
+		if line.kind == ' ' {
+			continue
+		}
+		from := max(i-diffContext, 0)
+		if start != -1 && from > end {
+			res = append(res, lines[start:end])
+			start = -1
+		}
+		if start == -1 {
+			start = from
+		}
+		end = min(i+diffContext+1, len(lines))
+	}
+	if start != -1 {
+		res = append(res, lines[start:end])
+	}
+	return res
+}
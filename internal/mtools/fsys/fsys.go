@@ -0,0 +1,318 @@
+package fsys
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// file is a state of a project file changed in the dry run mode
+type file struct {
+	// name is the path the file was touched by for the first time, it is used in the diff
+	name     string
+	original []byte
+	existed  bool
+	content  []byte
+	removed  bool
+}
+
+// overlay keeps the changed files in memory instead of the disk.
+// mtools runs one command per process, so the state is global for the whole process.
+var overlay = struct {
+	sync.Mutex
+	dryRun bool
+	files  map[string]*file
+	dirs   map[string]struct{}
+	order  []string
+}{
+	files: make(map[string]*file),
+	dirs:  make(map[string]struct{}),
+}
+
+// Change is a difference between the file on the disk and the file after the command
+type Change struct {
+	Name    string
+	Before  []byte
+	After   []byte
+	Existed bool
+	Removed bool
+}
+
+// EnableDryRun switches all writes of the package to the memory.
+// The project files are left untouched, the changes can be printed with PrintDiff.
+func EnableDryRun() {
+	overlay.Lock()
+	defer overlay.Unlock()
+	overlay.dryRun = true
+	overlay.files = make(map[string]*file)
+	overlay.dirs = make(map[string]struct{})
+	overlay.order = nil
+}
+
+func IsDryRun() bool {
+	overlay.Lock()
+	defer overlay.Unlock()
+	return overlay.dryRun
+}
+
+// ReadFile returns the content of the file taking into account the changes made in the dry run mode
+func ReadFile(name string) ([]byte, error) {
+	overlay.Lock()
+	defer overlay.Unlock()
+	if f, ok := overlay.files[key(name)]; ok && overlay.dryRun {
+		if f.removed {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		return append([]byte(nil), f.content...), nil
+	}
+	return os.ReadFile(name)
+}
+
+// WriteFile writes the file to the disk or keeps it in memory in the dry run mode
+func WriteFile(name string, data []byte, perm os.FileMode) error {
+	overlay.Lock()
+	defer overlay.Unlock()
+	if !overlay.dryRun {
+		return os.WriteFile(name, data, perm)
+	}
+	f := touch(name)
+	f.content = append([]byte(nil), data...)
+	f.removed = false
+	return nil
+}
+
+// WriteFileAtomic writes the data to a temporary file next to the named one and renames it over the file,
+// so an interrupted write never leaves a partially written file. The mode of the existing file is kept.
+// In the dry run mode it works as WriteFile.
+func WriteFileAtomic(name string, data []byte, perm os.FileMode) error {
+	overlay.Lock()
+	defer overlay.Unlock()
+	if !overlay.dryRun {
+		return writeAtomic(name, data, perm)
+	}
+	f := touch(name)
+	f.content = append([]byte(nil), data...)
+	f.removed = false
+	return nil
+}
+
+func writeAtomic(name string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(name); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpName, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpName, name)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// MkdirAll creates the directory on the disk or remembers it in the dry run mode
+func MkdirAll(path string, perm os.FileMode) error {
+	overlay.Lock()
+	defer overlay.Unlock()
+	if !overlay.dryRun {
+		return os.MkdirAll(path, perm)
+	}
+	overlay.dirs[key(path)] = struct{}{}
+	return nil
+}
+
+// Remove removes the file from the disk or marks it as removed in the dry run mode.
+// Directories are never removed in the dry run mode.
+func Remove(name string) error {
+	overlay.Lock()
+	defer overlay.Unlock()
+	if !overlay.dryRun {
+		return os.Remove(name)
+	}
+	if _, ok := overlay.files[key(name)]; !ok {
+		info, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+	}
+	f := touch(name)
+	f.content = nil
+	f.removed = true
+	return nil
+}
+
+// IsFile checks if the file exists taking into account the changes made in the dry run mode
+func IsFile(name string) bool {
+	overlay.Lock()
+	defer overlay.Unlock()
+	if f, ok := overlay.files[key(name)]; ok && overlay.dryRun {
+		return !f.removed
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return false
+	}
+	return !info.IsDir()
+}
+
+// IsDir checks if the directory exists taking into account the changes made in the dry run mode
+func IsDir(name string) bool {
+	overlay.Lock()
+	defer overlay.Unlock()
+	if overlay.dryRun {
+		path := key(name)
+		if _, ok := overlay.dirs[path]; ok {
+			return true
+		}
+		for filePath, f := range overlay.files {
+			if !f.removed && strings.HasPrefix(filePath, path+string(filepath.Separator)) {
+				return true
+			}
+		}
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return false
+	}
+	return info.IsDir()
+}
+
+// ApplyToCopy runs the function changing the file by its name.
+// It is used for the functions from other packages writing the files directly to the disk.
+// In the dry run mode the function gets a temporary copy of the file, and the result is kept in memory.
+func ApplyToCopy(name string, fn func(filename string) error) error {
+	if !IsDryRun() {
+		return fn(name)
+	}
+	dir, err := os.MkdirTemp("", "mtools-dry-run")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	tmpFile := filepath.Join(dir, filepath.Base(name))
+	content, err := ReadFile(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		err = os.WriteFile(tmpFile, content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	err = fn(tmpFile)
+	if err != nil {
+		return err
+	}
+	content, err = os.ReadFile(tmpFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return WriteFile(name, content, 0644)
+}
+
+// Run runs the external command. The command is skipped in the dry run mode
+// because its changes cannot be caught by the overlay.
+func Run(cmd *exec.Cmd) error {
+	if !IsDryRun() {
+		return cmd.Run()
+	}
+	fmt.Println(color.YellowString("Dry run: skipped %s", strings.Join(cmd.Args, " ")))
+	return nil
+}
+
+// Changes returns the files changed in the dry run mode in the order they were touched.
+// The files written with the same content are skipped.
+func Changes() []Change {
+	overlay.Lock()
+	defer overlay.Unlock()
+	res := make([]Change, 0, len(overlay.order))
+	for _, path := range overlay.order {
+		f := overlay.files[path]
+		if f.removed && !f.existed {
+			continue
+		}
+		if !f.removed && f.existed && string(f.original) == string(f.content) {
+			continue
+		}
+		res = append(
+			res, Change{
+				Name:    f.name,
+				Before:  f.original,
+				After:   f.content,
+				Existed: f.existed,
+				Removed: f.removed,
+			},
+		)
+	}
+	return res
+}
+
+// touch returns the overlay file remembering the original content on the first call.
+// The overlay must be locked.
+func touch(name string) *file {
+	path := key(name)
+	if f, ok := overlay.files[path]; ok {
+		return f
+	}
+	f := &file{name: displayName(path)}
+	content, err := os.ReadFile(name)
+	if err == nil {
+		f.original = content
+		f.content = content
+		f.existed = true
+	}
+	overlay.files[path] = f
+	overlay.order = append(overlay.order, path)
+	return f
+}
+
+func key(name string) string {
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return filepath.Clean(name)
+	}
+	return path
+}
+
+// displayName returns the path related to the current directory if the file is inside it
+func displayName(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
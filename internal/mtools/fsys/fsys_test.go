@@ -0,0 +1,106 @@
+package fsys_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	t.Run(
+		"keep the changes in memory and print the diff", func(t *testing.T) {
+			color.NoColor = true
+			projDir := t.TempDir()
+			require.NoError(t, os.WriteFile(projDir+"/main.go", []byte("package main\n\nfunc main() {\n}\n"), 0644))
+			require.NoError(t, os.WriteFile(projDir+"/old.txt", []byte("old\n"), 0644))
+
+			fsys.EnableDryRun()
+			errWrite := fsys.WriteFile(
+				projDir+"/main.go",
+				[]byte("package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"),
+				0644,
+			)
+			errMkdir := fsys.MkdirAll(projDir+"/internal/graphql", 0755)
+			errCreate := fsys.WriteFile(projDir+"/internal/graphql/module.go", []byte("package graphql\n"), 0644)
+			errRemove := fsys.Remove(projDir + "/old.txt")
+			errSame := fsys.WriteFile(projDir+"/same.txt", nil, 0644)
+			_ = fsys.Remove(projDir + "/same.txt")
+
+			overlayContent, errRead := fsys.ReadFile(projDir + "/main.go")
+			diskContent, errDisk := os.ReadFile(projDir + "/main.go")
+			_, errModuleDir := os.Stat(projDir + "/internal")
+			_, errOld := os.Stat(projDir + "/old.txt")
+			out := bytes.Buffer{}
+			errDiff := fsys.PrintDiff(&out)
+
+			t.Log("Given a project with the existing files")
+			t.Log("When change, create and remove the files in the dry run mode")
+			t.Log("	The errors should be nil")
+			require.NoError(t, errWrite)
+			require.NoError(t, errMkdir)
+			require.NoError(t, errCreate)
+			require.NoError(t, errRemove)
+			require.NoError(t, errSame)
+			require.NoError(t, errRead)
+			require.NoError(t, errDisk)
+			require.NoError(t, errDiff)
+			t.Log("	The changed file should be read from memory")
+			require.Contains(t, string(overlayContent), "println")
+			t.Log("	The files on the disk should be untouched")
+			require.NotContains(t, string(diskContent), "println")
+			require.ErrorIs(t, errModuleDir, os.ErrNotExist)
+			require.NoError(t, errOld)
+			t.Log("	The overlay should see the created directories and removed files")
+			require.True(t, fsys.IsDir(projDir+"/internal/graphql"))
+			require.True(t, fsys.IsFile(projDir+"/internal/graphql/module.go"))
+			require.False(t, fsys.IsFile(projDir+"/old.txt"))
+			t.Log("	The diff of all changed files should be printed")
+			require.Len(t, fsys.Changes(), 3)
+			require.Contains(t, out.String(), " func main() {\n+\tprintln(\"hello\")\n }\n")
+			require.Contains(t, out.String(), "@@ -1,4 +1,5 @@\n")
+			require.Contains(t, out.String(), "--- /dev/null\n")
+			require.Contains(t, out.String(), "+package graphql\n")
+			require.Contains(t, out.String(), "-old\n")
+			require.Contains(t, out.String(), "Dry run: 3 files would be changed.")
+		},
+	)
+}
+
+func TestApplyToCopy(t *testing.T) {
+	t.Run(
+		"keep the result of the function in memory", func(t *testing.T) {
+			projDir := t.TempDir()
+			require.NoError(t, os.WriteFile(projDir+"/.env", []byte("APP_ENV=dev\n"), 0644))
+
+			fsys.EnableDryRun()
+			err := fsys.ApplyToCopy(
+				projDir+"/.env", func(filename string) error {
+					f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					_, err = f.WriteString("DB_HOST=localhost\n")
+					return err
+				},
+			)
+			overlayContent, errRead := fsys.ReadFile(projDir + "/.env")
+			diskContent, errDisk := os.ReadFile(projDir + "/.env")
+
+			t.Log("Given an existing env file")
+			t.Log("When apply a function writing directly to the disk in the dry run mode")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			require.NoError(t, errRead)
+			require.NoError(t, errDisk)
+			t.Log("	The changed content should be kept in memory")
+			require.Equal(t, "APP_ENV=dev\nDB_HOST=localhost\n", string(overlayContent))
+			t.Log("	The file on the disk should be untouched")
+			require.Equal(t, "APP_ENV=dev\n", string(diskContent))
+		},
+	)
+}
@@ -0,0 +1,257 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+)
+
+// Dir is a directory inside the project where the journals of the changes made by mtools are kept
+const Dir = ".mtools/journal"
+
+const journalFile = "journal.json"
+
+// keepJournals is a number of the latest committed journals kept in the project
+const keepJournals = 10
+
+var ErrNoJournal = errors.New("there are no changes to undo")
+
+type State string
+
+const (
+	StateStarted    State = "started"
+	StateCommitted  State = "committed"
+	StateRolledBack State = "rolledBack"
+)
+
+// Entry is a snapshot of a file made before the file was changed for the first time
+type Entry struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Mode    fs.FileMode `json:"mode,omitempty"`
+	Backup  string      `json:"backup,omitempty"`
+}
+
+// Journal keeps the original state of all files touched by one mtools command.
+// It allows restoring them if the command fails or if the user wants to undo the command.
+type Journal struct {
+	ID        string    `json:"id"`
+	Command   string    `json:"command"`
+	State     State     `json:"state"`
+	CreatedAt time.Time `json:"createdAt"`
+	Entries   []Entry   `json:"entries"`
+
+	projPath string
+	entries  map[string]struct{}
+	// dryRun journals are kept only in memory because no files are changed in the dry run mode
+	dryRun bool
+}
+
+// Begin starts a new journal for the command in the project
+func Begin(projPath string, command string) (*Journal, error) {
+	now := time.Now()
+	j := &Journal{
+		ID:        now.UTC().Format("20060102150405.000000000"),
+		Command:   command,
+		State:     StateStarted,
+		CreatedAt: now,
+		Entries:   make([]Entry, 0),
+		projPath:  projPath,
+		entries:   make(map[string]struct{}),
+		dryRun:    fsys.IsDryRun(),
+	}
+	if j.dryRun {
+		return j, nil
+	}
+	err := os.MkdirAll(j.dir(), 0755)
+	if err != nil {
+		return nil, err
+	}
+	return j, j.save()
+}
+
+// Last returns the latest committed journal of the project
+func Last(projPath string) (*Journal, error) {
+	ids, err := journalIDs(projPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(ids) - 1; i >= 0; i-- {
+		j, err := load(projPath, ids[i])
+		if err != nil {
+			return nil, err
+		}
+		if j.State == StateCommitted {
+			return j, nil
+		}
+	}
+	return nil, ErrNoJournal
+}
+
+// Snapshot saves the current state of the file located by the path related to the project root.
+// Only the first snapshot of the file is kept, so the journal always restores the state before the command.
+func (j *Journal) Snapshot(path string) error {
+	path = filepath.Clean(path)
+	if _, ok := j.entries[path]; ok || j.dryRun {
+		return nil
+	}
+
+	entry := Entry{
+		Path: path,
+	}
+	info, err := os.Stat(filepath.Join(j.projPath, path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("cannot snapshot the directory %s", path)
+		}
+		content, err := os.ReadFile(filepath.Join(j.projPath, path))
+		if err != nil {
+			return err
+		}
+		entry.Existed = true
+		entry.Mode = info.Mode().Perm()
+		entry.Backup = strconv.Itoa(len(j.Entries)) + ".bak"
+		err = os.WriteFile(filepath.Join(j.dir(), entry.Backup), content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	j.entries[path] = struct{}{}
+	j.Entries = append(j.Entries, entry)
+	return j.save()
+}
+
+// Commit marks the journal as successfully finished and removes the oldest journals
+func (j *Journal) Commit() error {
+	j.State = StateCommitted
+	if j.dryRun {
+		return nil
+	}
+	err := j.save()
+	if err != nil {
+		return err
+	}
+	return cleanup(j.projPath)
+}
+
+// Rollback restores all snapshotted files in the reverse order and removes the journal
+func (j *Journal) Rollback() error {
+	if j.dryRun {
+		j.State = StateRolledBack
+		return nil
+	}
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		entry := j.Entries[i]
+		path := filepath.Join(j.projPath, entry.Path)
+		if !entry.Existed {
+			err := os.Remove(path)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			j.removeEmptyDirs(filepath.Dir(entry.Path))
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(j.dir(), entry.Backup))
+		if err != nil {
+			return err
+		}
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(path, content, entry.Mode)
+		if err != nil {
+			return err
+		}
+	}
+	j.State = StateRolledBack
+	return os.RemoveAll(j.dir())
+}
+
+// removeEmptyDirs removes the directory and its parents while they are empty.
+// It never goes out of the project root.
+func (j *Journal) removeEmptyDirs(dir string) {
+	for dir != "." && dir != "/" && dir != "" {
+		err := os.Remove(filepath.Join(j.projPath, dir))
+		if err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func (j *Journal) dir() string {
+	return filepath.Join(j.projPath, Dir, j.ID)
+}
+
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(j.dir(), journalFile), data, 0644)
+}
+
+func load(projPath string, id string) (*Journal, error) {
+	data, err := os.ReadFile(filepath.Join(projPath, Dir, id, journalFile))
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{}
+	err = json.Unmarshal(data, j)
+	if err != nil {
+		return nil, err
+	}
+	j.projPath = projPath
+	j.entries = make(map[string]struct{}, len(j.Entries))
+	for _, entry := range j.Entries {
+		j.entries[entry.Path] = struct{}{}
+	}
+	return j, nil
+}
+
+// journalIDs returns the identifiers of all journals in the project sorted from the oldest to the newest
+func journalIDs(projPath string) ([]string, error) {
+	dirs, err := os.ReadDir(filepath.Join(projPath, Dir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ids := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if dir.IsDir() {
+			ids = append(ids, dir.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func cleanup(projPath string) error {
+	ids, err := journalIDs(projPath)
+	if err != nil {
+		return err
+	}
+	for len(ids) > keepJournals {
+		err = os.RemoveAll(filepath.Join(projPath, Dir, ids[0]))
+		if err != nil {
+			return err
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package journal_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/journal"
+	"github.com/stretchr/testify/require"
+	"github.com/thanhpk/randstr"
+)
+
+func initProjDir(t *testing.T) string {
+	projDir := fmt.Sprintf("/tmp/%s", randstr.String(10))
+	err := os.Mkdir(projDir, 0755)
+	if err != nil {
+		t.Fatal("Cannot create "+projDir+" dir", err)
+	}
+	err = os.WriteFile(projDir+"/go.mod", []byte("module testproj\n"), 0644)
+	if err != nil {
+		t.Fatal("Cannot create go.mod file", err)
+	}
+	return projDir
+}
+
+func TestJournal_Rollback(t *testing.T) {
+	t.Run(
+		"restore changed and remove created files", func(t *testing.T) {
+			projDir := initProjDir(t)
+			defer os.RemoveAll(projDir)
+
+			j, err := journal.Begin(projDir, "module install")
+			require.NoError(t, err)
+			require.NoError(t, j.Snapshot("go.mod"))
+			require.NoError(t, j.Snapshot("internal/graphql/module.go"))
+
+			require.NoError(t, os.WriteFile(projDir+"/go.mod", []byte("module changed\n"), 0644))
+			require.NoError(t, os.MkdirAll(projDir+"/internal/graphql", 0755))
+			require.NoError(t, os.WriteFile(projDir+"/internal/graphql/module.go", []byte("package graphql\n"), 0644))
+
+			t.Log("	The second snapshot of the same file should not rewrite the original state")
+			require.NoError(t, j.Snapshot("go.mod"))
+
+			err = j.Rollback()
+			goMod, errGoMod := os.ReadFile(projDir + "/go.mod")
+			_, errModule := os.Stat(projDir + "/internal/graphql/module.go")
+			_, errModuleDir := os.Stat(projDir + "/internal")
+			_, errJournal := os.Stat(projDir + "/" + journal.Dir + "/" + j.ID)
+
+			t.Log("Given a journal with snapshots of the existing and not existing files")
+			t.Log("When rollback the journal")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The changed file should be restored")
+			require.NoError(t, errGoMod)
+			require.Equal(t, "module testproj\n", string(goMod))
+			t.Log("	The created file should be removed")
+			require.ErrorIs(t, errModule, os.ErrNotExist)
+			t.Log("	The directories left empty should be removed")
+			require.ErrorIs(t, errModuleDir, os.ErrNotExist)
+			t.Log("	The journal should be removed")
+			require.ErrorIs(t, errJournal, os.ErrNotExist)
+		},
+	)
+}
+
+func TestLast(t *testing.T) {
+	t.Run(
+		"return the last committed journal", func(t *testing.T) {
+			projDir := initProjDir(t)
+			defer os.RemoveAll(projDir)
+
+			first, err := journal.Begin(projDir, "first")
+			require.NoError(t, err)
+			require.NoError(t, first.Commit())
+			second, err := journal.Begin(projDir, "second")
+			require.NoError(t, err)
+			require.NoError(t, second.Commit())
+			_, err = journal.Begin(projDir, "not finished")
+			require.NoError(t, err)
+
+			last, err := journal.Last(projDir)
+
+			t.Log("Given several committed journals and a not finished one")
+			t.Log("When get the last journal")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The last committed journal should be returned")
+			require.Equal(t, "second", last.Command)
+		},
+	)
+
+	t.Run(
+		"return an error if there are no journals", func(t *testing.T) {
+			projDir := initProjDir(t)
+			defer os.RemoveAll(projDir)
+
+			_, err := journal.Last(projDir)
+
+			t.Log("Given a project without journals")
+			t.Log("When get the last journal")
+			t.Log("	The ErrNoJournal error should be returned")
+			require.ErrorIs(t, err, journal.ErrNoJournal)
+		},
+	)
+}
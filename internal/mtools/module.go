@@ -6,9 +6,13 @@ import (
 	"github.com/go-modulus/modulus/logger"
 	"github.com/go-modulus/modulus/module"
 	"github.com/go-modulus/mtools/internal/mtools/action"
+	"github.com/go-modulus/mtools/internal/mtools/cache"
 	cmdRoot "github.com/go-modulus/mtools/internal/mtools/cli"
 	cmdDb "github.com/go-modulus/mtools/internal/mtools/cli/db"
+	cmdEntrypoint "github.com/go-modulus/mtools/internal/mtools/cli/entrypoint"
+	cmdManifest "github.com/go-modulus/mtools/internal/mtools/cli/manifest"
 	cmdModule "github.com/go-modulus/mtools/internal/mtools/cli/module"
+	cmdRegistry "github.com/go-modulus/mtools/internal/mtools/cli/registry"
 )
 
 func NewModule() *module.Module {
@@ -16,14 +20,38 @@ func NewModule() *module.Module {
 		AddCliCommands(
 			cmdDb.NewDbCommand,
 			cmdRoot.NewInitProjectCommand,
+			cmdRoot.NewUndoCommand,
+			cmdRoot.NewDoctorCommand,
+			cmdRoot.NewCacheCommand,
 			cmdModule.NewModuleCommand,
+			cmdEntrypoint.NewEntrypointCommand,
+			cmdManifest.NewManifestCommand,
+			cmdRegistry.NewRegistryCommand,
 		).
 		AddProviders(
 			cmdRoot.NewInitProject,
+			cmdRoot.NewUndo,
+			cmdRoot.NewDoctor,
+			cmdRoot.NewCacheList,
+			cmdRoot.NewCacheClear,
+			cache.Default,
 			cmdModule.NewInstall,
+			cmdModule.NewUninstall,
+			cmdModule.NewUpgrade,
+			cmdModule.NewList,
+			cmdModule.NewInfo,
 			cmdModule.NewCreate,
 			cmdModule.NewAddCli,
 			cmdModule.NewAddJsonApi,
+			cmdEntrypoint.NewAdd,
+			cmdEntrypoint.NewSync,
+			cmdManifest.NewValidate,
+			cmdManifest.NewSchema,
+			cmdManifest.NewMigrate,
+			cmdRegistry.NewInit,
+			cmdRegistry.NewAdd,
+			cmdRegistry.NewValidate,
+			cmdRegistry.NewTest,
 			action.NewInstallStorage,
 			action.NewUpdateSqlcConfig,
 			cmdDb.NewUpdateSQLCConfig,
@@ -31,6 +59,9 @@ func NewModule() *module.Module {
 			cmdDb.NewMigrate,
 			cmdDb.NewRollback,
 			cmdDb.NewGenerate,
+			cmdDb.NewStatus,
+			cmdDb.NewLintMigrations,
+			cmdDb.NewLint,
 		).
 		AddDependencies(
 			logger.NewModule(),
@@ -0,0 +1,316 @@
+package semver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidVersion = fmt.Errorf("invalid semantic version")
+var ErrInvalidConstraint = fmt.Errorf("invalid version constraint")
+
+// Version is a semantic version in the Go modules format: vMAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	// Build is the build metadata, e.g. incompatible for the v2+ modules without go.mod. It does not affect the order.
+	Build string
+}
+
+// Parse parses the version with or without the "v" prefix
+func Parse(value string) (Version, error) {
+	v, parts, err := parseParts(value)
+	if err != nil {
+		return Version{}, err
+	}
+	if parts < 3 {
+		return Version{}, fmt.Errorf("%w: %s", ErrInvalidVersion, value)
+	}
+	return v, nil
+}
+
+// parseParts parses a version that can miss the minor and patch parts, e.g. 1 or 1.2.
+// Returns the number of the parts that are present in the value.
+func parseParts(value string) (Version, int, error) {
+	str := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	str, build, _ := strings.Cut(str, "+")
+	str, pre, _ := strings.Cut(str, "-")
+	nums := strings.Split(str, ".")
+	if len(nums) == 0 || len(nums) > 3 || nums[0] == "" {
+		return Version{}, 0, fmt.Errorf("%w: %s", ErrInvalidVersion, value)
+	}
+	v := Version{Prerelease: pre, Build: build}
+	fields := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, num := range nums {
+		n, err := strconv.Atoi(num)
+		if err != nil || n < 0 {
+			return Version{}, 0, fmt.Errorf("%w: %s", ErrInvalidVersion, value)
+		}
+		*fields[i] = n
+	}
+	return v, len(nums), nil
+}
+
+// String returns the version in the Go modules format
+func (v Version) String() string {
+	res := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		res += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		res += "+" + v.Build
+	}
+	return res
+}
+
+// Compare returns -1, 0 or 1 if the version is less, equal or greater than the other one
+func (v Version) Compare(other Version) int {
+	if res := compareInt(v.Major, other.Major); res != 0 {
+		return res
+	}
+	if res := compareInt(v.Minor, other.Minor); res != 0 {
+		return res
+	}
+	if res := compareInt(v.Patch, other.Patch); res != 0 {
+		return res
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		var res int
+		switch {
+		case aErr == nil && bErr == nil:
+			res = compareInt(aNum, bNum)
+		case aErr == nil:
+			res = -1
+		case bErr == nil:
+			res = 1
+		default:
+			res = strings.Compare(aParts[i], bParts[i])
+		}
+		if res != 0 {
+			return res
+		}
+	}
+	return compareInt(len(aParts), len(bParts))
+}
+
+type comparison struct {
+	op      string
+	version Version
+}
+
+func (c comparison) check(v Version) bool {
+	res := v.Compare(c.version)
+	switch c.op {
+	case "=":
+		return res == 0
+	case "!=":
+		return res != 0
+	case ">":
+		return res > 0
+	case ">=":
+		return res >= 0
+	case "<":
+		return res < 0
+	case "<=":
+		return res <= 0
+	}
+	return false
+}
+
+// Constraint is a set of alternatives separated by "||". Each alternative is a set of comparisons that all must match.
+// Supported forms: "1.2.3", "=1.2.3", "!=1.2.3", ">1.2", ">=1.2.3 <2", "^1.2.3", "~1.2", "1.2.x", "*", "latest".
+type Constraint struct {
+	original     string
+	alternatives [][]comparison
+}
+
+// ParseConstraint parses the constraint. The empty constraint, "*" and "latest" match any released version.
+func ParseConstraint(value string) (Constraint, error) {
+	c := Constraint{original: strings.TrimSpace(value)}
+	for _, alt := range strings.Split(c.original, "||") {
+		comparisons := make([]comparison, 0)
+		for _, item := range strings.FieldsFunc(
+			alt, func(r rune) bool {
+				return r == ' ' || r == ','
+			},
+		) {
+			parsed, err := parseComparison(item)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("%w: %s", ErrInvalidConstraint, value)
+			}
+			comparisons = append(comparisons, parsed...)
+		}
+		c.alternatives = append(c.alternatives, comparisons)
+	}
+	return c, nil
+}
+
+func parseComparison(item string) ([]comparison, error) {
+	if item == "*" || item == "x" || item == "latest" {
+		return nil, nil
+	}
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(item, prefix) {
+			op = prefix
+			item = strings.TrimPrefix(item, prefix)
+			break
+		}
+	}
+
+	// 1.2.x and 1.x are the same as 1.2 and 1
+	nums := strings.Split(item, ".")
+	for i, num := range nums {
+		if num == "x" || num == "X" || num == "*" {
+			nums = nums[:i]
+			break
+		}
+	}
+	if len(nums) == 0 {
+		return nil, nil
+	}
+	v, parts, err := parseParts(strings.Join(nums, "."))
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "^":
+		return []comparison{{">=", v}, {"<", caretUpper(v, parts)}}, nil
+	case "~":
+		return []comparison{{">=", v}, {"<", tildeUpper(v, parts)}}, nil
+	case "", "=":
+		if parts == 3 {
+			return []comparison{{"=", v}}, nil
+		}
+		return []comparison{{">=", v}, {"<", tildeUpper(v, parts)}}, nil
+	}
+	return []comparison{{op, v}}, nil
+}
+
+// caretUpper returns the upper bound allowing the changes that do not modify the left-most non-zero part
+func caretUpper(v Version, parts int) Version {
+	switch {
+	case v.Major > 0 || parts == 1:
+		return Version{Major: v.Major + 1}
+	case v.Minor > 0 || parts == 2:
+		return Version{Minor: v.Minor + 1}
+	}
+	return Version{Patch: v.Patch + 1}
+}
+
+// tildeUpper returns the upper bound allowing the patch level changes if the minor part is set
+func tildeUpper(v Version, parts int) Version {
+	if parts == 1 {
+		return Version{Major: v.Major + 1}
+	}
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// Check reports whether the version matches the constraint.
+// The prerelease versions match only if the constraint mentions a prerelease of the same major.minor.patch version.
+func (c Constraint) Check(v Version) bool {
+	for _, alt := range c.alternatives {
+		if c.checkAlternative(alt, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) checkAlternative(alt []comparison, v Version) bool {
+	prereleaseAllowed := v.Prerelease == ""
+	for _, cmp := range alt {
+		if !cmp.check(v) {
+			return false
+		}
+		if cmp.version.Prerelease != "" &&
+			cmp.version.Major == v.Major &&
+			cmp.version.Minor == v.Minor &&
+			cmp.version.Patch == v.Patch {
+			prereleaseAllowed = true
+		}
+	}
+	return prereleaseAllowed
+}
+
+// String returns the original constraint
+func (c Constraint) String() string {
+	return c.original
+}
+
+// Latest returns the greatest version from the list matching all constraints.
+// Without constraints the prerelease versions are skipped unless there are no releases, the same way go get chooses the latest version.
+func Latest(versions []string, constraints ...Constraint) (string, bool) {
+	sorted := sortDesc(versions)
+	if len(constraints) == 0 {
+		for _, v := range sorted {
+			if v.Prerelease == "" {
+				return v.String(), true
+			}
+		}
+		if len(sorted) == 0 {
+			return "", false
+		}
+		return sorted[0].String(), true
+	}
+	for _, v := range sorted {
+		matched := true
+		for _, c := range constraints {
+			if !c.Check(v) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return v.String(), true
+		}
+	}
+	return "", false
+}
+
+func sortDesc(versions []string) []Version {
+	parsed := make([]Version, 0, len(versions))
+	for _, value := range versions {
+		v, err := Parse(value)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, v)
+	}
+	sort.Slice(
+		parsed, func(i, j int) bool {
+			return parsed[i].Compare(parsed[j]) > 0
+		},
+	)
+	return parsed
+}
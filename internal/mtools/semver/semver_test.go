@@ -0,0 +1,120 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraint_Check(t *testing.T) {
+	cases := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"", "v1.2.3", true},
+		{"latest", "v0.0.1", true},
+		{"*", "v1.0.0-rc.1", false},
+		{"1.2.3", "v1.2.3", true},
+		{"v1.2.3", "v1.2.4", false},
+		{"1.2", "v1.2.9", true},
+		{"1.2.x", "v1.3.0", false},
+		{"^1.2.3", "v1.9.0", true},
+		{"^1.2.3", "v2.0.0", false},
+		{"^0.5.0", "v0.5.9", true},
+		{"^0.5.0", "v0.6.0", false},
+		{"^0.0.3", "v0.0.4", false},
+		{"~1.2.3", "v1.2.9", true},
+		{"~1.2.3", "v1.3.0", false},
+		{">=1.0.0 <2", "v1.5.0", true},
+		{">=1.0.0, <2", "v2.0.0", false},
+		{"<1.0.0 || >=2.0.0", "v2.1.0", true},
+		{"!=1.2.3", "v1.2.3", false},
+		{"^0.5.0-rc.1", "v0.5.0-rc.2", true},
+		{"^0.5.0-rc.1", "v0.5.1-rc.1", false},
+		{">=0.5.0-rc.1", "v0.5.0", true},
+	}
+	for _, c := range cases {
+		t.Run(
+			c.constraint+" "+c.version, func(t *testing.T) {
+				constraint, err := semver.ParseConstraint(c.constraint)
+				require.NoError(t, err)
+				v, err := semver.Parse(c.version)
+				require.NoError(t, err)
+
+				t.Log("When check the version against the constraint")
+				t.Log("	The result should match the expected one")
+				require.Equal(t, c.expected, constraint.Check(v))
+			},
+		)
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	t.Run(
+		"return an error for the invalid constraint", func(t *testing.T) {
+			_, err := semver.ParseConstraint("^one.two")
+
+			t.Log("When parse the invalid constraint")
+			t.Log("	The ErrInvalidConstraint error should be returned")
+			require.ErrorIs(t, err, semver.ErrInvalidConstraint)
+		},
+	)
+}
+
+func TestLatest(t *testing.T) {
+	t.Run(
+		"return the greatest matching version", func(t *testing.T) {
+			first, err := semver.ParseConstraint("^0.4.0 || ^0.5.0")
+			require.NoError(t, err)
+			second, err := semver.ParseConstraint("<0.5.3")
+			require.NoError(t, err)
+
+			v, ok := semver.Latest(
+				[]string{"v0.4.0", "v0.5.2", "v0.5.10", "v0.5.1", "v0.6.0", "v0.5.3-rc.1", "wrong"},
+				first,
+				second,
+			)
+
+			t.Log("Given a list of versions and several constraints")
+			t.Log("When get the latest version")
+			t.Log("	The greatest version matching all constraints should be returned")
+			require.True(t, ok)
+			require.Equal(t, "v0.5.2", v)
+		},
+	)
+
+	t.Run(
+		"skip the prerelease versions without constraints", func(t *testing.T) {
+			v, ok := semver.Latest([]string{"v1.1.0-rc.1", "v1.0.0", "v0.9.0"})
+			onlyPre, okPre := semver.Latest([]string{"v1.0.0-beta.1", "v1.0.0-rc.1"})
+			incompatible, okIncompatible := semver.Latest([]string{"v2.0.0+incompatible", "v2.1.0+incompatible"})
+
+			t.Log("When get the latest version without constraints")
+			t.Log("	The greatest release should be returned")
+			require.True(t, ok)
+			require.Equal(t, "v1.0.0", v)
+			t.Log("	The greatest prerelease should be returned if there are no releases")
+			require.True(t, okPre)
+			require.Equal(t, "v1.0.0-rc.1", onlyPre)
+			t.Log("	The build metadata should be kept")
+			require.True(t, okIncompatible)
+			require.Equal(t, "v2.1.0+incompatible", incompatible)
+		},
+	)
+
+	t.Run(
+		"return false if nothing matches", func(t *testing.T) {
+			c, err := semver.ParseConstraint("^1.0.0")
+			require.NoError(t, err)
+
+			_, ok := semver.Latest([]string{"v0.4.0", "v0.5.2"}, c)
+
+			t.Log("Given a list of versions not matching the constraint")
+			t.Log("When get the latest version")
+			t.Log("	The false should be returned")
+			require.False(t, ok)
+		},
+	)
+}
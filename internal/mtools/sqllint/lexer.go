@@ -0,0 +1,229 @@
+package sqllint
+
+import (
+	"regexp"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	// text is the token as it is written, the quoted identifiers are without the quotes
+	text string
+	line int
+}
+
+// is checks that the token is the keyword ignoring the case
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+type sectionKind int
+
+const (
+	sectionNone sectionKind = iota
+	sectionUp
+	sectionDown
+)
+
+// statement is one SQL statement of a migration section
+type statement struct {
+	section sectionKind
+	tokens  []token
+	// comments are the texts of the comments inside the statement and before it after the previous statement
+	comments []string
+	line     int
+}
+
+// section is the -- migrate:up or -- migrate:down part of the migration file
+type section struct {
+	kind sectionKind
+	line int
+	// options are the dbmate options written after the section marker, e.g. transaction:false
+	options string
+}
+
+type parsedFile struct {
+	statements []statement
+	sections   []section
+	// fileComments are the texts of all comments of the file
+	fileComments []string
+}
+
+var sectionRegexp = regexp.MustCompile(`^--\s*migrate:(up|down)\b(.*)$`)
+var dollarTagRegexp = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// parse splits the migration file into the statements of the dbmate sections.
+// The statements before the first section marker are skipped like dbmate does.
+func parse(content string) parsedFile {
+	res := parsedFile{}
+	current := statement{}
+	currentSection := sectionNone
+	line := 1
+
+	flush := func() {
+		if len(current.tokens) != 0 && currentSection != sectionNone {
+			current.section = currentSection
+			res.statements = append(res.statements, current)
+		}
+		current = statement{}
+	}
+	addToken := func(t token) {
+		if len(current.tokens) == 0 {
+			current.line = t.line
+		}
+		current.tokens = append(current.tokens, t)
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(content[i:], "--"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end == -1 {
+				end = len(content) - i
+			}
+			comment := content[i : i+end]
+			atLineStart := i == 0 || content[i-1] == '\n'
+			if matches := sectionRegexp.FindStringSubmatch(strings.TrimRight(comment, "\r")); atLineStart && matches != nil {
+				flush()
+				currentSection = sectionUp
+				if matches[1] == "down" {
+					currentSection = sectionDown
+				}
+				res.sections = append(
+					res.sections,
+					section{kind: currentSection, line: line, options: strings.TrimSpace(matches[2])},
+				)
+			} else {
+				text := strings.TrimSpace(strings.TrimPrefix(comment, "--"))
+				current.comments = append(current.comments, text)
+				res.fileComments = append(res.fileComments, text)
+			}
+			i += end
+		case strings.HasPrefix(content[i:], "/*"):
+			// the block comments of PostgreSQL can be nested
+			depth := 0
+			j := i
+			for j < len(content) {
+				if strings.HasPrefix(content[j:], "/*") {
+					depth++
+					j += 2
+					continue
+				}
+				if strings.HasPrefix(content[j:], "*/") {
+					depth--
+					j += 2
+					if depth == 0 {
+						break
+					}
+					continue
+				}
+				if content[j] == '\n' {
+					line++
+				}
+				j++
+			}
+			text := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(content[i:j], "/*"), "*/"))
+			current.comments = append(current.comments, text)
+			res.fileComments = append(res.fileComments, text)
+			i = j
+		case c == '\'':
+			start := line
+			j := i + 1
+			// E'...' strings allow the backslash escapes
+			escapes := i > 0 && (content[i-1] == 'E' || content[i-1] == 'e')
+			for j < len(content) {
+				if escapes && content[j] == '\\' {
+					j += 2
+					continue
+				}
+				if content[j] == '\'' {
+					if j+1 < len(content) && content[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				if content[j] == '\n' {
+					line++
+				}
+				j++
+			}
+			end := min(j+1, len(content))
+			addToken(token{kind: tokenString, text: content[i:end], line: start})
+			i = end
+		case c == '"':
+			start := line
+			j := i + 1
+			for j < len(content) {
+				if content[j] == '"' {
+					if j+1 < len(content) && content[j+1] == '"' {
+						j += 2
+						continue
+					}
+					break
+				}
+				if content[j] == '\n' {
+					line++
+				}
+				j++
+			}
+			text := strings.ReplaceAll(content[min(i+1, len(content)):min(j, len(content))], `""`, `"`)
+			addToken(token{kind: tokenQuotedIdent, text: text, line: start})
+			i = min(j+1, len(content))
+		case c == '$' && dollarTagRegexp.MatchString(content[i:]) && (i == 0 || !isWordByte(content[i-1])):
+			start := line
+			tag := dollarTagRegexp.FindString(content[i:])
+			end := strings.Index(content[i+len(tag):], tag)
+			if end == -1 {
+				end = len(content)
+			} else {
+				end = i + len(tag) + end + len(tag)
+			}
+			line += strings.Count(content[i:end], "\n")
+			addToken(token{kind: tokenString, text: content[i:end], line: start})
+			i = end
+		case c == ';':
+			flush()
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(content) && (isWordByte(content[j]) || content[j] == '.') {
+				j++
+			}
+			addToken(token{kind: tokenNumber, text: content[i:j], line: line})
+			i = j
+		case isWordByte(c):
+			j := i
+			for j < len(content) && (isWordByte(content[j]) || content[j] == '$') {
+				j++
+			}
+			addToken(token{kind: tokenWord, text: content[i:j], line: line})
+			i = j
+		default:
+			addToken(token{kind: tokenPunct, text: string(c), line: line})
+			i++
+		}
+	}
+	flush()
+	return res
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
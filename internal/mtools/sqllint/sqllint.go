@@ -0,0 +1,561 @@
+package sqllint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Rule string
+
+const (
+	RuleMissingDown                Rule = "missing-down"
+	RuleAddColumnNotNull           Rule = "add-column-not-null"
+	RuleCreateIndexNotConcurrently Rule = "create-index-not-concurrently"
+	// RuleConcurrentlyInTransaction is CREATE INDEX CONCURRENTLY in a section run in a transaction, PostgreSQL rejects it
+	RuleConcurrentlyInTransaction Rule = "concurrently-in-transaction"
+	RuleDropColumn                Rule = "drop-column"
+	RuleDropTable                 Rule = "drop-table"
+	RuleAlterColumnType           Rule = "alter-column-type"
+	RuleSchemaMismatch            Rule = "schema-mismatch"
+)
+
+// Rules are all rules of the linter with their descriptions
+var Rules = map[Rule]string{
+	RuleMissingDown:                "the migration has no statements in the -- migrate:down section",
+	RuleAddColumnNotNull:           "ADD COLUMN ... NOT NULL without a default on an existing table",
+	RuleCreateIndexNotConcurrently: "CREATE INDEX without CONCURRENTLY on an existing table",
+	RuleConcurrentlyInTransaction:  "CREATE INDEX CONCURRENTLY in a section without the transaction:false option",
+	RuleDropColumn:                 "DROP COLUMN in the -- migrate:up section",
+	RuleDropTable:                  "DROP TABLE in the -- migrate:up section",
+	RuleAlterColumnType:            "ALTER COLUMN ... TYPE rewriting an existing table",
+	RuleSchemaMismatch:             "a statement changes an object outside the schema of the module",
+}
+
+// IgnoreDirective is the comment disabling the rules for the next statement, e.g. -- mtools-lint-ignore drop-column.
+// All rules are disabled if the directive lists no rules.
+const IgnoreDirective = "mtools-lint-ignore"
+
+// IgnoreFileDirective is the comment disabling the rules for the whole file, e.g. -- mtools-lint-ignore-file missing-down
+const IgnoreFileDirective = "mtools-lint-ignore-file"
+
+type Issue struct {
+	Rule    Rule
+	Line    int
+	Message string
+}
+
+// Lint checks the dbmate migration for the operations dangerous for a PostgreSQL database with data.
+// The schema is the schema of the module, the objects of other schemas are reported.
+// The schema check is skipped if the schema is empty.
+func Lint(content string, schema string) []Issue {
+	file := parse(content)
+	l := &linter{
+		schema:  schema,
+		created: make(map[sectionKind]map[string]struct{}),
+		options: make(map[sectionKind]string),
+	}
+	for _, s := range file.sections {
+		l.options[s.kind] = s.options
+	}
+
+	issues := l.checkDown(file)
+	for _, stmt := range file.statements {
+		ignored := ignoredRules(stmt.comments, IgnoreDirective)
+		for _, issue := range l.lintStatement(stmt) {
+			if !ignored.has(issue.Rule) {
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	fileIgnored := ignoredRules(file.fileComments, IgnoreFileDirective)
+	res := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if !fileIgnored.has(issue.Rule) {
+			res = append(res, issue)
+		}
+	}
+	sort.SliceStable(
+		res, func(i, j int) bool {
+			return res[i].Line < res[j].Line
+		},
+	)
+	return res
+}
+
+type ignoreSet struct {
+	all   bool
+	rules map[Rule]struct{}
+}
+
+func (s ignoreSet) has(rule Rule) bool {
+	if s.all {
+		return true
+	}
+	_, ok := s.rules[rule]
+	return ok
+}
+
+// ignoredRules returns the rules listed in the comments with the directive
+func ignoredRules(comments []string, directive string) ignoreSet {
+	res := ignoreSet{rules: make(map[Rule]struct{})}
+	for _, comment := range comments {
+		fields := strings.FieldsFunc(
+			comment, func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			},
+		)
+		if len(fields) == 0 || fields[0] != directive {
+			continue
+		}
+		if len(fields) == 1 {
+			res.all = true
+		}
+		for _, rule := range fields[1:] {
+			res.rules[Rule(rule)] = struct{}{}
+		}
+	}
+	return res
+}
+
+type linter struct {
+	schema string
+	// created are the tables created by the migration in each section, the changes of them are safe
+	created map[sectionKind]map[string]struct{}
+	// options are the dbmate options of the sections
+	options map[sectionKind]string
+}
+
+func (l *linter) checkDown(file parsedFile) []Issue {
+	upLine := 1
+	for _, s := range file.sections {
+		if s.kind == sectionUp {
+			upLine = s.line
+		}
+	}
+	for _, stmt := range file.statements {
+		if stmt.section == sectionDown {
+			return nil
+		}
+	}
+	for _, s := range file.sections {
+		if s.kind == sectionDown {
+			return []Issue{
+				{
+					Rule:    RuleMissingDown,
+					Line:    s.line,
+					Message: "the -- migrate:down section is empty, the migration cannot be rolled back",
+				},
+			}
+		}
+	}
+	return []Issue{
+		{
+			Rule:    RuleMissingDown,
+			Line:    upLine,
+			Message: "the migration has no -- migrate:down section, it cannot be rolled back",
+		},
+	}
+}
+
+func (l *linter) lintStatement(stmt statement) []Issue {
+	c := &cursor{tokens: stmt.tokens}
+	switch {
+	case c.accept("CREATE"):
+		return l.lintCreate(stmt, c)
+	case c.accept("ALTER", "TABLE"):
+		return l.lintAlterTable(stmt, c)
+	case c.accept("DROP", "TABLE"):
+		return l.lintDropTable(stmt, c)
+	case c.accept("INSERT", "INTO"):
+		return l.checkSchema(c, "table")
+	case c.accept("UPDATE"), c.accept("DELETE", "FROM"):
+		c.accept("ONLY")
+		return l.checkSchema(c, "table")
+	}
+	return nil
+}
+
+func (l *linter) lintCreate(stmt statement, c *cursor) []Issue {
+	c.accept("OR", "REPLACE")
+	c.accept("UNIQUE")
+	if c.accept("INDEX") {
+		concurrently := c.accept("CONCURRENTLY")
+		c.accept("IF", "NOT", "EXISTS")
+		if !c.peekIs("ON") {
+			c.name()
+		}
+		if !c.accept("ON") {
+			return nil
+		}
+		c.accept("ONLY")
+		table, ok := c.peekName()
+		issues := l.checkSchema(c, "table")
+		if !ok {
+			return issues
+		}
+		if concurrently && !strings.Contains(l.options[stmt.section], "transaction:false") {
+			issues = append(
+				issues, Issue{
+					Rule: RuleConcurrentlyInTransaction,
+					Line: stmt.line,
+					Message: fmt.Sprintf(
+						"CREATE INDEX CONCURRENTLY on the table %s cannot run in a transaction, mark the section with -- migrate:%s transaction:false",
+						table.String(),
+						sectionName(stmt.section),
+					),
+				},
+			)
+		}
+		if !concurrently && !l.isCreated(stmt.section, table) {
+			issues = append(
+				issues, Issue{
+					Rule: RuleCreateIndexNotConcurrently,
+					Line: stmt.line,
+					Message: fmt.Sprintf(
+						"CREATE INDEX on the existing table %s blocks the writes to it until the index is built, use CREATE INDEX CONCURRENTLY",
+						table.String(),
+					),
+				},
+			)
+		}
+		return issues
+	}
+
+	temporary := false
+	c.accept("GLOBAL")
+	c.accept("LOCAL")
+	if c.accept("TEMP") || c.accept("TEMPORARY") {
+		temporary = true
+	}
+	c.accept("UNLOGGED")
+	if c.accept("TABLE") {
+		c.accept("IF", "NOT", "EXISTS")
+		table, ok := c.peekName()
+		if !ok || temporary {
+			return nil
+		}
+		l.markCreated(stmt.section, table)
+		return l.checkSchema(c, "table")
+	}
+	if temporary {
+		return nil
+	}
+	kinds := [][]string{
+		{"MATERIALIZED", "VIEW"},
+		{"VIEW"},
+		{"SEQUENCE"},
+		{"TYPE"},
+		{"DOMAIN"},
+		{"FUNCTION"},
+		{"PROCEDURE"},
+	}
+	for _, kind := range kinds {
+		if c.accept(kind...) {
+			c.accept("IF", "NOT", "EXISTS")
+			return l.checkSchema(c, strings.ToLower(strings.Join(kind, " ")))
+		}
+	}
+	return nil
+}
+
+func (l *linter) lintAlterTable(stmt statement, c *cursor) []Issue {
+	c.accept("IF", "EXISTS")
+	c.accept("ONLY")
+	table, ok := c.peekName()
+	if !ok {
+		return nil
+	}
+	issues := l.checkSchema(c, "table")
+	created := l.isCreated(stmt.section, table)
+	c.accept("*")
+
+	for _, action := range c.splitRest() {
+		a := &cursor{tokens: action}
+		switch {
+		case a.accept("ADD"):
+			if a.peekIs("CONSTRAINT") || a.peekIs("PRIMARY") || a.peekIs("UNIQUE") || a.peekIs("CHECK") ||
+				a.peekIs("FOREIGN") || a.peekIs("EXCLUDE") {
+				continue
+			}
+			a.accept("COLUMN")
+			a.accept("IF", "NOT", "EXISTS")
+			column, ok := a.name()
+			if !ok || created {
+				continue
+			}
+			if a.containsSeq("NOT", "NULL") && !a.containsSeq("DEFAULT") && !a.containsSeq("GENERATED") {
+				issues = append(
+					issues, Issue{
+						Rule: RuleAddColumnNotNull,
+						Line: action[0].line,
+						Message: fmt.Sprintf(
+							"adding the NOT NULL column %s without a default fails if the table %s has rows, add a DEFAULT or set NOT NULL after filling the column",
+							column.String(),
+							table.String(),
+						),
+					},
+				)
+			}
+		case a.accept("DROP"):
+			if a.peekIs("CONSTRAINT") || stmt.section != sectionUp || created {
+				continue
+			}
+			a.accept("COLUMN")
+			a.accept("IF", "EXISTS")
+			column, ok := a.name()
+			if !ok {
+				continue
+			}
+			issues = append(
+				issues, Issue{
+					Rule: RuleDropColumn,
+					Line: action[0].line,
+					Message: fmt.Sprintf(
+						"dropping the column %s of the table %s loses its data and breaks the running code still reading it",
+						column.String(),
+						table.String(),
+					),
+				},
+			)
+		case a.accept("ALTER"):
+			a.accept("COLUMN")
+			column, ok := a.name()
+			if !ok || created {
+				continue
+			}
+			if a.accept("SET", "DATA", "TYPE") || a.accept("TYPE") {
+				issues = append(
+					issues, Issue{
+						Rule: RuleAlterColumnType,
+						Line: action[0].line,
+						Message: fmt.Sprintf(
+							"changing the type of the column %s may rewrite the table %s holding an exclusive lock on it",
+							column.String(),
+							table.String(),
+						),
+					},
+				)
+			}
+		}
+	}
+	return issues
+}
+
+func (l *linter) lintDropTable(stmt statement, c *cursor) []Issue {
+	c.accept("IF", "EXISTS")
+	issues := make([]Issue, 0)
+	for {
+		table, ok := c.peekName()
+		if !ok {
+			return issues
+		}
+		issues = append(issues, l.checkSchema(c, "table")...)
+		if stmt.section == sectionUp && !l.isCreated(stmt.section, table) {
+			issues = append(
+				issues, Issue{
+					Rule:    RuleDropTable,
+					Line:    stmt.line,
+					Message: fmt.Sprintf("dropping the table %s loses its data", table.String()),
+				},
+			)
+		}
+		if !c.accept(",") {
+			return issues
+		}
+	}
+}
+
+// checkSchema reads the name at the cursor and reports it if it is outside the schema of the module.
+// The unqualified names are reported if the schema of the module is not public, they are created in the search_path.
+func (l *linter) checkSchema(c *cursor, kind string) []Issue {
+	line := c.peek(0).line
+	n, ok := c.name()
+	if !ok || l.schema == "" {
+		return nil
+	}
+	if n.schema == "" {
+		if strings.EqualFold(l.schema, "public") {
+			return nil
+		}
+		return []Issue{
+			{
+				Rule: RuleSchemaMismatch,
+				Line: line,
+				Message: fmt.Sprintf(
+					"the %s %s is not qualified with the schema %s of the module",
+					kind,
+					n.String(),
+					l.schema,
+				),
+			},
+		}
+	}
+	if n.schemaName() == l.schema || (!n.quotedSchema && n.schemaName() == strings.ToLower(l.schema)) {
+		return nil
+	}
+	return []Issue{
+		{
+			Rule:    RuleSchemaMismatch,
+			Line:    line,
+			Message: fmt.Sprintf("the %s %s is outside the schema %s of the module", kind, n.String(), l.schema),
+		},
+	}
+}
+
+func (l *linter) markCreated(section sectionKind, n qualifiedName) {
+	if l.created[section] == nil {
+		l.created[section] = make(map[string]struct{})
+	}
+	l.created[section][n.key()] = struct{}{}
+}
+
+func (l *linter) isCreated(section sectionKind, n qualifiedName) bool {
+	_, ok := l.created[section][n.key()]
+	return ok
+}
+
+func sectionName(section sectionKind) string {
+	if section == sectionDown {
+		return "down"
+	}
+	return "up"
+}
+
+type qualifiedName struct {
+	schema       string
+	quotedSchema bool
+	name         string
+	quotedName   bool
+}
+
+// schemaName returns the schema name the way PostgreSQL sees it: the unquoted names are lower-cased
+func (n qualifiedName) schemaName() string {
+	if n.quotedSchema {
+		return n.schema
+	}
+	return strings.ToLower(n.schema)
+}
+
+// key identifies the table in the migration, the schema is skipped to match the qualified and unqualified names
+func (n qualifiedName) key() string {
+	if n.quotedName {
+		return n.name
+	}
+	return strings.ToLower(n.name)
+}
+
+func (n qualifiedName) String() string {
+	if n.schema == "" {
+		return n.name
+	}
+	return n.schema + "." + n.name
+}
+
+type cursor struct {
+	tokens []token
+	pos    int
+}
+
+func (c *cursor) peek(offset int) token {
+	if c.pos+offset >= len(c.tokens) {
+		return token{kind: tokenPunct}
+	}
+	return c.tokens[c.pos+offset]
+}
+
+func (c *cursor) peekIs(keyword string) bool {
+	return c.peek(0).is(keyword)
+}
+
+// accept moves the cursor after the keywords or punctuation if the next tokens are them
+func (c *cursor) accept(words ...string) bool {
+	for i, word := range words {
+		t := c.peek(i)
+		if !t.is(word) && !(t.kind == tokenPunct && t.text == word) {
+			return false
+		}
+	}
+	c.pos += len(words)
+	return true
+}
+
+// name reads the optionally schema-qualified name
+func (c *cursor) name() (qualifiedName, bool) {
+	n, ok := c.peekName()
+	if !ok {
+		return n, false
+	}
+	if n.schema != "" {
+		c.pos += 3
+	} else {
+		c.pos++
+	}
+	return n, true
+}
+
+func (c *cursor) peekName() (qualifiedName, bool) {
+	first := c.peek(0)
+	if first.kind != tokenWord && first.kind != tokenQuotedIdent {
+		return qualifiedName{}, false
+	}
+	second := c.peek(2)
+	if c.peek(1).kind == tokenPunct && c.peek(1).text == "." &&
+		(second.kind == tokenWord || second.kind == tokenQuotedIdent) {
+		return qualifiedName{
+			schema:       first.text,
+			quotedSchema: first.kind == tokenQuotedIdent,
+			name:         second.text,
+			quotedName:   second.kind == tokenQuotedIdent,
+		}, true
+	}
+	return qualifiedName{name: first.text, quotedName: first.kind == tokenQuotedIdent}, true
+}
+
+// containsSeq checks that the rest of the tokens outside the parentheses contain the keywords in a row
+func (c *cursor) containsSeq(words ...string) bool {
+	depth := 0
+	for i := c.pos; i < len(c.tokens); i++ {
+		t := c.tokens[i]
+		if t.kind == tokenPunct && t.text == "(" {
+			depth++
+		}
+		if t.kind == tokenPunct && t.text == ")" {
+			depth--
+		}
+		if depth != 0 {
+			continue
+		}
+		rest := &cursor{tokens: c.tokens, pos: i}
+		if rest.accept(words...) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitRest splits the rest of the tokens by the commas outside the parentheses
+func (c *cursor) splitRest() [][]token {
+	res := make([][]token, 0)
+	depth := 0
+	start := c.pos
+	for i := c.pos; i < len(c.tokens); i++ {
+		t := c.tokens[i]
+		switch {
+		case t.kind == tokenPunct && t.text == "(":
+			depth++
+		case t.kind == tokenPunct && t.text == ")":
+			depth--
+		case t.kind == tokenPunct && t.text == "," && depth == 0:
+			if i > start {
+				res = append(res, c.tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(c.tokens) {
+		res = append(res, c.tokens[start:])
+	}
+	return res
+}
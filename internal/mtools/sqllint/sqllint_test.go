@@ -0,0 +1,193 @@
+package sqllint_test
+
+import (
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/sqllint"
+	"github.com/stretchr/testify/require"
+)
+
+func rules(issues []sqllint.Issue) []sqllint.Rule {
+	res := make([]sqllint.Rule, 0, len(issues))
+	for _, issue := range issues {
+		res = append(res, issue.Rule)
+	}
+	return res
+}
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name      string
+		migration string
+		schema    string
+		expected  []sqllint.Rule
+	}{
+		{
+			name: "safe migration",
+			migration: `-- migrate:up
+CREATE TABLE blog.post (
+    id uuid PRIMARY KEY,
+    title text NOT NULL
+);
+CREATE INDEX post_title_idx ON blog.post (title);
+ALTER TABLE blog.post ADD COLUMN author_id uuid NOT NULL;
+
+-- migrate:down
+DROP TABLE blog.post;
+`,
+			schema:   "blog",
+			expected: []sqllint.Rule{},
+		},
+		{
+			name: "missing down section",
+			migration: `-- migrate:up
+CREATE TABLE post (id uuid);
+`,
+			schema:   "public",
+			expected: []sqllint.Rule{sqllint.RuleMissingDown},
+		},
+		{
+			name: "empty down section",
+			migration: `-- migrate:up
+CREATE TABLE post (id uuid);
+-- migrate:down
+-- nothing to do
+`,
+			schema:   "public",
+			expected: []sqllint.Rule{sqllint.RuleMissingDown},
+		},
+		{
+			name: "not null column without a default on an existing table",
+			migration: `-- migrate:up
+ALTER TABLE post
+    ADD COLUMN rating int NOT NULL,
+    ADD COLUMN views int NOT NULL DEFAULT 0,
+    ADD CONSTRAINT post_rating_check CHECK (rating IS NOT NULL);
+-- migrate:down
+ALTER TABLE post DROP COLUMN rating, DROP COLUMN views;
+`,
+			expected: []sqllint.Rule{sqllint.RuleAddColumnNotNull},
+		},
+		{
+			name: "index on an existing table",
+			migration: `-- migrate:up
+CREATE UNIQUE INDEX IF NOT EXISTS post_slug_idx ON post (slug);
+-- migrate:down
+DROP INDEX post_slug_idx;
+`,
+			expected: []sqllint.Rule{sqllint.RuleCreateIndexNotConcurrently},
+		},
+		{
+			name: "concurrent index in a transaction",
+			migration: `-- migrate:up
+CREATE INDEX CONCURRENTLY post_slug_idx ON post (slug);
+-- migrate:down
+DROP INDEX post_slug_idx;
+`,
+			expected: []sqllint.Rule{sqllint.RuleConcurrentlyInTransaction},
+		},
+		{
+			name: "concurrent index without a transaction",
+			migration: `-- migrate:up transaction:false
+CREATE INDEX CONCURRENTLY post_slug_idx ON post (slug);
+-- migrate:down
+DROP INDEX post_slug_idx;
+`,
+			expected: []sqllint.Rule{},
+		},
+		{
+			name: "drops and type changes in the up section",
+			migration: `-- migrate:up
+ALTER TABLE post DROP COLUMN rating, ALTER COLUMN title TYPE varchar(100), DROP CONSTRAINT post_pkey;
+DROP TABLE IF EXISTS comment, "Like";
+-- migrate:down
+ALTER TABLE post ALTER COLUMN title SET DATA TYPE text;
+`,
+			expected: []sqllint.Rule{
+				sqllint.RuleDropColumn,
+				sqllint.RuleAlterColumnType,
+				sqllint.RuleDropTable,
+				sqllint.RuleDropTable,
+				sqllint.RuleAlterColumnType,
+			},
+		},
+		{
+			name: "objects outside the schema of the module",
+			migration: `-- migrate:up
+CREATE TABLE blog.post (id uuid);
+CREATE TABLE public.comment (id uuid);
+CREATE TABLE "Blog".tag (id uuid);
+CREATE OR REPLACE VIEW post_view AS SELECT * FROM blog.post;
+INSERT INTO auth.user (id) VALUES ('00000000-0000-0000-0000-000000000000');
+-- migrate:down
+DROP VIEW post_view;
+DROP TABLE blog.post;
+`,
+			schema: "blog",
+			expected: []sqllint.Rule{
+				sqllint.RuleSchemaMismatch,
+				sqllint.RuleSchemaMismatch,
+				sqllint.RuleSchemaMismatch,
+				sqllint.RuleSchemaMismatch,
+			},
+		},
+		{
+			name: "ignore comments",
+			migration: `-- mtools-lint-ignore-file missing-down
+-- migrate:up
+-- mtools-lint-ignore drop-column, drop-table
+ALTER TABLE post DROP COLUMN rating;
+/* mtools-lint-ignore */
+DROP TABLE comment;
+DROP TABLE "like";
+`,
+			expected: []sqllint.Rule{sqllint.RuleDropTable},
+		},
+		{
+			name: "keywords inside the strings and comments",
+			migration: `-- migrate:up
+CREATE FUNCTION notify() RETURNS trigger AS $$
+BEGIN
+    DROP TABLE post;
+END;
+$$ LANGUAGE plpgsql;
+COMMENT ON TABLE post IS 'DROP TABLE post; ALTER TABLE post DROP COLUMN id';
+-- DROP TABLE post;
+-- migrate:down
+DROP FUNCTION notify;
+`,
+			expected: []sqllint.Rule{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(
+			c.name, func(t *testing.T) {
+				issues := sqllint.Lint(c.migration, c.schema)
+
+				t.Log("When lint the migration")
+				t.Log("	The issues should have the expected rules")
+				require.Equal(t, c.expected, rules(issues))
+			},
+		)
+	}
+
+	t.Run(
+		"report the lines of the statements", func(t *testing.T) {
+			issues := sqllint.Lint(
+				`-- migrate:up
+CREATE TABLE post (id uuid);
+
+DROP TABLE comment;
+-- migrate:down
+CREATE TABLE comment (id uuid);
+`, "",
+			)
+
+			t.Log("When lint the migration with a dangerous statement")
+			t.Log("	The issue should point to the line of the statement")
+			require.Len(t, issues, 1)
+			require.Equal(t, 4, issues[0].Line)
+			require.Contains(t, issues[0].Message, "comment")
+		},
+	)
+}
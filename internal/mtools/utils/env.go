@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
+)
+
+// RemoveEnvVariablesFromFile removes the variables from the env file.
+// The comment of a variable placed right above it is removed as well if it is not changed, the other comments are kept.
+func RemoveEnvVariablesFromFile(vars []module.ConfigEnvVariable, filename string) error {
+	if !FileExists(filename) {
+		return nil
+	}
+	content, err := fsys.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	varsMap := make(map[string]module.ConfigEnvVariable, len(vars))
+	for _, envVar := range vars {
+		varsMap[envVar.Key] = envVar
+	}
+
+	lines := strings.Split(string(content), "\n")
+	res := make([]string, 0, len(lines))
+	for _, line := range lines {
+		key, _, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if envVar, remove := varsMap[strings.TrimSpace(key)]; ok && remove {
+			res = removeEnvComment(res, envVar.Comment)
+			continue
+		}
+		res = append(res, line)
+	}
+
+	return fsys.WriteFile(filename, []byte(strings.Join(res, "\n")), 0644)
+}
+
+// removeEnvComment removes the lines of the comment from the end of the lines if all of them are there
+func removeEnvComment(lines []string, comment string) []string {
+	if strings.TrimSpace(comment) == "" {
+		return lines
+	}
+	commentLines := strings.Split(strings.TrimSpace(comment), "\n")
+	if len(commentLines) > len(lines) {
+		return lines
+	}
+	start := len(lines) - len(commentLines)
+	for i, commentLine := range commentLines {
+		line := strings.TrimSpace(lines[start+i])
+		if !strings.HasPrefix(line, "#") ||
+			strings.TrimSpace(strings.TrimPrefix(line, "#")) != strings.TrimSpace(commentLine) {
+			return lines
+		}
+	}
+	return lines[:start]
+}
+
+// EnvVariableKeys returns the keys of the variables defined in the env file.
+// Returns an empty set if the file does not exist.
+func EnvVariableKeys(filename string) (map[string]struct{}, error) {
+	res := make(map[string]struct{})
+	if !FileExists(filename) {
+		return res, nil
+	}
+	content, err := fsys.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		res[strings.TrimSpace(strings.TrimPrefix(key, "export "))] = struct{}{}
+	}
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package utils_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-modulus/modulus/module"
+	"github.com/go-modulus/mtools/internal/mtools/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveEnvVariablesFromFile(t *testing.T) {
+	t.Run(
+		"remove the variables with their own comments only", func(t *testing.T) {
+			filename := t.TempDir() + "/.env"
+			content := `# Project settings
+APP_ENV=local
+# Test comment
+DB_NAME=test
+# The host of the database, see the docs
+PG_HOST=myhost
+`
+			require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+
+			err := utils.RemoveEnvVariablesFromFile(
+				[]module.ConfigEnvVariable{
+					{Key: "DB_NAME", Value: "test", Comment: "Test comment"},
+					{Key: "PG_HOST", Value: "localhost", Comment: "The host of the database"},
+				},
+				filename,
+			)
+			res, errRead := os.ReadFile(filename)
+
+			t.Log("When remove the variables from the env file")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			require.NoError(t, errRead)
+			t.Log("	The variables should be removed with the comments written for them")
+			t.Log("	The comments changed by the user and the other comments should be kept")
+			require.Equal(
+				t, `# Project settings
+APP_ENV=local
+# The host of the database, see the docs
+`, string(res),
+			)
+		},
+	)
+}
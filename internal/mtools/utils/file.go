@@ -3,33 +3,33 @@ package utils
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"html/template"
-	"os"
+	"io/fs"
+	"regexp"
 
+	"github.com/go-modulus/mtools/internal/mtools/fsys"
 	"github.com/go-modulus/mtools/internal/mtools/templates"
 )
 
+var goModuleRegexp = regexp.MustCompile(`(?m)^module\s+([^\s]+)`)
+
 func FileExists(filename string) bool {
-	info, err := os.Stat(filename)
-	if os.IsNotExist(err) {
-		return false
-	}
-	return !info.IsDir()
+	return fsys.IsFile(filename)
 }
 
 func DirExists(dirName string) bool {
-	info, err := os.Stat(dirName)
-	if os.IsNotExist(err) {
-		return false
-	}
-	return info.IsDir()
+	return fsys.IsDir(dirName)
 }
 
 func CreateDirIfNotExists(dirName string) error {
 	if DirExists(dirName) {
 		return nil
 	}
-	return os.MkdirAll(dirName, 0755)
+	return fsys.MkdirAll(dirName, 0755)
 }
 
 func CopyFromTemplates(src, dest string) error {
@@ -40,7 +40,7 @@ func CopyFromTemplates(src, dest string) error {
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(dest, content, 0644)
+	err = fsys.WriteFile(dest, content, 0644)
 	if err != nil {
 		return err
 	}
@@ -84,9 +84,31 @@ func ProcessTemplate(
 		return err
 	}
 
-	err = os.WriteFile(dest, b.Bytes(), 0644)
+	err = fsys.WriteFile(dest, b.Bytes(), 0644)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// Sha256 returns the hex encoded sha256 checksum of the content
+func Sha256(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// ProjectPackage returns the module path declared in the go.mod file of the project
+func ProjectPackage(projPath string) (string, error) {
+	content, err := fsys.ReadFile(projPath + "/go.mod")
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("the go.mod file is not found, run the command in the root of the project: %w", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	matches := goModuleRegexp.FindSubmatch(content)
+	if len(matches) < 2 {
+		return "", errors.New("cannot find a module name in the go.mod file")
+	}
+	return string(matches[1]), nil
+}
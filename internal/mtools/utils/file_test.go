@@ -0,0 +1,46 @@
+package utils_test
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/go-modulus/mtools/internal/mtools/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectPackage(t *testing.T) {
+	t.Run(
+		"read the module path from the go.mod file", func(t *testing.T) {
+			projDir := t.TempDir()
+			content := `// The mentions of the module word in comments are skipped
+module github.com/test/testproj
+
+go 1.23.1
+
+require (
+	github.com/go-modulus/modulus v0.0.4
+)
+`
+			require.NoError(t, os.WriteFile(projDir+"/go.mod", []byte(content), 0644))
+
+			pckg, err := utils.ProjectPackage(projDir)
+
+			t.Log("When read the project package")
+			t.Log("	The error should be nil")
+			require.NoError(t, err)
+			t.Log("	The package should be the module path of the go.mod file")
+			require.Equal(t, "github.com/test/testproj", pckg)
+		},
+	)
+
+	t.Run(
+		"read the project package without the go.mod file", func(t *testing.T) {
+			_, err := utils.ProjectPackage(t.TempDir())
+
+			t.Log("When read the project package of the folder without the go.mod file")
+			t.Log("	The error should be fs.ErrNotExist")
+			require.ErrorIs(t, err, fs.ErrNotExist)
+		},
+	)
+}